@@ -41,10 +41,33 @@ CREATE TABLE IF NOT EXISTS vacancies (
                                          weight_soft INT NOT NULL DEFAULT 33,
                                          weight_hard INT NOT NULL DEFAULT 33,
                                          weight_case INT NOT NULL DEFAULT 34,
-                                         text_jsonb JSONB
+                                         text_jsonb JSONB,
+                                         -- Порог оценки резюме для автоматического приглашения, NULL - только вручную
+                                         invite_threshold DOUBLE PRECISION CHECK (invite_threshold >= 0 AND invite_threshold <= 100),
+                                         -- Кандидат сам выбирает время собеседования из interview_slots
+                                         requires_slot BOOLEAN NOT NULL DEFAULT FALSE,
+                                         -- Ограничения собеседования, NULL - значение по умолчанию из настроек сервиса
+                                         interview_max_minutes INT CHECK (interview_max_minutes > 0),
+                                         interview_max_questions INT CHECK (interview_max_questions > 0),
+                                         interview_idle_minutes INT CHECK (interview_idle_minutes > 0)
 );
 CREATE INDEX IF NOT EXISTS idx_vacancies_storage_key ON vacancies(storage_key);
 
+-- ПРОФИЛИ РАЗБОРА ШАБЛОНОВ ВАКАНСИЙ
+-- aliases: {"title": ["Название", "Должность"], "salary_max": ["Оклад макс. (руб/мес)"], ...}
+CREATE TABLE IF NOT EXISTS vacancy_parser_profiles (
+                                                       id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                       name TEXT NOT NULL UNIQUE,
+                                                       description TEXT,
+                                                       priority INT NOT NULL DEFAULT 0,
+                                                       is_active BOOLEAN NOT NULL DEFAULT TRUE,
+                                                       layout TEXT NOT NULL DEFAULT 'auto' CHECK (layout IN ('auto','lines','table')),
+                                                       fuzzy_threshold REAL NOT NULL DEFAULT 0.85,
+                                                       aliases JSONB NOT NULL,
+                                                       created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                       updated_at TIMESTAMPTZ
+);
+
 -- ТАБЛИЦА РЕЗЮМЕ
 CREATE TABLE IF NOT EXISTS resumes (
                                        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -56,10 +79,145 @@ CREATE TABLE IF NOT EXISTS resumes (
                                        mail TEXT,
                                        created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
                                        result_jsonb JSONB,
-                                       resume_analysis_jsonb JSONB
+                                       resume_analysis_jsonb JSONB,
+                                       profile_jsonb JSONB,
+                                       content_hash VARCHAR(64) NOT NULL DEFAULT '',
+                                       email_fingerprint VARCHAR(64) NOT NULL DEFAULT '',
+                                       phone_fingerprint VARCHAR(64) NOT NULL DEFAULT '',
+                                       duplicate_of UUID REFERENCES resumes(id) ON DELETE SET NULL,
+                                       -- Итоговый балл: из результата собеседования, а до него - из анализа резюме
+                                       score NUMERIC GENERATED ALWAYS AS (
+                                           CASE
+                                               WHEN jsonb_typeof(result_jsonb #> '{overall_assessment,final,score}') = 'number'
+                                                   THEN (result_jsonb #>> '{overall_assessment,final,score}')::numeric
+                                               WHEN jsonb_typeof(resume_analysis_jsonb #> '{overall_assessment,final_score}') = 'number'
+                                                   THEN (resume_analysis_jsonb #>> '{overall_assessment,final_score}')::numeric
+                                               END
+                                           ) STORED
 
 );
 CREATE INDEX IF NOT EXISTS idx_resumes_storage_key ON resumes(storage_key);
+CREATE INDEX IF NOT EXISTS idx_resumes_profile ON resumes USING GIN (profile_jsonb jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_resumes_content_hash ON resumes(content_hash) WHERE content_hash <> '';
+-- Один и тот же файл нельзя загрузить на вакансию дважды
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resumes_vacancy_content_hash ON resumes(vacancy_id, content_hash) WHERE content_hash <> '';
+CREATE INDEX IF NOT EXISTS idx_resumes_email_fingerprint ON resumes(email_fingerprint) WHERE email_fingerprint <> '';
+CREATE INDEX IF NOT EXISTS idx_resumes_phone_fingerprint ON resumes(phone_fingerprint) WHERE phone_fingerprint <> '';
+-- Постраничный список резюме вакансии: сортировка по дате или баллу, фильтр по статусу
+CREATE INDEX IF NOT EXISTS idx_resumes_vacancy_created ON resumes(vacancy_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_resumes_vacancy_score ON resumes(vacancy_id, (COALESCE(score, -1)), id);
+CREATE INDEX IF NOT EXISTS idx_resumes_vacancy_status ON resumes(vacancy_id, status);
+
+-- ОЧЕРЕДЬ ОБРАБОТКИ РЕЗЮМЕ
+CREATE TABLE IF NOT EXISTS resume_processing_jobs (
+                                                      id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                      resume_id UUID NOT NULL UNIQUE REFERENCES resumes(id) ON DELETE CASCADE,
+                                                      status TEXT NOT NULL DEFAULT 'pending',
+                                                      attempts INT NOT NULL DEFAULT 0,
+                                                      last_error TEXT,
+                                                      next_run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                      locked_at TIMESTAMPTZ,
+                                                      created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                      updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_resume_processing_jobs_due ON resume_processing_jobs(status, next_run_at);
+
+-- OUTBOX ДЛЯ СООБЩЕНИЙ RABBITMQ
+CREATE TABLE IF NOT EXISTS outbox_messages (
+                                               id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                               aggregate_id UUID NOT NULL,
+                                               payload JSONB NOT NULL,
+                                               attempts INT NOT NULL DEFAULT 0,
+                                               last_error TEXT,
+                                               -- Следующая попытка после ошибки отправки (экспоненциальная задержка)
+                                               next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               locked_at TIMESTAMPTZ,
+                                               created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               sent_at TIMESTAMPTZ,
+                                               -- Попытки исчерпаны, сообщение больше не отправляется
+                                               dead_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_due ON outbox_messages(next_attempt_at) WHERE sent_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate_id ON outbox_messages(aggregate_id);
+
+-- ПАКЕТНЫЙ ИМПОРТ РЕЗЮМЕ
+CREATE TABLE IF NOT EXISTS resume_import_jobs (
+                                                  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                  vacancy_id UUID NOT NULL REFERENCES vacancies(id) ON DELETE CASCADE,
+                                                  status TEXT NOT NULL DEFAULT 'pending',
+                                                  total_files INT NOT NULL DEFAULT 0,
+                                                  succeeded INT NOT NULL DEFAULT 0,
+                                                  failed INT NOT NULL DEFAULT 0,
+                                                  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                  finished_at TIMESTAMPTZ,
+                                                  -- Обновляется после каждого файла; задание без обновлений брошено упавшей репликой
+                                                  heartbeat_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_resume_import_jobs_vacancy_id ON resume_import_jobs(vacancy_id);
+
+CREATE TABLE IF NOT EXISTS resume_import_items (
+                                                   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                   job_id UUID NOT NULL REFERENCES resume_import_jobs(id) ON DELETE CASCADE,
+                                                   position INT NOT NULL,
+                                                   filename TEXT NOT NULL,
+                                                   size_bytes BIGINT NOT NULL DEFAULT 0,
+                                                   status TEXT NOT NULL DEFAULT 'pending',
+                                                   resume_id UUID REFERENCES resumes(id) ON DELETE SET NULL,
+                                                   error TEXT,
+                                                   updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_resume_import_items_job_id ON resume_import_items(job_id);
+
+-- ИСТОРИЯ СТАТУСОВ РЕЗЮМЕ
+CREATE TABLE IF NOT EXISTS resume_status_history (
+                                                     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                     resume_id UUID NOT NULL REFERENCES resumes(id) ON DELETE CASCADE,
+                                                     from_status TEXT NOT NULL DEFAULT '',
+                                                     to_status TEXT NOT NULL,
+                                                     actor TEXT NOT NULL,
+                                                     reason TEXT,
+                                                     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_resume_status_history_resume ON resume_status_history(resume_id, created_at);
+
+-- ПЕРЕСЧЕТ ОЦЕНОК ПОСЛЕ ИЗМЕНЕНИЯ ВЕСОВ ВАКАНСИИ
+CREATE TABLE IF NOT EXISTS vacancy_rescore_jobs (
+                                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                    vacancy_id UUID NOT NULL REFERENCES vacancies(id) ON DELETE CASCADE,
+                                                    status TEXT NOT NULL DEFAULT 'pending',
+                                                    old_weight_soft INT NOT NULL,
+                                                    old_weight_hard INT NOT NULL,
+                                                    old_weight_case INT NOT NULL,
+                                                    weight_soft INT NOT NULL,
+                                                    weight_hard INT NOT NULL,
+                                                    weight_case INT NOT NULL,
+                                                    total INT NOT NULL DEFAULT 0,
+                                                    processed INT NOT NULL DEFAULT 0,
+                                                    recomputed INT NOT NULL DEFAULT 0,
+                                                    republished INT NOT NULL DEFAULT 0,
+                                                    skipped INT NOT NULL DEFAULT 0,
+                                                    failed INT NOT NULL DEFAULT 0,
+                                                    error TEXT,
+                                                    ranking_before JSONB,
+                                                    ranking_after JSONB,
+                                                    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                    started_at TIMESTAMPTZ,
+                                                    finished_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_vacancy_rescore_jobs_vacancy ON vacancy_rescore_jobs(vacancy_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_vacancy_rescore_jobs_pending ON vacancy_rescore_jobs(created_at) WHERE status = 'pending';
+
+-- ОКНА ДЛЯ СОБЕСЕДОВАНИЙ
+CREATE TABLE IF NOT EXISTS interview_slots (
+                                               id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                               vacancy_id UUID NOT NULL REFERENCES vacancies(id) ON DELETE CASCADE,
+                                               starts_at TIMESTAMPTZ NOT NULL,
+                                               ends_at TIMESTAMPTZ NOT NULL,
+                                               capacity INT NOT NULL DEFAULT 1 CHECK (capacity > 0),
+                                               created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS idx_interview_slots_vacancy ON interview_slots(vacancy_id, starts_at);
 
 -- ТАБЛИЦА ИНТЕРВЬЮ
 CREATE TABLE IF NOT EXISTS interviews (
@@ -68,11 +226,58 @@ CREATE TABLE IF NOT EXISTS interviews (
                                           vacancy_id UUID NOT NULL REFERENCES vacancies(id) ON DELETE CASCADE,
                                           status TEXT NOT NULL DEFAULT 'pending',
                                           text_jsonb JSONB,
-                                          url_token TEXT UNIQUE,
+                                          token_hash TEXT UNIQUE, -- SHA-256 токена ссылки кандидата, сам токен не хранится
                                           score_jsonb JSONB,
                                           created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
                                           date_start TIMESTAMPTZ,
                                           updated_at TIMESTAMPTZ,
-                                          started_at TIMESTAMPTZ
+                                          started_at TIMESTAMPTZ,
+                                          -- Выбранное кандидатом окно: date_start и updated_at тогда - его начало и конец
+                                          slot_id UUID REFERENCES interview_slots(id) ON DELETE SET NULL,
+                                          reschedule_count INT NOT NULL DEFAULT 0,
+                                          -- Аренда хода чата: сообщения одного собеседования обрабатываются по очереди на любой реплике
+                                          chat_locked_until TIMESTAMPTZ,
+                                          -- Токен захватившего ход, освободить аренду может только он
+                                          chat_lock_owner TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_interviews_vacancy_id ON interviews(vacancy_id);
+CREATE INDEX IF NOT EXISTS idx_interviews_slot_id ON interviews(slot_id);
+
+-- ПЕРЕПИСКА СОБЕСЕДОВАНИЙ
+CREATE TABLE IF NOT EXISTS interview_messages (
+                                                  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                  interview_id UUID NOT NULL REFERENCES interviews(id) ON DELETE CASCADE,
+                                                  seq INT NOT NULL,
+                                                  type TEXT NOT NULL,
+                                                  content TEXT NOT NULL,
+                                                  sender TEXT NOT NULL,
+                                                  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                  UNIQUE (interview_id, seq)
+);
+
+-- ОЧЕРЕДЬ ПИСЕМ КАНДИДАТАМ
+-- status: queued, sending, sent, failed (попытки закончились), bounced (адрес отклонен сервером)
+CREATE TABLE IF NOT EXISTS email_outbox (
+                                            id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                            kind TEXT NOT NULL,
+                                            interview_id UUID REFERENCES interviews(id) ON DELETE CASCADE,
+                                            vacancy_id UUID REFERENCES vacancies(id) ON DELETE CASCADE,
+                                            resume_id UUID REFERENCES resumes(id) ON DELETE SET NULL,
+                                            recipient TEXT NOT NULL,
+                                            subject TEXT NOT NULL,
+                                            html_body TEXT NOT NULL,
+                                            -- Приложение .ics и его METHOD (REQUEST, CANCEL)
+                                            calendar_ics TEXT,
+                                            calendar_method TEXT,
+                                            status TEXT NOT NULL DEFAULT 'queued',
+                                            attempts INT NOT NULL DEFAULT 0,
+                                            last_error TEXT,
+                                            next_run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                            locked_at TIMESTAMPTZ,
+                                            created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                            updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                            sent_at TIMESTAMPTZ
 );
-CREATE INDEX IF NOT EXISTS idx_interviews_vacancy_id ON interviews(vacancy_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(status, next_run_at);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_interview_id ON email_outbox(interview_id);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_vacancy_id ON email_outbox(vacancy_id);
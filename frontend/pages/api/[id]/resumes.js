@@ -1,6 +1,7 @@
 // pages/api/vacancies/[id]/resumes.js
 export default async function handler(req, res) {
-    const { id } = req.query;
+    const { id, ...query } = req.query;
+    const search = new URLSearchParams(query).toString();
     const token = req.headers.authorization;
 
     if (!token) {
@@ -8,7 +9,7 @@ export default async function handler(req, res) {
     }
 
     try {
-        const response = await fetch(`http://localhost:8081/api/vacancies/${id}/resumes`, {
+        const response = await fetch(`http://localhost:8081/api/vacancies/${id}/resumes${search ? `?${search}` : ''}`, {
             headers: {
                 'Authorization': token,
             },
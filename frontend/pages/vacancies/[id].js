@@ -33,6 +33,7 @@ function ResumeCard({ resume, index, onDelete }) {
                 }
 
                 if (analysisData.candidate_name) return analysisData.candidate_name;
+                if (resume.full_name) return resume.full_name;
                 if (analysisData.personal_info?.name) return analysisData.personal_info.name;
                 if (analysisData.name) return analysisData.name;
             }
@@ -484,16 +485,25 @@ export default function VacancyDetail() {
         const token = getToken();
 
         try {
-            const response = await fetch(`/api/vacancies/${id}/resumes`, {
-                headers: { 'Authorization': `Bearer ${token}` }
-            });
+            // Список отдается страницами: идем по next_cursor, пока страницы не закончатся
+            const loaded = [];
+            let cursor = '';
+            do {
+                const query = `include=analysis&sort=score&limit=200${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`;
+                const response = await fetch(`/api/vacancies/${id}/resumes?${query}`, {
+                    headers: { 'Authorization': `Bearer ${token}` }
+                });
 
-            if (response.ok) {
+                if (!response.ok) {
+                    console.warn('Failed to fetch resumes');
+                    return;
+                }
                 const data = await response.json();
-                setResumes(data.resumes || []);
-            } else {
-                console.warn('Failed to fetch resumes');
-            }
+                loaded.push(...(data.resumes || []));
+                cursor = data.next_cursor || '';
+            } while (cursor);
+
+            setResumes(loaded);
         } catch (error) {
             console.error('Error fetching resumes:', error);
         } finally {
@@ -0,0 +1,273 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+	consumerRetry     = time.Second
+)
+
+// Topology объявляет exchange, очереди и привязки. Вызывается после каждого подключения.
+type Topology func(ch *amqp.Channel) error
+
+// ConsumeFunc начинает чтение очереди на переданном канале
+type ConsumeFunc func(ch *amqp.Channel) (<-chan amqp.Delivery, error)
+
+type consumer struct {
+	name    string
+	consume ConsumeFunc
+	handle  func(amqp.Delivery)
+}
+
+// Health - состояние подключения к RabbitMQ
+type Health struct {
+	Connected      bool       `json:"connected"`
+	ConnectedSince *time.Time `json:"connected_since,omitempty"`
+	Reconnects     int        `json:"reconnects"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// ConnectionManager держит одно соединение с RabbitMQ для publisher и AI RPC клиента.
+// Следит за NotifyClose, переподключается с экспоненциальной задержкой, заново объявляет
+// топологию и перезапускает зарегистрированных потребителей.
+type ConnectionManager struct {
+	url string
+
+	mu         sync.RWMutex
+	conn       *amqp.Connection
+	ready      chan struct{} // закрыт, пока соединение установлено
+	topologies []Topology
+	consumers  []*consumer
+	health     Health
+	connected  bool // хотя бы одно подключение уже было
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+func NewConnectionManager(url string) *ConnectionManager {
+	return &ConnectionManager{
+		url:   url,
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start подключается в фоне и поддерживает соединение до вызова Close
+func (m *ConnectionManager) Start() {
+	go m.run()
+}
+
+func (m *ConnectionManager) run() {
+	delay := reconnectMinDelay
+	for {
+		conn, err := m.connect()
+		if err != nil {
+			m.setError(err)
+			log.Printf("⚠️ RabbitMQ connection failed, retry in %s: %v", delay, err)
+			select {
+			case <-m.done:
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > reconnectMaxDelay {
+				delay = reconnectMaxDelay
+			}
+			continue
+		}
+		delay = reconnectMinDelay
+
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		select {
+		case <-m.done:
+			conn.Close()
+			return
+		case amqpErr := <-closed:
+			m.setDisconnected(amqpErr)
+			log.Printf("⚠️ RabbitMQ connection lost: %v", amqpErr)
+		}
+	}
+}
+
+// connect открывает соединение, объявляет топологию и запускает потребителей
+func (m *ConnectionManager) connect() (*amqp.Connection, error) {
+	conn, err := amqp.Dial(m.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, topology := range m.topologies {
+		if err := declare(conn, topology); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	for _, c := range m.consumers {
+		go m.runConsumer(conn, c)
+	}
+
+	now := time.Now()
+	if m.connected {
+		m.health.Reconnects++
+	}
+	m.connected = true
+	m.health.Connected = true
+	m.health.ConnectedSince = &now
+	m.health.LastError = ""
+	m.conn = conn
+	close(m.ready)
+
+	log.Println("✅ RabbitMQ connected")
+	return conn, nil
+}
+
+func declare(conn *amqp.Connection, topology Topology) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+	return topology(ch)
+}
+
+func (m *ConnectionManager) setError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.LastError = err.Error()
+}
+
+func (m *ConnectionManager) setDisconnected(amqpErr *amqp.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conn = nil
+	m.ready = make(chan struct{})
+	m.health.Connected = false
+	m.health.ConnectedSince = nil
+	if amqpErr != nil {
+		m.health.LastError = amqpErr.Error()
+	} else {
+		m.health.LastError = "connection closed"
+	}
+}
+
+// runConsumer читает очередь, пока живо соединение. Если закрылся только канал,
+// потребитель перезапускается на новом канале.
+func (m *ConnectionManager) runConsumer(conn *amqp.Connection, c *consumer) {
+	for {
+		ch, err := conn.Channel()
+		if err != nil {
+			return
+		}
+
+		deliveries, err := c.consume(ch)
+		if err != nil {
+			log.Printf("❌ Failed to start consumer %s: %v", c.name, err)
+		} else {
+			log.Printf("👂 Consumer %s started", c.name)
+			for delivery := range deliveries {
+				c.handle(delivery)
+			}
+		}
+		ch.Close()
+
+		if conn.IsClosed() {
+			return
+		}
+		select {
+		case <-m.done:
+			return
+		case <-time.After(consumerRetry):
+			log.Printf("🔄 Restarting consumer %s", c.name)
+		}
+	}
+}
+
+// DeclareTopology регистрирует топологию. Если соединение уже есть, она объявляется сразу.
+func (m *ConnectionManager) DeclareTopology(topology Topology) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.topologies = append(m.topologies, topology)
+	if m.conn != nil {
+		return declare(m.conn, topology)
+	}
+	return nil
+}
+
+// Consume регистрирует потребителя, который будет запускаться после каждого подключения
+func (m *ConnectionManager) Consume(name string, consume ConsumeFunc, handle func(amqp.Delivery)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := &consumer{name: name, consume: consume, handle: handle}
+	m.consumers = append(m.consumers, c)
+	if m.conn != nil {
+		go m.runConsumer(m.conn, c)
+	}
+}
+
+// Channel открывает новый канал, при необходимости дожидаясь подключения
+func (m *ConnectionManager) Channel(ctx context.Context) (*amqp.Channel, error) {
+	for {
+		m.mu.RLock()
+		conn, ready := m.conn, m.ready
+		m.mu.RUnlock()
+
+		if conn != nil {
+			ch, err := conn.Channel()
+			if err == nil {
+				return ch, nil
+			}
+			if !conn.IsClosed() {
+				return nil, fmt.Errorf("failed to open channel: %w", err)
+			}
+			// Соединение только что упало, но run еще не заметил этого
+			ready = nil
+		}
+
+		var wait <-chan time.Time
+		if ready == nil {
+			wait = time.After(100 * time.Millisecond)
+		}
+
+		select {
+		case <-ready:
+		case <-wait:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("RabbitMQ is not available: %w", ctx.Err())
+		case <-m.done:
+			return nil, fmt.Errorf("RabbitMQ connection manager is closed")
+		}
+	}
+}
+
+func (m *ConnectionManager) Health() Health {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.health
+}
+
+func (m *ConnectionManager) Close() {
+	m.closeOnce.Do(func() {
+		close(m.done)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.conn != nil {
+			m.conn.Close()
+		}
+	})
+}
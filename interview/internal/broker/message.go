@@ -1,6 +1,13 @@
 package broker
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
 	"gorm.io/datatypes"
 )
 
@@ -14,3 +21,79 @@ type ResumeMessage struct {
 	WeightHard  int            `json:"weight_hard"` // Вес hard skills (0-100)
 	WeightCase  int            `json:"weight_case"` // Вес кейсов/опыта (0-100)
 }
+
+// Результат анализа резюме от CV-review сервиса (очередь результатов)
+const (
+	ResumeResultSchemaVersion = 1
+
+	ResumeResultAnalyzed = "analyzed"
+	ResumeResultFailed   = "failed"
+)
+
+// ResumeResultMessage - результат анализа резюме, который публикует ML сервис
+type ResumeResultMessage struct {
+	SchemaVersion int                    `json:"schema_version"`
+	ResumeID      string                 `json:"resume_id"`
+	VacancyID     string                 `json:"vacancy_id,omitempty"`
+	Status        string                 `json:"status"`             // analyzed или failed
+	Analysis      map[string]interface{} `json:"analysis,omitempty"` // обязателен для analyzed
+	Email         string                 `json:"email,omitempty"`
+	Error         string                 `json:"error,omitempty"` // обязателен для failed
+	AnalyzedAt    time.Time              `json:"analyzed_at"`
+}
+
+var (
+	uuidRe  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// ParseResumeResult разбирает и проверяет сообщение по схеме. Неизвестные поля считаются ошибкой,
+// чтобы расхождение версий ML сервиса и Go сервиса было видно сразу.
+func ParseResumeResult(body []byte) (*ResumeResultMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	var msg ResumeResultMessage
+	if err := decoder.Decode(&msg); err != nil {
+		return nil, fmt.Errorf("invalid result message: %w", err)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (m *ResumeResultMessage) Validate() error {
+	if m.SchemaVersion != ResumeResultSchemaVersion {
+		return fmt.Errorf("unsupported schema_version %d, expected %d", m.SchemaVersion, ResumeResultSchemaVersion)
+	}
+	if !uuidRe.MatchString(m.ResumeID) {
+		return fmt.Errorf("resume_id must be a UUID")
+	}
+	if m.VacancyID != "" && !uuidRe.MatchString(m.VacancyID) {
+		return fmt.Errorf("vacancy_id must be a UUID")
+	}
+	if m.AnalyzedAt.IsZero() {
+		return fmt.Errorf("analyzed_at is required")
+	}
+	// Email извлекает ИИ, и ошибка в нем не должна терять весь анализ: такой email отбрасывается
+	if m.Email != "" && (len(m.Email) > 254 || !emailRe.MatchString(m.Email)) {
+		log.Printf("⚠️ Resume %s: ignoring invalid email %q in analysis result", m.ResumeID, m.Email)
+		m.Email = ""
+	}
+
+	switch m.Status {
+	case ResumeResultAnalyzed:
+		if len(m.Analysis) == 0 {
+			return fmt.Errorf("analysis is required for status %s", m.Status)
+		}
+	case ResumeResultFailed:
+		if m.Error == "" {
+			return fmt.Errorf("error is required for status %s", m.Status)
+		}
+	default:
+		return fmt.Errorf("unknown status %q", m.Status)
+	}
+	return nil
+}
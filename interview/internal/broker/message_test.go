@@ -0,0 +1,72 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResumeResult(t *testing.T) {
+	msg, err := ParseResumeResult([]byte(`{
+		"schema_version": 1,
+		"resume_id": "5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f",
+		"vacancy_id": "0e9d8c7b-6a5f-4e3d-2c1b-a09f8e7d6c5b",
+		"status": "analyzed",
+		"analysis": {"score": 87, "email": ["ivan@mail.ru"]},
+		"email": "ivan@mail.ru",
+		"analyzed_at": "2025-09-01T10:00:00Z"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, ResumeResultAnalyzed, msg.Status)
+	assert.Equal(t, float64(87), msg.Analysis["score"])
+	assert.Equal(t, "ivan@mail.ru", msg.Email)
+
+	msg, err = ParseResumeResult([]byte(`{
+		"schema_version": 1,
+		"resume_id": "5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f",
+		"status": "failed",
+		"error": "cv review service timeout",
+		"analyzed_at": "2025-09-01T10:00:00Z"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, ResumeResultFailed, msg.Status)
+
+	// Некорректный email отбрасывается, анализ сохраняется
+	msg, err = ParseResumeResult([]byte(`{
+		"schema_version": 1,
+		"resume_id": "5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f",
+		"status": "analyzed",
+		"analysis": {"score": 87},
+		"email": "user_has_no_mail",
+		"analyzed_at": "2025-09-01T10:00:00Z"
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, msg.Email)
+	assert.Equal(t, float64(87), msg.Analysis["score"])
+}
+
+func TestParseResumeResult_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"не JSON", `not json`, "invalid result message"},
+		{"неизвестное поле", `{"schema_version":1,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"analyzed","analysis":{"a":1},"analyzed_at":"2025-09-01T10:00:00Z","score":5}`, "unknown field"},
+		{"другая версия схемы", `{"schema_version":2,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"analyzed","analysis":{"a":1},"analyzed_at":"2025-09-01T10:00:00Z"}`, "unsupported schema_version 2"},
+		{"resume_id не UUID", `{"schema_version":1,"resume_id":"N/A","status":"analyzed","analysis":{"a":1},"analyzed_at":"2025-09-01T10:00:00Z"}`, "resume_id must be a UUID"},
+		{"нет анализа", `{"schema_version":1,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"analyzed","analyzed_at":"2025-09-01T10:00:00Z"}`, "analysis is required"},
+		{"ошибка без текста", `{"schema_version":1,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"failed","analyzed_at":"2025-09-01T10:00:00Z"}`, "error is required"},
+		{"неизвестный статус", `{"schema_version":1,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"done","analyzed_at":"2025-09-01T10:00:00Z"}`, `unknown status "done"`},
+		{"нет времени анализа", `{"schema_version":1,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"analyzed","analysis":{"a":1}}`, "analyzed_at is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseResumeResult([]byte(tt.body))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -2,95 +2,122 @@ package broker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
+	"sync"
 	"time"
 
 	"github.com/streadway/amqp"
 )
 
 type Publisher interface {
-	PublishResumeMessage(ctx context.Context, msg ResumeMessage) error
+	// Publish отправляет сообщение и ждет подтверждения брокера (publisher confirms)
+	Publish(ctx context.Context, messageID string, body []byte) error
 	Close()
 }
 
+// RabbitMQPublisher публикует сообщения в режиме publisher confirms.
+// Канал открывается через ConnectionManager и пересоздается после ошибки.
 type RabbitMQPublisher struct {
-	conn     *amqp.Connection
-	channel  *amqp.Channel
+	conn     *ConnectionManager
 	exchange string
 	queue    string
+
+	mu       sync.Mutex
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
 }
 
-func NewRabbitMQPublisher(url, exchange, queue string) (*RabbitMQPublisher, error) {
-	conn, err := amqp.Dial(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+func NewRabbitMQPublisher(conn *ConnectionManager, exchange, queue string) (*RabbitMQPublisher, error) {
+	p := &RabbitMQPublisher{
+		conn:     conn,
+		exchange: exchange,
+		queue:    queue,
 	}
-
-	ch, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+	if err := conn.DeclareTopology(p.declare); err != nil {
+		return nil, err
 	}
+	return p, nil
+}
 
+// declare объявляет exchange и очередь резюме. Повторяется после каждого переподключения.
+func (p *RabbitMQPublisher) declare(ch *amqp.Channel) error {
 	// Объявляем exchange
 	if err := ch.ExchangeDeclare(
-		exchange, // name
-		"direct", // type
-		true,     // durable
-		false,    // auto-deleted
-		false,    // internal
-		false,    // no-wait
-		nil,      // arguments
+		p.exchange, // name
+		"direct",   // type
+		true,       // durable
+		false,      // auto-deleted
+		false,      // internal
+		false,      // no-wait
+		nil,        // arguments
 	); err != nil {
-		ch.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+		return fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
 	// Объявляем очередь
 	if _, err := ch.QueueDeclare(
-		queue, // name
-		true,  // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		nil,   // arguments
+		p.queue, // name
+		true,    // durable
+		false,   // delete when unused
+		false,   // exclusive
+		false,   // no-wait
+		nil,     // arguments
 	); err != nil {
-		ch.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to declare queue: %w", err)
+		return fmt.Errorf("failed to declare queue: %w", err)
 	}
 
 	// Привязываем очередь к exchange
 	if err := ch.QueueBind(
-		queue,    // queue name
-		queue,    // routing key
-		exchange, // exchange
+		p.queue,    // queue name
+		p.queue,    // routing key
+		p.exchange, // exchange
 		false,
 		nil,
 	); err != nil {
+		return fmt.Errorf("failed to bind queue: %w", err)
+	}
+	return nil
+}
+
+func (p *RabbitMQPublisher) openChannel(ctx context.Context) error {
+	if p.channel != nil {
+		return nil
+	}
+
+	ch, err := p.conn.Channel(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Включаем подтверждения публикации
+	if err := ch.Confirm(false); err != nil {
 		ch.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to bind queue: %w", err)
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
 	}
 
-	return &RabbitMQPublisher{
-		conn:     conn,
-		channel:  ch,
-		exchange: exchange,
-		queue:    queue,
-	}, nil
+	p.channel = ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	return nil
 }
 
-func (p *RabbitMQPublisher) PublishResumeMessage(ctx context.Context, msg ResumeMessage) error {
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+// reset закрывает канал, следующая публикация откроет новый
+func (p *RabbitMQPublisher) reset() {
+	if p.channel != nil {
+		p.channel.Close()
+	}
+	p.channel = nil
+	p.confirms = nil
+}
+
+func (p *RabbitMQPublisher) Publish(ctx context.Context, messageID string, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.openChannel(ctx); err != nil {
+		return err
 	}
 
-	return p.channel.Publish(
+	err := p.channel.Publish(
 		p.exchange, // exchange
 		p.queue,    // routing key
 		false,      // mandatory
@@ -100,32 +127,33 @@ func (p *RabbitMQPublisher) PublishResumeMessage(ctx context.Context, msg Resume
 			Body:         body,
 			DeliveryMode: amqp.Persistent, // make message persistent
 			Timestamp:    time.Now(),
-			MessageId:    msg.ID,
+			MessageId:    messageID,
 		},
 	)
-}
-
-func (p *RabbitMQPublisher) Close() {
-	if p.channel != nil {
-		p.channel.Close()
-	}
-	if p.conn != nil {
-		p.conn.Close()
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("failed to publish message: %w", err)
 	}
-}
 
-// NullPublisher для тестов или когда RabbitMQ недоступен
-type NullPublisher struct{}
-
-func NewNullPublisher() *NullPublisher {
-	return &NullPublisher{}
-}
-
-func (p *NullPublisher) PublishResumeMessage(ctx context.Context, msg ResumeMessage) error {
-	log.Printf("NullPublisher: would publish message for resume %s", msg.ID)
-	return nil
+	select {
+	case confirm, ok := <-p.confirms:
+		if !ok {
+			p.reset()
+			return fmt.Errorf("channel closed before publish confirmation")
+		}
+		if !confirm.Ack {
+			return fmt.Errorf("message %s was rejected by broker", messageID)
+		}
+		return nil
+	case <-ctx.Done():
+		// Подтверждение может прийти позже и перепутаться со следующим сообщением
+		p.reset()
+		return ctx.Err()
+	}
 }
 
-func (p *NullPublisher) Close() {
-	// nothing to close
+func (p *RabbitMQPublisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
 }
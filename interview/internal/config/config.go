@@ -3,16 +3,53 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Processing ProcessingConfig
+	Mail       MailConfig
+	Interview  InterviewConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Mode string
+	// PublicURL - адрес сервиса, как его видит кандидат, для ссылок на собеседование
+	PublicURL string
+}
+
+// ProcessingConfig - очередь обработки резюме
+type ProcessingConfig struct {
+	Workers     int
+	MaxAttempts int
+}
+
+// MailConfig - отправка приглашений кандидатам. Без MAIL_HOST сервис не запускается,
+// если не включен режим разработки MAIL_CAPTURE: письма тогда только пишутся в лог.
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Capture  bool
+	// Ограничение провайдера и число попыток отправки одного письма
+	RatePerMinute int
+	MaxAttempts   int
+}
+
+// InterviewConfig - ограничения собеседований по умолчанию и напоминания кандидатам.
+// Вакансия может задать свои ограничения.
+type InterviewConfig struct {
+	IdleTimeout     time.Duration   // начатое собеседование без сообщений дольше завершается автоматически
+	MaxDuration     time.Duration   // сколько длится собеседование с начала
+	MaxQuestions    int             // сколько вопросов задает AI до итогового результата
+	ReminderOffsets []time.Duration // за сколько до выбранного времени напоминать кандидату
 }
 
 type DatabaseConfig struct {
@@ -29,6 +66,8 @@ func Load() *Config {
 		Server: ServerConfig{
 			Port: getEnv("PORT", "8081"),
 			Mode: getEnv("GIN_MODE", "debug"),
+
+			PublicURL: getEnv("PUBLIC_BASE_URL", "http://localhost:8081"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -38,6 +77,27 @@ func Load() *Config {
 			DBName:   getEnv("DB_NAME", "ai_hr_db"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
+		Processing: ProcessingConfig{
+			Workers:     getEnvInt("RESUME_WORKERS", 4),
+			MaxAttempts: getEnvInt("RESUME_MAX_ATTEMPTS", 5),
+		},
+		Mail: MailConfig{
+			Host:     getEnv("MAIL_HOST", ""),
+			Port:     getEnvInt("MAIL_PORT", 465),
+			Username: getEnv("MAIL_NAME", ""),
+			Password: getEnv("MAIL_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", ""),
+			Capture:  getEnvBool("MAIL_CAPTURE", false),
+
+			RatePerMinute: getEnvInt("MAIL_RATE_PER_MINUTE", 30),
+			MaxAttempts:   getEnvInt("MAIL_MAX_ATTEMPTS", 6),
+		},
+		Interview: InterviewConfig{
+			IdleTimeout:     time.Duration(getEnvInt("INTERVIEW_IDLE_TIMEOUT_MINUTES", 30)) * time.Minute,
+			MaxDuration:     time.Duration(getEnvInt("INTERVIEW_MAX_MINUTES", 30)) * time.Minute,
+			MaxQuestions:    getEnvInt("INTERVIEW_MAX_QUESTIONS", 15),
+			ReminderOffsets: getEnvDurations("INTERVIEW_REMINDER_OFFSETS", []time.Duration{24 * time.Hour, time.Hour}),
+		},
 	}
 }
 
@@ -48,6 +108,51 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid value %q for %s, using %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using %t", value, key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvDurations читает список через запятую в формате time.ParseDuration, например "24h,1h"
+func getEnvDurations(key string, defaultValue []time.Duration) []time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var durations []time.Duration
+	for _, item := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(item))
+		if err != nil || d <= 0 {
+			log.Printf("Invalid value %q for %s, using %v", value, key, defaultValue)
+			return defaultValue
+		}
+		durations = append(durations, d)
+	}
+	return durations
+}
+
 func getEnvRequired(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -13,11 +13,110 @@ func RunMigrations(db *gorm.DB) error {
 		&models.Vacancy{},
 		&models.Resume{},
 		&models.Interview{},
+		&models.VacancyParserProfile{},
+		&models.ResumeImportJob{},
+		&models.ResumeImportItem{},
+		&models.ResumeProcessingJob{},
+		&models.OutboxMessage{},
+		&models.ResumeStatusHistory{},
+		&models.VacancyRescoreJob{},
+		&models.ChatMessage{},
+		&models.EmailMessage{},
+		&models.InterviewSlot{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("could not run GORM migrations: %w", err)
 	}
 
+	// Ссылки кандидатов раньше хранились целиком в url_token - переносим их в хэши
+	if db.Migrator().HasColumn("interviews", "url_token") {
+		err = db.Exec(`UPDATE interviews
+			SET token_hash = encode(sha256(convert_to(substring(url_token from '/interview/([^/]+)$'), 'UTF8')), 'hex')
+			WHERE token_hash IS NULL AND url_token IS NOT NULL`).Error
+		if err != nil {
+			return fmt.Errorf("could not migrate interview tokens: %w", err)
+		}
+		if err := db.Migrator().DropColumn("interviews", "url_token"); err != nil {
+			return fmt.Errorf("could not drop url_token: %w", err)
+		}
+	}
+
+	if err := migrateResumeScore(db); err != nil {
+		return err
+	}
+
+	if err := migrateResumeContentHash(db); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// resumeScoreExpr - выражение генерируемой колонки resumes.score, как в database/init.sql
+const resumeScoreExpr = `CASE
+	WHEN jsonb_typeof(result_jsonb #> '{overall_assessment,final,score}') = 'number'
+		THEN (result_jsonb #>> '{overall_assessment,final,score}')::numeric
+	WHEN jsonb_typeof(resume_analysis_jsonb #> '{overall_assessment,final_score}') = 'number'
+		THEN (resume_analysis_jsonb #>> '{overall_assessment,final_score}')::numeric
+	END`
+
+// migrateResumeScore создает генерируемый итоговый балл и индексы постраничного списка резюме.
+// Колонка без выражения или со старым путем к баллу пересоздается.
+func migrateResumeScore(db *gorm.DB) error {
+	var current int64
+	err := db.Raw(`SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'resumes' AND column_name = 'score'
+		AND is_generated = 'ALWAYS' AND generation_expression LIKE '%{overall_assessment,final,score}%'`).
+		Scan(&current).Error
+	if err != nil {
+		return fmt.Errorf("could not inspect resumes.score: %w", err)
+	}
+
+	if current == 0 {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Индекс по баллу удаляется вместе с колонкой и создается заново ниже
+			if err := tx.Exec(`ALTER TABLE resumes DROP COLUMN IF EXISTS score`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE resumes ADD COLUMN score NUMERIC GENERATED ALWAYS AS (` + resumeScoreExpr + `) STORED`).Error
+		})
+		if err != nil {
+			return fmt.Errorf("could not create resumes.score: %w", err)
+		}
+	}
+
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_resumes_vacancy_created ON resumes(vacancy_id, created_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_resumes_vacancy_score ON resumes(vacancy_id, (COALESCE(score, -1)), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_resumes_vacancy_status ON resumes(vacancy_id, status)`,
+	}
+	for _, index := range indexes {
+		if err := db.Exec(index).Error; err != nil {
+			return fmt.Errorf("could not create resume list index: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateResumeContentHash запрещает загружать один и тот же файл на вакансию дважды.
+// Повторы, сохраненные до появления индекса, теряют хэш: остается самое раннее резюме.
+func migrateResumeContentHash(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE resumes r SET content_hash = ''
+			WHERE r.content_hash <> '' AND EXISTS (
+				SELECT 1 FROM resumes e
+				WHERE e.vacancy_id = r.vacancy_id AND e.content_hash = r.content_hash
+				AND (e.created_at, e.id) < (r.created_at, r.id)
+			)`).Error
+		if err != nil {
+			return fmt.Errorf("could not clear duplicate resume hashes: %w", err)
+		}
+		err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_resumes_vacancy_content_hash
+			ON resumes(vacancy_id, content_hash) WHERE content_hash <> ''`).Error
+		if err != nil {
+			return fmt.Errorf("could not create resume content index: %w", err)
+		}
+		return nil
+	})
+}
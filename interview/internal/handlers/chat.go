@@ -1,25 +1,55 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"interview/internal/models"
 	"interview/internal/service"
+	"io"
+	"log"
 	"net/http"
+	"sync"
+	"time"
 )
 
+// sseKeepAlive - интервал комментариев, которые не дают прокси закрыть простаивающий поток
+const sseKeepAlive = 15 * time.Second
+
 type ChatHandler struct {
 	chatSvc      service.ChatService
 	interviewSvc service.InterviewService
+	events       service.ChatEventBus
+
+	closing   chan struct{} // закрывается при остановке сервера, чтобы завершить потоки событий
+	closeOnce sync.Once
 }
 
-func NewChatHandler(chatSvc service.ChatService, interviewSvc service.InterviewService) *ChatHandler {
+func NewChatHandler(chatSvc service.ChatService, interviewSvc service.InterviewService, events service.ChatEventBus) *ChatHandler {
 	return &ChatHandler{
 		chatSvc:      chatSvc,
 		interviewSvc: interviewSvc,
+		events:       events,
+		closing:      make(chan struct{}),
+	}
+}
+
+// CloseStreams завершает открытые потоки событий. Без этого http.Server.Shutdown ждал бы их до таймаута.
+func (h *ChatHandler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// replyWriteMargin - запас к таймауту AI на сохранение ответа и запись его клиенту
+const replyWriteMargin = 15 * time.Second
+
+// setWriteDeadline заменяет WriteTimeout сервера для этого запроса. Нулевое время снимает ограничение.
+func setWriteDeadline(c *gin.Context, deadline time.Time) {
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(deadline); err != nil {
+		log.Printf("⚠️ Failed to change write deadline for %s: %v", c.FullPath(), err)
 	}
 }
 
-// POST /interview/:token/message
+// POST /api/interview/:token/message?async=true
+// С async=true сообщение принимается сразу (202), а ответ AI приходит в GET /api/interview/:token/events
 func (h *ChatHandler) SendMessage(c *gin.Context) {
 	token := c.Param("token")
 
@@ -46,6 +76,10 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 
 	// Создаем или получаем сессию чата
 	session, err := h.getOrCreateSession(interview)
+	if errors.Is(err, service.ErrChatBusy) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize chat session"})
 		return
@@ -62,8 +96,38 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		Sender:  "candidate",
 	}
 
-	// Добавляем сообщение кандидата и получаем ответ AI
+	if c.Query("async") == "true" {
+		err := h.chatSvc.SubmitCandidateMessage(interview.ID, candidateMsg)
+		if errors.Is(err, service.ErrChatBusy) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrChatSessionClosed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Interview completed"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"candidate_message": candidateMsg})
+		return
+	}
+
+	// Добавляем сообщение кандидата и получаем ответ AI. Ожидание AI дольше WriteTimeout сервера.
+	setWriteDeadline(c, time.Now().Add(service.ChatReplyTimeout+replyWriteMargin))
 	aiMsg, err := h.chatSvc.AddCandidateMessage(interview.ID, candidateMsg)
+	if errors.Is(err, service.ErrChatBusy) {
+		// Предыдущее сообщение еще обрабатывается, возможно другой репликой
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrChatSessionClosed) {
+		// Ограничение собеседования истекло, пока кандидат писал ответ
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Interview completed"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -75,7 +139,54 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	})
 }
 
-// GET /interview/:token/messages
+// GET /api/interview/:token/events
+// Server-Sent Events: message, typing, chunk, status, error. Первым приходит текущий status.
+func (h *ChatHandler) StreamEvents(c *gin.Context) {
+	interview, err := h.interviewSvc.GetInterviewByToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Interview not found"})
+		return
+	}
+
+	// Подписываемся до чтения статуса, чтобы не потерять события между ними
+	events, unsubscribe := h.events.Subscribe(interview.ID)
+	defer unsubscribe()
+
+	// Поток открыт, пока кандидат на странице; WriteTimeout сервера оборвал бы его
+	setWriteDeadline(c, time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+
+	c.SSEvent(models.ChatEventStatus, models.ChatEvent{
+		Type:        models.ChatEventStatus,
+		InterviewID: interview.ID,
+		Status:      h.currentStatus(interview),
+	})
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		case <-h.closing:
+			return false
+		}
+	})
+}
+
+// GET /api/interview/:token/messages
 func (h *ChatHandler) GetMessages(c *gin.Context) {
 	token := c.Param("token")
 
@@ -85,17 +196,36 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
-	msgs, err := h.chatSvc.GetMessages(interview.ID)
+	h.respondMessages(c, interview.ID)
+}
+
+// GET /api/interviews/:id/messages
+func (h *ChatHandler) GetTranscript(c *gin.Context) {
+	interview, err := h.interviewSvc.GetInterview(c.Request.Context(), c.Param("id"))
 	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Interview not found"})
+		return
+	}
+
+	h.respondMessages(c, interview.ID)
+}
+
+func (h *ChatHandler) respondMessages(c *gin.Context, interviewID string) {
+	msgs, err := h.chatSvc.GetMessages(interviewID)
+	if errors.Is(err, service.ErrChatSessionNotFound) {
 		// Если сессии нет, возвращаем пустой массив
 		c.JSON(http.StatusOK, gin.H{"messages": []models.ChatMessage{}})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get messages"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"messages": msgs})
 }
 
-// GET /interview/:token/status
+// GET /api/interview/:token/status
 func (h *ChatHandler) GetStatus(c *gin.Context) {
 	token := c.Param("token")
 
@@ -105,23 +235,26 @@ func (h *ChatHandler) GetStatus(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, h.currentStatus(interview))
+}
+
+// currentStatus - состояние чата, а если он еще не начат - состояние собеседования
+func (h *ChatHandler) currentStatus(interview *models.Interview) *models.InterviewStatus {
 	status, err := h.chatSvc.GetStatus(interview.ID)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"interview_id":  interview.ID,
-			"status":        interview.Status,
-			"is_active":     interview.Status == "started",
-			"message_count": 0,
-		})
-		return
+		return &models.InterviewStatus{
+			InterviewID:  interview.ID,
+			Status:       interview.Status,
+			IsActive:     interview.Status == "started",
+			MessageCount: 0,
+		}
 	}
-
-	c.JSON(http.StatusOK, status)
+	return status
 }
 
 func (h *ChatHandler) getOrCreateSession(interview *models.Interview) (*models.ChatSession, error) {
 	session, err := h.chatSvc.GetSession(interview.ID)
-	if err != nil {
+	if errors.Is(err, service.ErrChatSessionNotFound) {
 		// Получаем resumeID
 		var resumeID string
 		if interview.ResumeID != nil {
@@ -131,5 +264,5 @@ func (h *ChatHandler) getOrCreateSession(interview *models.Interview) (*models.C
 		// Создаем сессию с полными данными
 		return h.chatSvc.CreateSession(interview.ID, resumeID, interview.VacancyID)
 	}
-	return session, nil
+	return session, err
 }
@@ -1,23 +1,27 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	_ "time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"interview/internal/models"
 	"interview/internal/service"
 )
 
-type Handler struct {
-	svc service.InterviewService
+type InterviewHandler struct {
+	svc         service.InterviewService
+	invitations service.InvitationService
 }
 
-func NewHandler(svc service.InterviewService) *Handler { return &Handler{svc: svc} }
+func NewInterviewHandler(svc service.InterviewService, invitations service.InvitationService) *InterviewHandler {
+	return &InterviewHandler{svc: svc, invitations: invitations}
+}
 
-/* -------- POST /api/admin/interviews -------- */
+/* -------- POST /api/interviews -------- */
 
-func (h *Handler) Create(c *gin.Context) {
+func (h *InterviewHandler) Create(c *gin.Context) {
 	var req struct {
 		ResumeID  *string `json:"resume_id,omitempty"`
 		VacancyID string  `json:"vacancy_id" binding:"required"`
@@ -28,14 +32,8 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 	intv := &models.Interview{ResumeID: req.ResumeID, VacancyID: req.VacancyID}
 
-	scheme := "http"
-	if c.Request.TLS != nil {
-		scheme = "https"
-	}
-	base := scheme + "://" + c.Request.Host
-
-	if err := h.svc.CreateInterview(c.Request.Context(), intv, base); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	if err := h.svc.CreateInterview(c.Request.Context(), intv); err != nil {
+		h.handleError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
@@ -43,16 +41,127 @@ func (h *Handler) Create(c *gin.Context) {
 		"vacancy_id":    intv.VacancyID,
 		"resume_id":     intv.ResumeID,
 		"status":        intv.Status,
-		"interview_url": *intv.URLToken,
+		"interview_url": intv.URL,
 		"scheduled_at":  intv.ScheduledAt,
 		"expires_at":    intv.ExpiresAt,
 		"created_at":    intv.CreatedAt,
 	})
 }
 
+/* -------- GET /api/interviews?vacancy_id=...&status=... -------- */
+
+func (h *InterviewHandler) List(c *gin.Context) {
+	vacancyID := c.Query("vacancy_id")
+	if vacancyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vacancy_id is required"})
+		return
+	}
+
+	interviews, err := h.svc.GetInterviewsByVacancy(c.Request.Context(), vacancyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	statuses := splitQueryList(c.Query("status"))
+	result := make([]*models.Interview, 0, len(interviews))
+	for _, intv := range interviews {
+		if len(statuses) == 0 || containsString(statuses, intv.Status) {
+			result = append(result, intv)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"interviews": result, "count": len(result)})
+}
+
+/* -------- GET /api/interviews/:id -------- */
+
+func (h *InterviewHandler) GetByID(c *gin.Context) {
+	intv, err := h.svc.GetInterview(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, intv)
+}
+
+/* -------- POST /api/interviews/:id/cancel -------- */
+
+func (h *InterviewHandler) Cancel(c *gin.Context) {
+	intv, err := h.svc.CancelInterview(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, intv)
+}
+
+/* -------- POST /api/interviews/:id/link -------- */
+
+// ReissueLink выпускает новую ссылку кандидата. Новая ссылка есть только в этом ответе.
+func (h *InterviewHandler) ReissueLink(c *gin.Context) {
+	intv, err := h.svc.ReissueLink(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, intv)
+}
+
+/* -------- DELETE /api/interviews/:id/link -------- */
+
+func (h *InterviewHandler) RevokeLink(c *gin.Context) {
+	intv, err := h.svc.RevokeLink(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, intv)
+}
+
+/* -------- GET /api/interviews/invitations?vacancy_id=... -------- */
+
+// ListInvitations - статус доставки последнего приглашения каждого кандидата вакансии
+func (h *InterviewHandler) ListInvitations(c *gin.Context) {
+	vacancyID := c.Query("vacancy_id")
+	if vacancyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vacancy_id is required"})
+		return
+	}
+
+	emails, err := h.invitations.VacancyDeliveries(c.Request.Context(), vacancyID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": emails, "count": len(emails)})
+}
+
+/* -------- GET /api/interviews/:id/emails -------- */
+
+func (h *InterviewHandler) GetEmails(c *gin.Context) {
+	emails, err := h.invitations.Deliveries(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"emails": emails, "count": len(emails)})
+}
+
+/* -------- POST /api/interviews/:id/invitation/resend -------- */
+
+func (h *InterviewHandler) ResendInvitation(c *gin.Context) {
+	email, err := h.invitations.Resend(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, email)
+}
+
 /* -------- GET /api/interview/:token -------- */
 
-func (h *Handler) GetByToken(c *gin.Context) {
+func (h *InterviewHandler) GetByToken(c *gin.Context) {
 	tok := c.Param("token")
 	intv, err := h.svc.GetInterviewByToken(c.Request.Context(), tok)
 	if err != nil {
@@ -64,14 +173,15 @@ func (h *Handler) GetByToken(c *gin.Context) {
 		"scheduled_at": intv.ScheduledAt,
 		"expires_at":   intv.ExpiresAt,
 		"started_at":   intv.StartedAt,
+		"slot_id":      intv.SlotID,
 	})
 }
 
 /* -------- POST /api/interview/:token/start -------- */
 
-func (h *Handler) StartByToken(c *gin.Context) {
-	if h.svc.StartInterview(c.Request.Context(), c.Param("token")) != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot start"})
+func (h *InterviewHandler) StartByToken(c *gin.Context) {
+	if err := h.svc.StartInterview(c.Request.Context(), c.Param("token")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot start", "details": err.Error()})
 		return
 	}
 	c.Status(http.StatusOK)
@@ -79,12 +189,32 @@ func (h *Handler) StartByToken(c *gin.Context) {
 
 /* -------- POST /api/interview/:token/finish -------- */
 
-func (h *Handler) FinishByToken(c *gin.Context) {
-	if h.svc.FinishInterview(c.Request.Context(), c.Param("token")) != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot finish"})
+func (h *InterviewHandler) FinishByToken(c *gin.Context) {
+	if err := h.svc.FinishInterview(c.Request.Context(), c.Param("token")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot finish", "details": err.Error()})
 		return
 	}
 	c.Status(http.StatusOK)
 }
 
-/* -------- Роутер -------- */
+func (h *InterviewHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInterview):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInterviewStateChanged), errors.Is(err, service.ErrInvitationNotAllowed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
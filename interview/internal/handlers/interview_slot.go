@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"interview/internal/models"
+	"interview/internal/service"
+)
+
+type InterviewSlotHandler struct {
+	svc      service.InterviewSlotService
+	calendar service.InterviewCalendarService
+}
+
+func NewInterviewSlotHandler(svc service.InterviewSlotService, calendar service.InterviewCalendarService) *InterviewSlotHandler {
+	return &InterviewSlotHandler{svc: svc, calendar: calendar}
+}
+
+/* -------- POST /api/vacancies/:id/slots -------- */
+
+func (h *InterviewSlotHandler) Create(c *gin.Context) {
+	var req struct {
+		StartsAt time.Time `json:"starts_at" binding:"required"`
+		EndsAt   time.Time `json:"ends_at" binding:"required"`
+		Capacity int       `json:"capacity"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	slot := &models.InterviewSlot{
+		VacancyID: c.Param("id"),
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Capacity:  req.Capacity,
+	}
+	if err := h.svc.CreateSlot(c.Request.Context(), slot); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, slot)
+}
+
+/* -------- GET /api/vacancies/:id/slots -------- */
+
+func (h *InterviewSlotHandler) List(c *gin.Context) {
+	slots, err := h.svc.ListSlots(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"slots": slots, "count": len(slots)})
+}
+
+/* -------- DELETE /api/vacancies/:id/slots/:slot_id -------- */
+
+func (h *InterviewSlotHandler) Delete(c *gin.Context) {
+	if err := h.svc.DeleteSlot(c.Request.Context(), c.Param("id"), c.Param("slot_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "slot deleted successfully"})
+}
+
+/* -------- GET /api/interview/:token/slots -------- */
+
+func (h *InterviewSlotHandler) Available(c *gin.Context) {
+	slots, err := h.svc.AvailableSlots(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// Кандидату не нужно знать, сколько человек записано
+	result := make([]gin.H, 0, len(slots))
+	for _, slot := range slots {
+		result = append(result, gin.H{"id": slot.ID, "starts_at": slot.StartsAt, "ends_at": slot.EndsAt})
+	}
+	c.JSON(http.StatusOK, gin.H{"slots": result, "count": len(result)})
+}
+
+/* -------- PUT /api/interview/:token/slot -------- */
+
+func (h *InterviewSlotHandler) Book(c *gin.Context) {
+	var req struct {
+		SlotID string `json:"slot_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slot_id is required"})
+		return
+	}
+
+	intv, err := h.svc.BookSlot(c.Request.Context(), c.Param("token"), req.SlotID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"slot_id":          intv.SlotID,
+		"scheduled_at":     intv.ScheduledAt,
+		"expires_at":       intv.ExpiresAt,
+		"reschedules_left": service.InterviewMaxReschedules - intv.RescheduleCount,
+	})
+}
+
+/* -------- GET /api/interview/:token/calendar.ics -------- */
+
+// Calendar - то же событие, что приходит во вложении письма о записи
+func (h *InterviewSlotHandler) Calendar(c *gin.Context) {
+	ics, err := h.calendar.CalendarByToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="interview.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+func (h *InterviewSlotHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrInterviewNotScheduled):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSlot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSlotUnavailable),
+		errors.Is(err, service.ErrSlotBooked),
+		errors.Is(err, service.ErrRescheduleNotAllowed),
+		errors.Is(err, service.ErrInterviewStateChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"interview/internal/service"
+)
+
+type ProcessingHandler struct {
+	svc service.ResumeProcessingService
+}
+
+func NewProcessingHandler(svc service.ResumeProcessingService) *ProcessingHandler {
+	return &ProcessingHandler{svc: svc}
+}
+
+// GET /api/admin/processing-jobs?status=failed
+func (h *ProcessingHandler) GetJobs(c *gin.Context) {
+	jobs, err := h.svc.ListJobs(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(jobs),
+		"jobs":  jobs,
+	})
+}
+
+// POST /api/admin/resumes/:id/requeue
+func (h *ProcessingHandler) Requeue(c *gin.Context) {
+	if err := h.svc.Requeue(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "resume re-enqueued for processing"})
+}
+
+// POST /api/admin/processing-jobs/requeue-failed
+func (h *ProcessingHandler) RequeueFailed(c *gin.Context) {
+	count, err := h.svc.RequeueFailed(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to re-enqueue resumes"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"requeued": count})
+}
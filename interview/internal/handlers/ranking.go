@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"interview/internal/service"
+)
+
+type RankingHandler struct {
+	svc service.RankingService
+}
+
+func NewRankingHandler(svc service.RankingService) *RankingHandler {
+	return &RankingHandler{svc: svc}
+}
+
+// GET /api/vacancies/:id/ranking?top=10&min_score=70
+func (h *RankingHandler) GetRanking(c *gin.Context) {
+	ranking, ok := h.rank(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// GET /api/vacancies/:id/shortlist?top=10&min_score=70
+func (h *RankingHandler) GetShortlist(c *gin.Context) {
+	if c.Query("top") == "" && c.Query("min_score") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top or min_score is required"})
+		return
+	}
+
+	ranking, ok := h.rank(c)
+	if !ok {
+		return
+	}
+
+	shortlist := make([]*service.CandidateRanking, 0, ranking.ShortlistCount)
+	for _, candidate := range ranking.Candidates {
+		if candidate.Shortlisted {
+			shortlist = append(shortlist, candidate)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vacancy_id": ranking.VacancyID,
+		"count":      len(shortlist),
+		"candidates": shortlist,
+	})
+}
+
+func (h *RankingHandler) rank(c *gin.Context) (*service.VacancyRanking, bool) {
+	var opts service.ShortlistOptions
+	if top := c.Query("top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "top must be a number"})
+			return nil, false
+		}
+		opts.Top = n
+	}
+	minScore, err := parseQueryFloat(c, "min_score")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	opts.MinScore = minScore
+
+	ranking, err := h.svc.Rank(c.Request.Context(), c.Param("id"), opts)
+	switch {
+	case err == nil:
+		return ranking, true
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "vacancy not found"})
+	case errors.Is(err, service.ErrInvalidShortlist):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rank candidates"})
+	}
+	return nil, false
+}
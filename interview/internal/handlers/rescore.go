@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"interview/internal/service"
+)
+
+type RescoreHandler struct {
+	svc service.RescoreService
+}
+
+func NewRescoreHandler(svc service.RescoreService) *RescoreHandler {
+	return &RescoreHandler{svc: svc}
+}
+
+// GET /api/vacancies/:id/rescore-jobs
+func (h *RescoreHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.svc.ListJobs(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rescore jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "count": len(jobs)})
+}
+
+// GET /api/vacancies/:id/rescore-jobs/:job_id
+func (h *RescoreHandler) GetJob(c *gin.Context) {
+	job, err := h.svc.GetJob(c.Request.Context(), c.Param("id"), c.Param("job_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GET /api/vacancies/:id/rescore-jobs/:job_id/diff?live=true
+func (h *RescoreHandler) GetDiff(c *gin.Context) {
+	live := c.Query("live") == "true"
+
+	diff, err := h.svc.Diff(c.Request.Context(), c.Param("id"), c.Param("job_id"), live)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *RescoreHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRescoreJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "rescore job not found"})
+	case errors.Is(err, service.ErrRescoreJobNotFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rescore job"})
+	}
+}
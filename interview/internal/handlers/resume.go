@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	_ "strconv"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"interview/internal/models"
 	"interview/internal/service"
 )
@@ -44,6 +49,10 @@ func (h *ResumeHandler) Create(c *gin.Context) {
 	}
 
 	err = h.svc.CreateResume(c.Request.Context(), resume, file, fileHeader.Filename)
+	if errors.Is(err, service.ErrDuplicateResume) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "resume": resume})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -101,17 +110,159 @@ func (h *ResumeHandler) GetDownloadLink(c *gin.Context) {
 //}
 
 // GET /api/vacancies/:id/resumes
+// Параметры: status (через запятую), min_score, max_score, created_from, created_to (RFC3339 или YYYY-MM-DD),
+// skills (через запятую, все обязательны), sort (created_at|score), order (asc|desc), limit, cursor, include=analysis
 func (h *ResumeHandler) GetByVacancy(c *gin.Context) {
 	vacancyID := c.Param("id") // ← ИСПРАВЛЕНО: используй "id" вместо "vacancy_id"
-	resumes, err := h.svc.GetResumesByVacancy(c.Request.Context(), vacancyID)
+
+	filter, err := parseResumeFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.svc.ListResumes(c.Request.Context(), vacancyID, filter, c.Query("cursor"))
+	if errors.Is(err, service.ErrInvalidResumeQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get resumes"})
 		return
 	}
 
+	c.JSON(http.StatusOK, page)
+}
+
+func parseResumeFilter(c *gin.Context) (models.ResumeFilter, error) {
+	filter := models.ResumeFilter{
+		Sort:            c.Query("sort"),
+		IncludeAnalysis: c.Query("include") == "analysis",
+	}
+
+	for _, status := range splitQueryList(c.Query("status")) {
+		filter.Statuses = append(filter.Statuses, models.ResumeStatus(status))
+	}
+	filter.Skills = splitQueryList(c.Query("skills"))
+
+	switch c.Query("order") {
+	case "", "desc":
+		// По умолчанию сначала новые или с наибольшим баллом
+		filter.Desc = true
+	case "asc":
+	default:
+		return filter, fmt.Errorf("order must be asc or desc")
+	}
+
+	var err error
+	if filter.MinScore, err = parseQueryFloat(c, "min_score"); err != nil {
+		return filter, err
+	}
+	if filter.MaxScore, err = parseQueryFloat(c, "max_score"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom, err = parseQueryTime(c, "created_from", false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseQueryTime(c, "created_to", true); err != nil {
+		return filter, err
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, fmt.Errorf("limit must be a number")
+		}
+	}
+	return filter, nil
+}
+
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseQueryFloat(c *gin.Context, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &f, nil
+}
+
+func parseFormFloat(c *gin.Context, name string) (*float64, error) {
+	value := c.PostForm(name)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &f, nil
+}
+
+func parseFormInt(c *gin.Context, name string) (*int, error) {
+	value := c.PostForm(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", name)
+	}
+	return &n, nil
+}
+
+// parseQueryTime принимает RFC3339 или дату. Дата в конце периода (endOfDay) включает весь день.
+func parseQueryTime(c *gin.Context, name string, endOfDay bool) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be RFC3339 or YYYY-MM-DD", name)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// GET /api/resumes/:id/duplicates
+func (h *ResumeHandler) GetDuplicates(c *gin.Context) {
+	duplicates, err := h.svc.GetDuplicates(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "resume not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, duplicates)
+}
+
+// GET /api/vacancies/:id/duplicates
+func (h *ResumeHandler) GetDuplicatesByVacancy(c *gin.Context) {
+	duplicates, err := h.svc.GetDuplicatesByVacancy(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get duplicates"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"count":   len(resumes),
-		"resumes": resumes,
+		"count":      len(duplicates),
+		"duplicates": duplicates,
 	})
 }
 
@@ -120,7 +271,8 @@ func (h *ResumeHandler) UpdateStatus(c *gin.Context) {
 	id := c.Param("id")
 
 	var req struct {
-		Status string                 `json:"status" binding:"required"`
+		Status models.ResumeStatus    `json:"status" binding:"required"`
+		Reason string                 `json:"reason"`
 		Result map[string]interface{} `json:"result"`
 	}
 
@@ -129,15 +281,53 @@ func (h *ResumeHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	err := h.svc.UpdateStatusAndResult(c.Request.Context(), id, req.Status, req.Result)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	userID, _ := c.Get("user_id")
+	err := h.svc.ChangeStatus(c.Request.Context(), id, service.StatusChange{
+		To:     req.Status,
+		Actor:  service.UserActor(userID),
+		Reason: req.Reason,
+		Result: req.Result,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "resume not found"})
+		return
+	case errors.Is(err, service.ErrUnknownResumeStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "allowed": models.ResumeStatuses})
+		return
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "status updated successfully"})
 }
 
+// GET /api/resumes/:id/status-history
+func (h *ResumeHandler) GetStatusHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	history, err := h.svc.GetStatusHistory(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "resume not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get status history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resume_id": id,
+		"count":     len(history),
+		"history":   history,
+	})
+}
+
 // DELETE /api/resumes/:id
 func (h *ResumeHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
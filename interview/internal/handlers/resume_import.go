@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"interview/internal/service"
+)
+
+type ResumeImportHandler struct {
+	svc service.ResumeImportService
+}
+
+func NewResumeImportHandler(svc service.ResumeImportService) *ResumeImportHandler {
+	return &ResumeImportHandler{svc: svc}
+}
+
+// POST /api/resumes/import
+// multipart: vacancy_id и один или несколько файлов в полях "files" или "file" (резюме или ZIP архивы)
+func (h *ResumeImportHandler) Create(c *gin.Context) {
+	vacancyID := c.PostForm("vacancy_id")
+	if vacancyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vacancy_id is required"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart form is required"})
+		return
+	}
+
+	var headers []*multipart.FileHeader
+	headers = append(headers, form.File["files"]...)
+	headers = append(headers, form.File["file"]...)
+	if len(headers) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one file is required"})
+		return
+	}
+
+	uploads := make([]service.ImportUpload, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open file " + header.Filename})
+			return
+		}
+		defer file.Close()
+
+		uploads = append(uploads, service.ImportUpload{
+			Filename: header.Filename,
+			Reader:   file,
+			Size:     header.Size,
+		})
+	}
+
+	job, err := h.svc.StartImport(c.Request.Context(), vacancyID, uploads)
+	if errors.Is(err, service.ErrImportNotRunning) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":      job.ID,
+		"status":      job.Status,
+		"total_files": job.TotalFiles,
+	})
+}
+
+// GET /api/resumes/imports/:id
+func (h *ResumeImportHandler) GetByID(c *gin.Context) {
+	job, err := h.svc.GetImport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
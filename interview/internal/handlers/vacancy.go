@@ -81,6 +81,17 @@ func (h *VacancyHandler) Create(c *gin.Context) {
 	weightSoft, _ := strconv.Atoi(c.DefaultPostForm("weight_soft", "33"))
 	weightHard, _ := strconv.Atoi(c.DefaultPostForm("weight_hard", "33"))
 	weightCase, _ := strconv.Atoi(c.DefaultPostForm("weight_case", "34"))
+	inviteThreshold, err := parseFormFloat(c, "invite_threshold")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requiresSlot, _ := strconv.ParseBool(c.DefaultPostForm("requires_slot", "false"))
+	policy, err := parseInterviewPolicyForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if title == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
@@ -107,6 +118,13 @@ func (h *VacancyHandler) Create(c *gin.Context) {
 		WeightSoft:  weightSoft,
 		WeightHard:  weightHard,
 		WeightCase:  weightCase,
+
+		InviteThreshold: inviteThreshold,
+		RequiresSlot:    requiresSlot,
+
+		InterviewMaxMinutes:   policy.maxMinutes,
+		InterviewMaxQuestions: policy.maxQuestions,
+		InterviewIdleMinutes:  policy.idleMinutes,
 	}
 
 	err = h.svc.CreateVacancy(c.Request.Context(), vacancy, file, fileHeader.Filename)
@@ -177,6 +195,13 @@ func (h *VacancyHandler) Update(c *gin.Context) {
 		WeightSoft  int     `json:"weight_soft" binding:"min=0,max=100"`
 		WeightHard  int     `json:"weight_hard" binding:"min=0,max=100"`
 		WeightCase  int     `json:"weight_case" binding:"min=0,max=100"`
+
+		InviteThreshold *float64 `json:"invite_threshold"`
+		RequiresSlot    bool     `json:"requires_slot"`
+
+		InterviewMaxMinutes   *int `json:"interview_max_minutes"`
+		InterviewMaxQuestions *int `json:"interview_max_questions"`
+		InterviewIdleMinutes  *int `json:"interview_idle_minutes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -192,6 +217,13 @@ func (h *VacancyHandler) Update(c *gin.Context) {
 		WeightSoft:  req.WeightSoft,
 		WeightHard:  req.WeightHard,
 		WeightCase:  req.WeightCase,
+
+		InviteThreshold: req.InviteThreshold,
+		RequiresSlot:    req.RequiresSlot,
+
+		InterviewMaxMinutes:   req.InterviewMaxMinutes,
+		InterviewMaxQuestions: req.InterviewMaxQuestions,
+		InterviewIdleMinutes:  req.InterviewIdleMinutes,
 	}
 
 	err := h.svc.UpdateVacancy(c.Request.Context(), vacancy)
@@ -213,6 +245,17 @@ func (h *VacancyHandler) UpdateWithFile(c *gin.Context) {
 	weightSoft, _ := strconv.Atoi(c.DefaultPostForm("weight_soft", "33"))
 	weightHard, _ := strconv.Atoi(c.DefaultPostForm("weight_hard", "33"))
 	weightCase, _ := strconv.Atoi(c.DefaultPostForm("weight_case", "34"))
+	inviteThreshold, err := parseFormFloat(c, "invite_threshold")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requiresSlot, _ := strconv.ParseBool(c.DefaultPostForm("requires_slot", "false"))
+	policy, err := parseInterviewPolicyForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if title == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
@@ -239,6 +282,13 @@ func (h *VacancyHandler) UpdateWithFile(c *gin.Context) {
 		WeightSoft:  weightSoft,
 		WeightHard:  weightHard,
 		WeightCase:  weightCase,
+
+		InviteThreshold: inviteThreshold,
+		RequiresSlot:    requiresSlot,
+
+		InterviewMaxMinutes:   policy.maxMinutes,
+		InterviewMaxQuestions: policy.maxQuestions,
+		InterviewIdleMinutes:  policy.idleMinutes,
 	}
 
 	err = h.svc.UpdateVacancyWithFile(c.Request.Context(), vacancy, file, fileHeader.Filename)
@@ -262,3 +312,25 @@ func (h *VacancyHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "vacancy deleted successfully"})
 }
+
+// interviewPolicyForm - ограничения собеседования из формы вакансии; пустое поле - значение по умолчанию
+type interviewPolicyForm struct {
+	maxMinutes   *int
+	maxQuestions *int
+	idleMinutes  *int
+}
+
+func parseInterviewPolicyForm(c *gin.Context) (interviewPolicyForm, error) {
+	var form interviewPolicyForm
+	var err error
+	if form.maxMinutes, err = parseFormInt(c, "interview_max_minutes"); err != nil {
+		return form, err
+	}
+	if form.maxQuestions, err = parseFormInt(c, "interview_max_questions"); err != nil {
+		return form, err
+	}
+	if form.idleMinutes, err = parseFormInt(c, "interview_idle_minutes"); err != nil {
+		return form, err
+	}
+	return form, nil
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"interview/internal/models"
+	"interview/internal/service"
+)
+
+type VacancyParserHandler struct {
+	svc service.VacancyParserService
+}
+
+func NewVacancyParserHandler(svc service.VacancyParserService) *VacancyParserHandler {
+	return &VacancyParserHandler{svc: svc}
+}
+
+// parserProfileRequest - тело запроса создания и обновления профиля
+type parserProfileRequest struct {
+	Name           string              `json:"name" binding:"required"`
+	Description    string              `json:"description"`
+	Priority       int                 `json:"priority"`
+	IsActive       *bool               `json:"is_active"`
+	Layout         string              `json:"layout"`
+	FuzzyThreshold float64             `json:"fuzzy_threshold"`
+	Aliases        map[string][]string `json:"aliases" binding:"required"`
+}
+
+func (r *parserProfileRequest) toModel(id string) (*models.VacancyParserProfile, error) {
+	aliases, err := json.Marshal(r.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	isActive := true
+	if r.IsActive != nil {
+		isActive = *r.IsActive
+	}
+
+	return &models.VacancyParserProfile{
+		ID:             id,
+		Name:           r.Name,
+		Description:    r.Description,
+		Priority:       r.Priority,
+		IsActive:       isActive,
+		Layout:         r.Layout,
+		FuzzyThreshold: r.FuzzyThreshold,
+		Aliases:        datatypes.JSON(aliases),
+	}, nil
+}
+
+// GET /api/vacancy-parser-profiles
+func (h *VacancyParserHandler) GetAll(c *gin.Context) {
+	profiles, err := h.svc.ListProfiles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get parser profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(profiles),
+		"profiles": profiles,
+	})
+}
+
+// GET /api/vacancy-parser-profiles/:id
+func (h *VacancyParserHandler) GetByID(c *gin.Context) {
+	profile, err := h.svc.GetProfile(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "parser profile not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// POST /api/vacancy-parser-profiles
+func (h *VacancyParserHandler) Create(c *gin.Context) {
+	var req parserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	profile, err := req.toModel("")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid aliases: " + err.Error()})
+		return
+	}
+
+	if err := h.svc.CreateProfile(c.Request.Context(), profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// PUT /api/vacancy-parser-profiles/:id
+func (h *VacancyParserHandler) Update(c *gin.Context) {
+	var req parserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	profile, err := req.toModel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid aliases: " + err.Error()})
+		return
+	}
+
+	if err := h.svc.UpdateProfile(c.Request.Context(), profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DELETE /api/vacancy-parser-profiles/:id
+func (h *VacancyParserHandler) Delete(c *gin.Context) {
+	if err := h.svc.DeleteProfile(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "parser profile deleted successfully"})
+}
+
+// POST /api/vacancies/preview - разбор файла вакансии без сохранения
+func (h *VacancyParserHandler) Preview(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.svc.PreviewFile(c.Request.Context(), file, fileHeader.Filename, c.PostForm("profile_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
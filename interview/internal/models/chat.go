@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type MessageType string
 
 const (
@@ -10,12 +12,21 @@ const (
 	MessageTypeError    MessageType = "error"
 )
 
+// ChatMessage - сообщение чата собеседования. Хранится в interview_messages,
+// Seq задает порядок сообщений внутри собеседования.
 type ChatMessage struct {
-	ID          string      `json:"id"`
-	InterviewID string      `json:"interview_id"`
-	Type        MessageType `json:"type"`
-	Content     string      `json:"content"`
-	Sender      string      `json:"sender"` // "candidate" или "ai"
+	ID          string      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	InterviewID string      `gorm:"type:uuid;not null;uniqueIndex:idx_interview_messages_seq" json:"interview_id"`
+	Seq         int         `gorm:"type:int;not null;uniqueIndex:idx_interview_messages_seq" json:"seq"`
+	Type        MessageType `gorm:"type:text;not null" json:"type"`
+	Content     string      `gorm:"type:text;not null" json:"content"`
+	Sender      string      `gorm:"type:text;not null" json:"sender"` // "candidate" или "ai"
+	CreatedAt   time.Time   `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (ChatMessage) TableName() string {
+	return "interview_messages"
 }
 
 type ChatSession struct {
@@ -25,6 +36,7 @@ type ChatSession struct {
 	MessageCount int           `json:"message_count"`
 	VacancyID    string        `json:"vacancy_id"`
 	ResumeID     string        `json:"resume_id"`
+	StartedAt    *time.Time    `json:"started_at,omitempty"`
 }
 
 type InterviewStatus struct {
@@ -32,4 +44,30 @@ type InterviewStatus struct {
 	Status       string `json:"status"`
 	IsActive     bool   `json:"is_active"`
 	MessageCount int    `json:"message_count"`
+
+	// Ограничения идущего собеседования. Deadline и RemainingSeconds не заданы, если время не ограничено.
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	RemainingSeconds *int       `json:"remaining_seconds,omitempty"`
+	QuestionsAsked   int        `json:"questions_asked"`
+	MaxQuestions     int        `json:"max_questions,omitempty"`
+}
+
+// Типы событий чата, которые получает кандидат в реальном времени
+const (
+	ChatEventMessage = "message" // сообщение сохранено
+	ChatEventTyping  = "typing"  // AI готовит ответ
+	ChatEventChunk   = "chunk"   // часть ответа AI по мере генерации
+	ChatEventStatus  = "status"  // изменилось состояние собеседования
+	ChatEventError   = "error"
+)
+
+// ChatEvent - событие чата собеседования. Чанки одного ответа связаны RequestID.
+type ChatEvent struct {
+	Type        string           `json:"type"`
+	InterviewID string           `json:"interview_id"`
+	RequestID   string           `json:"request_id,omitempty"`
+	Delta       string           `json:"delta,omitempty"`
+	Message     *ChatMessage     `json:"message,omitempty"`
+	Status      *InterviewStatus `json:"status,omitempty"`
+	Error       string           `json:"error,omitempty"`
 }
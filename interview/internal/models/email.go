@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Статусы письма в очереди отправки
+const (
+	EmailStatusQueued    = "queued"
+	EmailStatusSending   = "sending"
+	EmailStatusSent      = "sent"
+	EmailStatusFailed    = "failed"    // попытки закончились
+	EmailStatusBounced   = "bounced"   // почтовый сервер отклонил адрес, повтор не поможет
+	EmailStatusCancelled = "cancelled" // письмо больше не нужно: напоминание о перенесенном или отмененном собеседовании
+)
+
+// Виды писем
+const (
+	EmailKindInvitation   = "interview_invitation"
+	EmailKindScheduled    = "interview_scheduled" // кандидат выбрал или перенес время
+	EmailKindReminder     = "interview_reminder"
+	EmailKindCancellation = "interview_cancellation"
+)
+
+// EmailMessage - письмо в очереди отправки. Письмо готово к отправке целиком,
+// повторная отправка HR создает новое письмо, чтобы история попыток сохранялась.
+type EmailMessage struct {
+	ID          string  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Kind        string  `gorm:"type:text;not null" json:"kind"`
+	InterviewID *string `gorm:"type:uuid;index" json:"interview_id,omitempty"`
+	VacancyID   *string `gorm:"type:uuid;index" json:"vacancy_id,omitempty"`
+	ResumeID    *string `gorm:"type:uuid" json:"resume_id,omitempty"`
+	Recipient   string  `gorm:"type:text;not null" json:"recipient"`
+	Subject     string  `gorm:"type:text;not null" json:"subject"`
+	HTMLBody    string  `gorm:"type:text;not null" json:"-"`
+	// CalendarICS - приложение .ics (RFC 5545), CalendarMethod - его METHOD: REQUEST или CANCEL
+	CalendarICS    string     `gorm:"type:text" json:"-"`
+	CalendarMethod string     `gorm:"type:text" json:"calendar_method,omitempty"`
+	Status         string     `gorm:"type:text;not null;default:'queued';index:idx_email_outbox_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextRunAt      time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP;index:idx_email_outbox_due,priority:2" json:"next_run_at"`
+	LockedAt       *time.Time `gorm:"type:timestamptz" json:"-"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	SentAt         *time.Time `gorm:"type:timestamptz" json:"sent_at,omitempty"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (EmailMessage) TableName() string {
+	return "email_outbox"
+}
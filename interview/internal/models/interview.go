@@ -6,16 +6,38 @@ import (
 	"gorm.io/datatypes"
 )
 
+// Статусы собеседования
+const (
+	InterviewStatusPending   = "pending"   // ссылка выдана, кандидат еще не начал
+	InterviewStatusStarted   = "started"   // идет чат
+	InterviewStatusFinished  = "finished"  // завершено кандидатом или AI
+	InterviewStatusCancelled = "cancelled" // отменено HR, ссылка больше не работает
+	InterviewStatusExpired   = "expired"   // кандидат не начал собеседование до истечения ссылки
+)
+
 type Interview struct {
-	ID          string         `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	ResumeID    *string        `gorm:"type:uuid" json:"resume_id,omitempty"`
-	VacancyID   string         `gorm:"type:uuid;index" json:"vacancy_id"`
-	Status      string         `gorm:"type:text;not null;default:'pending'" json:"status"`
-	TextJSONB   datatypes.JSON `gorm:"type:jsonb;column:text_jsonb" json:"text_jsonb,omitempty"`
-	URLToken    *string        `gorm:"uniqueIndex" json:"url_token,omitempty"`
+	ID        string         `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ResumeID  *string        `gorm:"type:uuid" json:"resume_id,omitempty"`
+	VacancyID string         `gorm:"type:uuid;index" json:"vacancy_id"`
+	Status    string         `gorm:"type:text;not null;default:'pending'" json:"status"`
+	TextJSONB datatypes.JSON `gorm:"type:jsonb;column:text_jsonb" json:"text_jsonb,omitempty"`
+	// TokenHash - SHA-256 токена ссылки кандидата. Сам токен не хранится: потерянную ссылку HR выпускает заново.
+	TokenHash   *string        `gorm:"column:token_hash;uniqueIndex" json:"-"`
 	ScoreJSONB  datatypes.JSON `gorm:"type:jsonb;column:score_jsonb" json:"score_jsonb,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	ScheduledAt *time.Time     `json:"scheduled_at,omitempty" gorm:"column:date_start"`
 	ExpiresAt   *time.Time     `json:"expires_at,omitempty" gorm:"column:updated_at"`
 	StartedAt   *time.Time     `json:"started_at,omitempty"`
+
+	// SlotID - выбранное кандидатом окно. ScheduledAt и ExpiresAt тогда равны началу и концу окна.
+	SlotID          *string `gorm:"type:uuid;index" json:"slot_id,omitempty"`
+	RescheduleCount int     `gorm:"type:int;not null;default:0" json:"reschedule_count"`
+
+	// Аренда хода чата: сообщения одного собеседования обрабатываются по очереди на любой реплике.
+	// ChatLockOwner - токен захватившего ход, освободить аренду может только он.
+	ChatLockedUntil *time.Time `gorm:"type:timestamptz" json:"-"`
+	ChatLockOwner   *string    `gorm:"type:text" json:"-"`
+
+	// URL - ссылка кандидата, известна только сразу после выпуска токена
+	URL string `gorm:"-" json:"interview_url,omitempty"`
 }
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// InterviewSlot - окно, в которое HR готов провести собеседование по вакансии.
+// Кандидат выбирает окно по своей ссылке, собеседование открывается только в выбранное окно.
+type InterviewSlot struct {
+	ID        string    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	VacancyID string    `gorm:"type:uuid;not null;index:idx_interview_slots_vacancy,priority:1" json:"vacancy_id"`
+	StartsAt  time.Time `gorm:"type:timestamptz;not null;index:idx_interview_slots_vacancy,priority:2" json:"starts_at"`
+	EndsAt    time.Time `gorm:"type:timestamptz;not null" json:"ends_at"`
+	// Capacity - сколько кандидатов можно записать в окно
+	Capacity  int       `gorm:"type:int;not null;default:1;check:capacity>0" json:"capacity"`
+	CreatedAt time.Time `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// Booked - сколько ожидающих и идущих собеседований записано в окно, считается при чтении
+	Booked int `gorm:"->;-:migration" json:"booked"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (InterviewSlot) TableName() string {
+	return "interview_slots"
+}
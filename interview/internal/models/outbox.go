@@ -0,0 +1,27 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"time"
+)
+
+// OutboxMessage - сообщение для RabbitMQ, сохраненное в одной транзакции с изменением данных.
+// OutboxRelay отправляет его в брокер и проставляет SentAt.
+// Сообщение, которое не удалось отправить за все попытки, получает DeadAt и больше не отправляется.
+type OutboxMessage struct {
+	ID            string         `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	AggregateID   string         `gorm:"type:uuid;not null;index" json:"aggregate_id"` // ID резюме
+	Payload       datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
+	Attempts      int            `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError     string         `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time      `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"next_attempt_at"`
+	LockedAt      *time.Time     `gorm:"type:timestamptz" json:"-"`
+	CreatedAt     time.Time      `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	SentAt        *time.Time     `gorm:"type:timestamptz" json:"sent_at,omitempty"`
+	DeadAt        *time.Time     `gorm:"type:timestamptz" json:"dead_at,omitempty"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
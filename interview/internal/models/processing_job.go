@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Статусы задания обработки резюме
+const (
+	ProcessingJobPending = "pending"
+	ProcessingJobRunning = "running"
+	ProcessingJobDone    = "done"
+	ProcessingJobFailed  = "failed"
+)
+
+// ResumeProcessingJob - задание на обработку резюме (извлечение текста, профиль, отправка на анализ).
+// Для каждого резюме хранится одно задание, повторная постановка сбрасывает его в pending.
+type ResumeProcessingJob struct {
+	ID        string     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ResumeID  string     `gorm:"type:uuid;not null;uniqueIndex" json:"resume_id"`
+	Status    string     `gorm:"type:text;not null;default:'pending';index:idx_resume_processing_jobs_due,priority:1" json:"status"`
+	Attempts  int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextRunAt time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP;index:idx_resume_processing_jobs_due,priority:2" json:"next_run_at"`
+	LockedAt  *time.Time `gorm:"type:timestamptz" json:"locked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (ResumeProcessingJob) TableName() string {
+	return "resume_processing_jobs"
+}
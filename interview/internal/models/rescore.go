@@ -0,0 +1,81 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"time"
+)
+
+// Статусы пересчета оценок вакансии
+const (
+	RescoreStatusPending   = "pending"
+	RescoreStatusRunning   = "running"
+	RescoreStatusCompleted = "completed"
+	RescoreStatusFailed    = "failed"
+)
+
+// VacancyWeights - веса компонентов оценки вакансии
+type VacancyWeights struct {
+	Soft int `json:"soft"`
+	Hard int `json:"hard"`
+	Case int `json:"case"`
+}
+
+// Weights возвращает текущие веса вакансии
+func (v *Vacancy) Weights() VacancyWeights {
+	return VacancyWeights{Soft: v.WeightSoft, Hard: v.WeightHard, Case: v.WeightCase}
+}
+
+// VacancyRescoreJob - пересчет оценок кандидатов после изменения весов вакансии.
+// Рейтинги до и после пересчета сохраняются, чтобы их можно было сравнить.
+type VacancyRescoreJob struct {
+	ID        string `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	VacancyID string `gorm:"type:uuid;not null;index" json:"vacancy_id"`
+	Status    string `gorm:"type:text;not null;default:'pending';index" json:"status"`
+
+	OldWeightSoft int `gorm:"type:int;not null" json:"old_weight_soft"`
+	OldWeightHard int `gorm:"type:int;not null" json:"old_weight_hard"`
+	OldWeightCase int `gorm:"type:int;not null" json:"old_weight_case"`
+	WeightSoft    int `gorm:"type:int;not null" json:"weight_soft"`
+	WeightHard    int `gorm:"type:int;not null" json:"weight_hard"`
+	WeightCase    int `gorm:"type:int;not null" json:"weight_case"`
+
+	// Прогресс: recomputed - пересчитаны по сохраненным оценкам компонентов,
+	// republished - отправлены на повторный анализ, skipped - пересчитывать нечего
+	Total       int    `gorm:"type:int;not null;default:0" json:"total"`
+	Processed   int    `gorm:"type:int;not null;default:0" json:"processed"`
+	Recomputed  int    `gorm:"type:int;not null;default:0" json:"recomputed"`
+	Republished int    `gorm:"type:int;not null;default:0" json:"republished"`
+	Skipped     int    `gorm:"type:int;not null;default:0" json:"skipped"`
+	Failed      int    `gorm:"type:int;not null;default:0" json:"failed"`
+	Error       string `gorm:"type:text" json:"error,omitempty"`
+
+	RankingBefore datatypes.JSON `gorm:"type:jsonb" json:"-"`
+	RankingAfter  datatypes.JSON `gorm:"type:jsonb" json:"-"`
+
+	CreatedAt  time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	StartedAt  *time.Time `gorm:"type:timestamptz" json:"started_at,omitempty"`
+	FinishedAt *time.Time `gorm:"type:timestamptz" json:"finished_at,omitempty"`
+}
+
+// OldWeights - веса до изменения
+func (j *VacancyRescoreJob) OldWeights() VacancyWeights {
+	return VacancyWeights{Soft: j.OldWeightSoft, Hard: j.OldWeightHard, Case: j.OldWeightCase}
+}
+
+// NewWeights - веса после изменения
+func (j *VacancyRescoreJob) NewWeights() VacancyWeights {
+	return VacancyWeights{Soft: j.WeightSoft, Hard: j.WeightHard, Case: j.WeightCase}
+}
+
+// TableName указывает имя таблицы для GORM
+func (VacancyRescoreJob) TableName() string {
+	return "vacancy_rescore_jobs"
+}
+
+// RankingEntry - позиция кандидата в сохраненном рейтинге
+type RankingEntry struct {
+	ResumeID string  `json:"resume_id"`
+	FullName string  `json:"full_name,omitempty"`
+	Rank     int     `json:"rank"`
+	Total    float64 `json:"total"`
+}
@@ -11,15 +11,136 @@ type Resume struct {
 	CreatedAt time.Time `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 
 	// Основные поля из БД
-	FileURL    string `gorm:"type:text;column:file_url" json:"file_url,omitempty"`
-	StorageKey string `gorm:"type:varchar(255);column:storage_key" json:"-"`
-	Text       string `gorm:"type:text" json:"text,omitempty"`
-	Status     string `gorm:"type:text;default:'pending'" json:"status"`
-	Mail       string `gorm:"type:text" json:"mail,omitempty"`
+	FileURL    string       `gorm:"type:text;column:file_url" json:"file_url,omitempty"`
+	StorageKey string       `gorm:"type:varchar(255);column:storage_key" json:"-"`
+	Text       string       `gorm:"type:text" json:"text,omitempty"`
+	Status     ResumeStatus `gorm:"type:text;default:'pending'" json:"status"`
+	Mail       string       `gorm:"type:text" json:"mail,omitempty"`
 
 	// JSONB поля для анализа
 	ResultJSONB         datatypes.JSON `gorm:"type:jsonb;column:result_jsonb" json:"result_jsonb,omitempty"`
 	ResumeAnalysisJSONB datatypes.JSON `gorm:"type:jsonb;column:resume_analysis_jsonb" json:"resume_analysis_jsonb,omitempty"`
+
+	// Итоговый балл: overall_assessment.final.score из результата собеседования (result_jsonb),
+	// а до собеседования - overall_assessment.final_score из анализа резюме.
+	// Генерируемая колонка, только для чтения; создается миграцией db.migrateResumeScore, а не AutoMigrate.
+	Score *float64 `gorm:"->;-:migration;type:numeric" json:"score,omitempty"`
+
+	// Структурированный профиль кандидата (CandidateProfile), разобранный из текста резюме
+	ProfileJSONB datatypes.JSON `gorm:"type:jsonb;column:profile_jsonb" json:"profile,omitempty"`
+
+	// Поиск дубликатов: SHA-256 файла и отпечатки нормализованных email/телефона
+	ContentHash      string  `gorm:"type:varchar(64);not null;default:'';index" json:"content_hash,omitempty"`
+	EmailFingerprint string  `gorm:"type:varchar(64);not null;default:'';index" json:"-"`
+	PhoneFingerprint string  `gorm:"type:varchar(64);not null;default:'';index" json:"-"`
+	DuplicateOf      *string `gorm:"type:uuid;column:duplicate_of" json:"duplicate_of,omitempty"`
+}
+
+// ResumeDuplicateMatch - совпадение резюме с другим резюме того же кандидата
+type ResumeDuplicateMatch struct {
+	ResumeID     string    `json:"-"`
+	DuplicateID  string    `json:"resume_id"`
+	VacancyID    string    `json:"vacancy_id"`
+	VacancyTitle string    `json:"vacancy_title"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	SameContent  bool      `json:"same_content"`
+	SameEmail    bool      `json:"same_email"`
+	SamePhone    bool      `json:"same_phone"`
+}
+
+// ResumeListItem - облегченное представление резюме для списков: без текста и JSONB полей
+type ResumeListItem struct {
+	ID          string         `json:"id"`
+	VacancyID   string         `json:"vacancy_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Status      ResumeStatus   `json:"status"`
+	Mail        string         `json:"mail,omitempty"`
+	Score       *float64       `json:"score,omitempty"`
+	FullName    string         `json:"full_name,omitempty"`
+	Skills      datatypes.JSON `json:"skills,omitempty"`
+	DuplicateOf *string        `json:"duplicate_of,omitempty"`
+
+	// Заполняется только по запросу (include=analysis)
+	ResumeAnalysisJSONB datatypes.JSON `gorm:"column:resume_analysis_jsonb" json:"resume_analysis_jsonb,omitempty"`
+}
+
+// ResumeScores - оценки резюме для ранжирования: анализ резюме и результат собеседования
+type ResumeScores struct {
+	ID                  string         `json:"id"`
+	Status              ResumeStatus   `json:"status"`
+	Mail                string         `json:"mail,omitempty"`
+	FullName            string         `json:"full_name,omitempty"`
+	CreatedAt           time.Time      `json:"created_at"`
+	ResumeAnalysisJSONB datatypes.JSON `gorm:"column:resume_analysis_jsonb" json:"-"`
+	ResultJSONB         datatypes.JSON `gorm:"column:result_jsonb" json:"-"`
+}
+
+// Поля сортировки списка резюме
+const (
+	ResumeSortCreatedAt = "created_at"
+	ResumeSortScore     = "score"
+)
+
+// ResumeFilter - фильтры и сортировка списка резюме вакансии
+type ResumeFilter struct {
+	Statuses    []ResumeStatus
+	MinScore    *float64
+	MaxScore    *float64
+	CreatedFrom *time.Time // включительно
+	CreatedTo   *time.Time // не включительно
+	Skills      []string   // все навыки должны быть в профиле
+	Sort        string     // ResumeSortCreatedAt или ResumeSortScore
+	Desc        bool
+	Limit       int
+
+	IncludeAnalysis bool
+}
+
+// ResumeCursor - позиция последнего элемента страницы в порядке сортировки
+type ResumeCursor struct {
+	Sort      string    `json:"sort"`
+	Desc      bool      `json:"desc,omitempty"`
+	Score     float64   `json:"score,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        string    `json:"id"`
+}
+
+// CandidateProfile - структурированные данные кандидата, извлеченные из текста резюме
+type CandidateProfile struct {
+	FullName              string           `json:"full_name,omitempty"`
+	Email                 string           `json:"email,omitempty"`
+	Phone                 string           `json:"phone,omitempty"`
+	City                  string           `json:"city,omitempty"`
+	TotalExperienceMonths int              `json:"total_experience_months,omitempty"`
+	Experience            []WorkExperience `json:"experience,omitempty"`
+	Education             []Education      `json:"education,omitempty"`
+	Skills                []string         `json:"skills,omitempty"`
+	Languages             []Language       `json:"languages,omitempty"`
+}
+
+// WorkExperience - место работы. Даты в формате YYYY-MM (или YYYY, если месяц не указан)
+type WorkExperience struct {
+	Company     string `json:"company,omitempty"`
+	Position    string `json:"position,omitempty"`
+	StartDate   string `json:"start_date,omitempty"`
+	EndDate     string `json:"end_date,omitempty"`
+	Current     bool   `json:"current,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Education - учебное заведение или курс
+type Education struct {
+	Level       string `json:"level,omitempty"`
+	Institution string `json:"institution,omitempty"`
+	Specialty   string `json:"specialty,omitempty"`
+	Year        int    `json:"year,omitempty"`
+}
+
+// Language - иностранный язык и уровень владения
+type Language struct {
+	Name  string `json:"name"`
+	Level string `json:"level,omitempty"`
 }
 
 // TableName указывает имя таблицы для GORM
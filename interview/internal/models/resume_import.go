@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Статусы пакетного импорта резюме
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed" // импорт прерван, необработанные файлы помечены failed
+
+	ImportItemPending = "pending"
+	ImportItemCreated = "created"
+	ImportItemFailed  = "failed"
+)
+
+// ResumeImportJob - пакетная загрузка резюме (ZIP архив или несколько файлов) для одной вакансии
+type ResumeImportJob struct {
+	ID         string     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	VacancyID  string     `gorm:"type:uuid;not null;index" json:"vacancy_id"`
+	Status     string     `gorm:"type:text;not null;default:'pending'" json:"status"`
+	TotalFiles int        `gorm:"type:int;not null;default:0" json:"total_files"`
+	Succeeded  int        `gorm:"type:int;not null;default:0" json:"succeeded"`
+	Failed     int        `gorm:"type:int;not null;default:0" json:"failed"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	FinishedAt *time.Time `gorm:"type:timestamptz" json:"finished_at,omitempty"`
+	// HeartbeatAt обновляется после каждого файла. Задание без обновлений брошено упавшей репликой.
+	HeartbeatAt *time.Time `gorm:"type:timestamptz" json:"-"`
+
+	Items []ResumeImportItem `gorm:"foreignKey:JobID" json:"items,omitempty"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (ResumeImportJob) TableName() string {
+	return "resume_import_jobs"
+}
+
+// ResumeImportItem - результат импорта одного файла
+type ResumeImportItem struct {
+	ID        string    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	JobID     string    `gorm:"type:uuid;not null;index" json:"job_id"`
+	Position  int       `gorm:"type:int;not null" json:"position"`
+	Filename  string    `gorm:"type:text;not null" json:"filename"`
+	SizeBytes int64     `gorm:"type:bigint;not null;default:0" json:"size_bytes"`
+	Status    string    `gorm:"type:text;not null;default:'pending'" json:"status"`
+	ResumeID  *string   `gorm:"type:uuid" json:"resume_id,omitempty"`
+	Error     string    `gorm:"type:text" json:"error,omitempty"`
+	UpdatedAt time.Time `gorm:"type:timestamptz" json:"updated_at"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (ResumeImportItem) TableName() string {
+	return "resume_import_items"
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// ResumeStatus - этап, на котором находится резюме.
+// Значения совпадают со строками, которые уже хранятся в БД.
+type ResumeStatus string
+
+const (
+	ResumeStatusPending      ResumeStatus = "pending"       // значение по умолчанию в БД
+	ResumeStatusParsed       ResumeStatus = "Прошел парсер" // файл загружен, ждет обработки и анализа
+	ResumeStatusAnalyzed     ResumeStatus = "analyzed"      // CV-review сервис вернул анализ
+	ResumeStatusError        ResumeStatus = "error"         // обработка или анализ не удались
+	ResumeStatusInterviewing ResumeStatus = "Проходит собеседование"
+	ResumeStatusInterviewed  ResumeStatus = "Прошел собеседование"
+	ResumeStatusAccepted     ResumeStatus = "accepted" // решение HR
+	ResumeStatusRejected     ResumeStatus = "rejected" // решение HR
+)
+
+// ResumeStatuses - все известные статусы
+var ResumeStatuses = []ResumeStatus{
+	ResumeStatusPending,
+	ResumeStatusParsed,
+	ResumeStatusAnalyzed,
+	ResumeStatusError,
+	ResumeStatusInterviewing,
+	ResumeStatusInterviewed,
+	ResumeStatusAccepted,
+	ResumeStatusRejected,
+}
+
+// Valid сообщает, является ли значение известным статусом
+func (s ResumeStatus) Valid() bool {
+	for _, status := range ResumeStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Инициаторы смены статуса, кроме пользователей (для них "user:<id>")
+const (
+	ResumeActorSystem     = "system"
+	ResumeActorProcessing = "processing"
+	ResumeActorCVReview   = "cv-review"
+	ResumeActorInterview  = "interview"
+)
+
+// ResumeStatusHistory - запись о смене статуса резюме
+type ResumeStatusHistory struct {
+	ID         string       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ResumeID   string       `gorm:"type:uuid;not null;index:idx_resume_status_history_resume,priority:1" json:"resume_id"`
+	FromStatus ResumeStatus `gorm:"type:text;not null;default:''" json:"from_status"`
+	ToStatus   ResumeStatus `gorm:"type:text;not null" json:"to_status"`
+	Actor      string       `gorm:"type:text;not null" json:"actor"`
+	Reason     string       `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time    `gorm:"type:timestamptz;not null;default:CURRENT_TIMESTAMP;index:idx_resume_status_history_resume,priority:2" json:"created_at"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (ResumeStatusHistory) TableName() string {
+	return "resume_status_history"
+}
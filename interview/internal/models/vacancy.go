@@ -23,4 +23,16 @@ type Vacancy struct {
 	WeightHard int            `gorm:"type:int;default:33;check:weight_hard>=0 AND weight_hard<=100" json:"weight_hard"`
 	WeightCase int            `gorm:"type:int;default:34;check:weight_case>=0 AND weight_case<=100" json:"weight_case"`
 	TextJSONB  datatypes.JSON `gorm:"type:jsonb;column:text_jsonb" json:"text_jsonb,omitempty"`
+
+	// InviteThreshold - минимальная оценка резюме для автоматического приглашения на собеседование.
+	// nil - приглашения отправляет только HR.
+	InviteThreshold *float64 `gorm:"column:invite_threshold" json:"invite_threshold,omitempty"`
+
+	// RequiresSlot - кандидат сам выбирает время собеседования из окон InterviewSlot
+	RequiresSlot bool `gorm:"column:requires_slot;not null;default:false" json:"requires_slot"`
+
+	// Ограничения собеседования. nil - действует значение по умолчанию из настроек сервиса.
+	InterviewMaxMinutes   *int `gorm:"column:interview_max_minutes" json:"interview_max_minutes,omitempty"`
+	InterviewMaxQuestions *int `gorm:"column:interview_max_questions" json:"interview_max_questions,omitempty"`
+	InterviewIdleMinutes  *int `gorm:"column:interview_idle_minutes" json:"interview_idle_minutes,omitempty"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Раскладка шаблона вакансии
+const (
+	VacancyLayoutAuto  = "auto"  // заголовки отдельными строками и строки таблиц
+	VacancyLayoutLines = "lines" // только заголовки отдельными строками
+	VacancyLayoutTable = "table" // только таблица "заголовок | значение"
+)
+
+// VacancyParserProfile - профиль разбора шаблона вакансии.
+// Aliases хранит map[поле Job][]варианты заголовка, где поле - json-имя ("title", "salary_max", ...).
+type VacancyParserProfile struct {
+	ID             string         `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name           string         `gorm:"type:text;not null;uniqueIndex" json:"name"`
+	Description    string         `gorm:"type:text" json:"description,omitempty"`
+	Priority       int            `gorm:"type:int;not null;default:0" json:"priority"`
+	IsActive       bool           `gorm:"not null" json:"is_active"`
+	Layout         string         `gorm:"type:text;not null;default:'auto'" json:"layout"`
+	FuzzyThreshold float64        `gorm:"type:real;not null;default:0.85" json:"fuzzy_threshold"`
+	Aliases        datatypes.JSON `gorm:"type:jsonb;not null" json:"aliases"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      *time.Time     `json:"updated_at,omitempty"`
+}
+
+// TableName указывает имя таблицы для GORM
+func (VacancyParserProfile) TableName() string {
+	return "vacancy_parser_profiles"
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"interview/internal/models"
+)
+
+type ChatMessageRepository interface {
+	// Append сохраняет сообщение следующим по порядку в собеседовании
+	Append(ctx context.Context, msg *models.ChatMessage) error
+	ListByInterview(ctx context.Context, interviewID string) ([]models.ChatMessage, error)
+}
+
+type chatMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewChatMessageRepository(db *gorm.DB) ChatMessageRepository {
+	return &chatMessageRepository{db: db}
+}
+
+func (r *chatMessageRepository) Append(ctx context.Context, msg *models.ChatMessage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last int
+		err := tx.Model(&models.ChatMessage{}).
+			Select("COALESCE(MAX(seq), 0)").
+			Where("interview_id = ?", msg.InterviewID).
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		// Параллельная запись с тем же seq упадет на уникальном индексе
+		msg.Seq = last + 1
+		return tx.Create(msg).Error
+	})
+}
+
+func (r *chatMessageRepository) ListByInterview(ctx context.Context, interviewID string) ([]models.ChatMessage, error) {
+	var messages []models.ChatMessage
+	err := r.db.WithContext(ctx).
+		Where("interview_id = ?", interviewID).
+		Order("seq").
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"interview/internal/models"
+	"time"
+)
+
+type EmailOutboxRepository interface {
+	Enqueue(ctx context.Context, msg *models.EmailMessage) error
+	// ClaimNext захватывает одно готовое к отправке письмо. Несколько реплик не получат одно письмо.
+	// Если писем нет, возвращает nil.
+	ClaimNext(ctx context.Context) (*models.EmailMessage, error)
+	// MarkSent, MarkRetry и MarkFailed записывают результат, только если письмо все еще захвачено этим
+	// вызовом ClaimNext (status = sending и тот же locked_at). Иначе его уже вернул в очередь RecoverStale.
+	MarkSent(ctx context.Context, msg *models.EmailMessage) (bool, error)
+	MarkRetry(ctx context.Context, msg *models.EmailMessage, nextRunAt time.Time, lastError string) error
+	// MarkFailed завершает письмо без отправки со статусом failed или bounced
+	MarkFailed(ctx context.Context, msg *models.EmailMessage, status string, lastError string) error
+	// RecoverStale возвращает в очередь письма, захваченные раньше lockedBefore (упавший отправитель)
+	RecoverStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+	ListByInterview(ctx context.Context, interviewID string) ([]*models.EmailMessage, error)
+	// CancelQueued отменяет еще не отправленные письма собеседования этого вида
+	CancelQueued(ctx context.Context, interviewID string, kind string) (int64, error)
+	// LatestByVacancy возвращает последнее письмо каждого собеседования вакансии
+	LatestByVacancy(ctx context.Context, vacancyID string, kind string) ([]*models.EmailMessage, error)
+}
+
+type emailOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailOutboxRepository(db *gorm.DB) EmailOutboxRepository {
+	return &emailOutboxRepository{db: db}
+}
+
+func (r *emailOutboxRepository) Enqueue(ctx context.Context, msg *models.EmailMessage) error {
+	return enqueueEmail(r.db.WithContext(ctx), msg)
+}
+
+// enqueueEmail ставит письмо в очередь; db может быть транзакцией другого репозитория
+func enqueueEmail(db *gorm.DB, msg *models.EmailMessage) error {
+	now := time.Now()
+	msg.Status = models.EmailStatusQueued
+	// Напоминания ставятся в очередь заранее и ждут своего времени
+	if msg.NextRunAt.IsZero() {
+		msg.NextRunAt = now
+	}
+	msg.CreatedAt = now
+	msg.UpdatedAt = now
+	return db.Create(msg).Error
+}
+
+func (r *emailOutboxRepository) ClaimNext(ctx context.Context) (*models.EmailMessage, error) {
+	var messages []*models.EmailMessage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", models.EmailStatusQueued, time.Now()).
+			Order("next_run_at").
+			Limit(1).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		// Postgres хранит микросекунды; иначе Mark* не найдут письмо по locked_at
+		now := time.Now().Truncate(time.Microsecond)
+		msg := messages[0]
+		msg.Status = models.EmailStatusSending
+		msg.Attempts++
+		msg.LockedAt = &now
+
+		return tx.Model(&models.EmailMessage{}).
+			Where("id = ?", msg.ID).
+			Updates(map[string]interface{}{
+				"status":     models.EmailStatusSending,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"locked_at":  now,
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// claimedEmail ограничивает обновление письмом, которое захватил именно этот вызов ClaimNext
+func claimedEmail(db *gorm.DB, msg *models.EmailMessage) *gorm.DB {
+	return db.Model(&models.EmailMessage{}).
+		Where("id = ? AND status = ? AND locked_at = ?", msg.ID, models.EmailStatusSending, msg.LockedAt)
+}
+
+func (r *emailOutboxRepository) MarkSent(ctx context.Context, msg *models.EmailMessage) (bool, error) {
+	now := time.Now()
+	result := claimedEmail(r.db.WithContext(ctx), msg).
+		Updates(map[string]interface{}{
+			"status":     models.EmailStatusSent,
+			"last_error": "",
+			"locked_at":  nil,
+			"sent_at":    now,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *emailOutboxRepository) MarkRetry(ctx context.Context, msg *models.EmailMessage, nextRunAt time.Time, lastError string) error {
+	return claimedEmail(r.db.WithContext(ctx), msg).
+		Updates(map[string]interface{}{
+			"status":      models.EmailStatusQueued,
+			"last_error":  lastError,
+			"next_run_at": nextRunAt,
+			"locked_at":   nil,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+func (r *emailOutboxRepository) MarkFailed(ctx context.Context, msg *models.EmailMessage, status string, lastError string) error {
+	return claimedEmail(r.db.WithContext(ctx), msg).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": lastError,
+			"locked_at":  nil,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *emailOutboxRepository) RecoverStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.EmailMessage{}).
+		Where("status = ? AND locked_at < ?", models.EmailStatusSending, lockedBefore).
+		Updates(map[string]interface{}{
+			"status":      models.EmailStatusQueued,
+			"next_run_at": time.Now(),
+			"locked_at":   nil,
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *emailOutboxRepository) ListByInterview(ctx context.Context, interviewID string) ([]*models.EmailMessage, error) {
+	var messages []*models.EmailMessage
+	err := r.db.WithContext(ctx).
+		Where("interview_id = ?", interviewID).
+		Order("created_at DESC").
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *emailOutboxRepository) CancelQueued(ctx context.Context, interviewID string, kind string) (int64, error) {
+	result := cancelQueuedEmails(r.db.WithContext(ctx), interviewID, kind)
+	return result.RowsAffected, result.Error
+}
+
+// cancelQueuedEmails отменяет еще не отправленные письма собеседования, в том числе внутри транзакции
+func cancelQueuedEmails(db *gorm.DB, interviewID string, kind string) *gorm.DB {
+	return db.Model(&models.EmailMessage{}).
+		Where("interview_id = ? AND kind = ? AND status = ?", interviewID, kind, models.EmailStatusQueued).
+		Updates(map[string]interface{}{
+			"status":     models.EmailStatusCancelled,
+			"updated_at": time.Now(),
+		})
+}
+
+func (r *emailOutboxRepository) LatestByVacancy(ctx context.Context, vacancyID string, kind string) ([]*models.EmailMessage, error) {
+	var messages []*models.EmailMessage
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (interview_id) * FROM email_outbox
+			WHERE vacancy_id = ? AND kind = ?
+			ORDER BY interview_id, created_at DESC`, vacancyID, kind).
+		Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -3,21 +3,54 @@ package repository
 import (
 	"context"
 	"errors"
-	"strings"
+	"fmt"
+	"time"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"interview/internal/models"
 )
 
 type InterviewRepository interface {
 	Create(ctx context.Context, interview *models.Interview) error
+	// CreateInvited одной транзакцией переводит резюме по change, создает собеседование и ставит
+	// приглашение в очередь. Если статус резюме уже не change.FromStatus, возвращает ErrStatusChanged.
+	CreateInvited(ctx context.Context, interview *models.Interview, change *models.ResumeStatusHistory, invitation *models.EmailMessage) error
 	GetByID(ctx context.Context, id string) (*models.Interview, error)
-	GetByToken(ctx context.Context, token string) (*models.Interview, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.Interview, error)
 	GetByVacancyID(ctx context.Context, vacancyID string) ([]*models.Interview, error)
 	Update(ctx context.Context, interview *models.Interview) error
+	// UpdateTranscript сохраняет полную переписку собеседования в text_jsonb
+	UpdateTranscript(ctx context.Context, id string, transcript datatypes.JSON) error
+	// ClaimChatTurn захватывает ход чата на ttl для owner, чтобы сообщения одного собеседования
+	// обрабатывались по очереди на любой из реплик. Возвращает false, если ход уже занят.
+	ClaimChatTurn(ctx context.Context, id string, owner string, ttl time.Duration) (bool, error)
+	// ReleaseChatTurn освобождает ход, только если он все еще принадлежит owner:
+	// истекшую аренду мог захватить другой обработчик.
+	ReleaseChatTurn(ctx context.Context, id string, owner string) error
 	Delete(ctx context.Context, id string) error
 	DeleteInterview(ctx context.Context, interview *models.Interview) error
 	ListByVacancy(ctx context.Context, vacancyID string) ([]models.Interview, error) // Для обратной совместимости
+	// GetExpiredInterviews возвращает ожидающие собеседования с истекшей ссылкой
+	GetExpiredInterviews(ctx context.Context) ([]*models.Interview, error)
+	// GetIdleStarted возвращает начатые собеседования без сообщений дольше idle-таймаута вакансии,
+	// а если он не задан - дольше defaultTimeout. Нулевой defaultTimeout - без ограничения.
+	// Собеседования, в которых сейчас идет ход чата, пропускаются.
+	GetIdleStarted(ctx context.Context, defaultTimeout time.Duration) ([]*models.Interview, error)
+	// GetOvertimeStarted возвращает начатые собеседования, идущие дольше ограничения вакансии,
+	// а если оно не задано - дольше defaultDuration. Нулевой defaultDuration - без ограничения.
+	GetOvertimeStarted(ctx context.Context, defaultDuration time.Duration) ([]*models.Interview, error)
+	// ReissueToken заменяет токен ожидающего собеседования и продлевает ссылку. Возвращает false, если собеседование уже не ожидает.
+	ReissueToken(ctx context.Context, id string, tokenHash string, expiresAt time.Time) (bool, error)
+	// ReissueWithInvitation одной транзакцией заменяет токен ожидающего собеседования, отменяет
+	// неотправленные приглашения со старой ссылкой и ставит в очередь invitation. Возвращает false, если собеседование уже не ожидает.
+	ReissueWithInvitation(ctx context.Context, id string, tokenHash string, expiresAt time.Time, invitation *models.EmailMessage) (bool, error)
+	// RevokeToken удаляет токен ожидающего или идущего собеседования, ссылка перестает работать
+	RevokeToken(ctx context.Context, id string) (bool, error)
+	// TransitionStatus меняет статус, только если он все еще равен from. Возвращает false, если статус уже другой.
+	TransitionStatus(ctx context.Context, id string, from, to string) (bool, error)
+	// StartIfPending начинает ожидающее собеседование и записывает время начала. Возвращает false, если собеседование уже не ожидает.
+	StartIfPending(ctx context.Context, id string, startedAt time.Time) (bool, error)
 }
 
 type interviewRepository struct {
@@ -35,6 +68,18 @@ func (r *interviewRepository) Create(ctx context.Context, interview *models.Inte
 	return r.db.WithContext(ctx).Create(interview).Error
 }
 
+func (r *interviewRepository) CreateInvited(ctx context.Context, interview *models.Interview, change *models.ResumeStatusHistory, invitation *models.EmailMessage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := applyStatusChange(tx, change, map[string]interface{}{"status": change.ToStatus}); err != nil {
+			return err
+		}
+		if err := tx.Create(interview).Error; err != nil {
+			return err
+		}
+		return enqueueEmail(tx, invitation)
+	})
+}
+
 func (r *interviewRepository) GetByID(ctx context.Context, id string) (*models.Interview, error) {
 	if id == "" {
 		return nil, errors.New("id cannot be empty")
@@ -43,40 +88,25 @@ func (r *interviewRepository) GetByID(ctx context.Context, id string) (*models.I
 	var interview models.Interview
 	if err := r.db.WithContext(ctx).First(&interview, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("interview not found")
+			return nil, fmt.Errorf("interview not found: %w", err)
 		}
 		return nil, err
 	}
 	return &interview, nil
 }
 
-func (r *interviewRepository) GetByToken(ctx context.Context, token string) (*models.Interview, error) {
-	if token == "" {
+func (r *interviewRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Interview, error) {
+	if tokenHash == "" {
 		return nil, errors.New("token cannot be empty")
 	}
 
 	var interview models.Interview
-
-	query := r.db.WithContext(ctx)
-
-	if strings.Contains(token, "/interview/") {
-		err := query.Where("url_token = ?", token).First(&interview).Error
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("interview not found")
-			}
-			return nil, err
-		}
-	} else {
-		err := query.Where("url_token LIKE ?", "%/interview/"+token).First(&interview).Error
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("interview not found")
-			}
-			return nil, err
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&interview).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("interview not found: %w", err)
 		}
+		return nil, err
 	}
-
 	return &interview, nil
 }
 
@@ -100,7 +130,48 @@ func (r *interviewRepository) Update(ctx context.Context, interview *models.Inte
 		return errors.New("interview ID cannot be empty")
 	}
 
-	return r.db.WithContext(ctx).Model(interview).Select("*").Updates(interview).Error
+	// Переписку и аренду хода пишет только чат, а токен - выпуск ссылки, устаревшая копия не должна их затирать
+	return r.db.WithContext(ctx).Model(interview).Select("*").
+		Omit("text_jsonb", "token_hash", "chat_locked_until", "chat_lock_owner").
+		Updates(interview).Error
+}
+
+func (r *interviewRepository) UpdateTranscript(ctx context.Context, id string, transcript datatypes.JSON) error {
+	if id == "" {
+		return errors.New("id cannot be empty")
+	}
+
+	return r.db.WithContext(ctx).Model(&models.Interview{}).
+		Where("id = ?", id).
+		Update("text_jsonb", transcript).Error
+}
+
+func (r *interviewRepository) ClaimChatTurn(ctx context.Context, id string, owner string, ttl time.Duration) (bool, error) {
+	if id == "" {
+		return false, errors.New("id cannot be empty")
+	}
+
+	// Время берется из БД, чтобы не зависеть от расхождения часов реплик
+	result := r.db.WithContext(ctx).Model(&models.Interview{}).
+		Where("id = ?", id).
+		Where("chat_locked_until IS NULL OR chat_locked_until < NOW()").
+		Updates(map[string]interface{}{
+			"chat_locked_until": gorm.Expr("NOW() + ?::interval", fmt.Sprintf("%d milliseconds", ttl.Milliseconds())),
+			"chat_lock_owner":   owner,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *interviewRepository) ReleaseChatTurn(ctx context.Context, id string, owner string) error {
+	return r.db.WithContext(ctx).Model(&models.Interview{}).
+		Where("id = ? AND chat_lock_owner = ?", id, owner).
+		Updates(map[string]interface{}{
+			"chat_locked_until": nil,
+			"chat_lock_owner":   nil,
+		}).Error
 }
 
 func (r *interviewRepository) Delete(ctx context.Context, id string) error {
@@ -142,7 +213,7 @@ func (r *interviewRepository) ListByVacancy(ctx context.Context, vacancyID strin
 func (r *interviewRepository) GetActiveInterviews(ctx context.Context) ([]*models.Interview, error) {
 	var interviews []*models.Interview
 	if err := r.db.WithContext(ctx).
-		Where("status IN ?", []string{"pending", "started"}).
+		Where("status IN ?", []string{models.InterviewStatusPending, models.InterviewStatusStarted}).
 		Where("date_start <= NOW()").
 		Where("updated_at >= NOW()").
 		Find(&interviews).Error; err != nil {
@@ -154,7 +225,7 @@ func (r *interviewRepository) GetActiveInterviews(ctx context.Context) ([]*model
 func (r *interviewRepository) GetExpiredInterviews(ctx context.Context) ([]*models.Interview, error) {
 	var interviews []*models.Interview
 	if err := r.db.WithContext(ctx).
-		Where("status IN ?", []string{"pending", "started"}).
+		Where("status = ?", models.InterviewStatusPending).
 		Where("updated_at < NOW()").
 		Find(&interviews).Error; err != nil {
 		return nil, err
@@ -162,6 +233,109 @@ func (r *interviewRepository) GetExpiredInterviews(ctx context.Context) ([]*mode
 	return interviews, nil
 }
 
+func (r *interviewRepository) GetIdleStarted(ctx context.Context, defaultTimeout time.Duration) ([]*models.Interview, error) {
+	var interviews []*models.Interview
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", models.InterviewStatusStarted).
+		Where("chat_locked_until IS NULL OR chat_locked_until < NOW()").
+		Where(`COALESCE(
+			(SELECT MAX(m.created_at) FROM interview_messages m WHERE m.interview_id = interviews.id),
+			started_at, created_at) < NOW() - COALESCE(
+			(SELECT v.interview_idle_minutes FROM vacancies v WHERE v.id = interviews.vacancy_id),
+			NULLIF(?::float8, 0)) * INTERVAL '1 minute'`, defaultTimeout.Minutes()).
+		Omit("text_jsonb").
+		Find(&interviews).Error; err != nil {
+		return nil, err
+	}
+	return interviews, nil
+}
+
+func (r *interviewRepository) GetOvertimeStarted(ctx context.Context, defaultDuration time.Duration) ([]*models.Interview, error) {
+	var interviews []*models.Interview
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", models.InterviewStatusStarted).
+		Where("chat_locked_until IS NULL OR chat_locked_until < NOW()").
+		Where(`COALESCE(started_at, created_at) < NOW() - COALESCE(
+			(SELECT v.interview_max_minutes FROM vacancies v WHERE v.id = interviews.vacancy_id),
+			NULLIF(?::float8, 0)) * INTERVAL '1 minute'`, defaultDuration.Minutes()).
+		Omit("text_jsonb").
+		Find(&interviews).Error; err != nil {
+		return nil, err
+	}
+	return interviews, nil
+}
+
+func (r *interviewRepository) ReissueToken(ctx context.Context, id string, tokenHash string, expiresAt time.Time) (bool, error) {
+	result := reissueToken(r.db.WithContext(ctx), id, tokenHash, expiresAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *interviewRepository) ReissueWithInvitation(ctx context.Context, id string, tokenHash string, expiresAt time.Time, invitation *models.EmailMessage) (bool, error) {
+	var reissued bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := reissueToken(tx, id, tokenHash, expiresAt)
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		reissued = true
+
+		if err := cancelQueuedEmails(tx, id, models.EmailKindInvitation).Error; err != nil {
+			return err
+		}
+		return enqueueEmail(tx, invitation)
+	})
+	if err != nil {
+		return false, err
+	}
+	return reissued, nil
+}
+
+// reissueToken заменяет токен, только пока собеседование ожидает начала. Срок ссылки хранится в updated_at.
+func reissueToken(db *gorm.DB, id string, tokenHash string, expiresAt time.Time) *gorm.DB {
+	return db.Model(&models.Interview{}).
+		Where("id = ? AND status = ?", id, models.InterviewStatusPending).
+		Updates(map[string]interface{}{
+			"token_hash": tokenHash,
+			"updated_at": expiresAt,
+		})
+}
+
+func (r *interviewRepository) RevokeToken(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Interview{}).
+		Where("id = ? AND status IN ?", id, []string{models.InterviewStatusPending, models.InterviewStatusStarted}).
+		Update("token_hash", nil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *interviewRepository) TransitionStatus(ctx context.Context, id string, from, to string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Interview{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *interviewRepository) StartIfPending(ctx context.Context, id string, startedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Interview{}).
+		Where("id = ? AND status = ?", id, models.InterviewStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.InterviewStatusStarted,
+			"started_at": startedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func (r *interviewRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&models.Interview{}).
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"interview/internal/models"
+)
+
+var ErrSlotFull = errors.New("interview slot is full")
+
+// Собеседования с этими статусами занимают место в окне
+var slotHoldingStatuses = []string{models.InterviewStatusPending, models.InterviewStatusStarted}
+
+type InterviewSlotRepository interface {
+	Create(ctx context.Context, slot *models.InterviewSlot) error
+	GetByID(ctx context.Context, id string) (*models.InterviewSlot, error)
+	// ListByVacancy возвращает окна вакансии по времени начала. Если from задан - только начинающиеся позже.
+	ListByVacancy(ctx context.Context, vacancyID string, from *time.Time) ([]*models.InterviewSlot, error)
+	// Delete удаляет окно, в которое никто не записан. Возвращает false, если запись появилась.
+	Delete(ctx context.Context, id string) (bool, error)
+	// Book записывает ожидающее собеседование в окно, если в нем есть место (иначе ErrSlotFull).
+	// previousSlotID - окно, в которое собеседование записано сейчас; при переносе увеличивается reschedule_count.
+	// Возвращает false, если собеседование уже не ожидает или его запись изменилась параллельно.
+	Book(ctx context.Context, interviewID string, slotID string, previousSlotID *string) (bool, error)
+}
+
+type interviewSlotRepository struct {
+	db *gorm.DB
+}
+
+func NewInterviewSlotRepository(db *gorm.DB) InterviewSlotRepository {
+	return &interviewSlotRepository{db: db}
+}
+
+func (r *interviewSlotRepository) Create(ctx context.Context, slot *models.InterviewSlot) error {
+	if slot == nil {
+		return errors.New("slot cannot be nil")
+	}
+	return r.db.WithContext(ctx).Create(slot).Error
+}
+
+func (r *interviewSlotRepository) GetByID(ctx context.Context, id string) (*models.InterviewSlot, error) {
+	if id == "" {
+		return nil, errors.New("id cannot be empty")
+	}
+
+	var slot models.InterviewSlot
+	if err := r.withBooked(ctx).First(&slot, "interview_slots.id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("interview slot not found: %w", err)
+		}
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (r *interviewSlotRepository) ListByVacancy(ctx context.Context, vacancyID string, from *time.Time) ([]*models.InterviewSlot, error) {
+	if vacancyID == "" {
+		return nil, errors.New("vacancyID cannot be empty")
+	}
+
+	query := r.withBooked(ctx).Where("interview_slots.vacancy_id = ?", vacancyID)
+	if from != nil {
+		query = query.Where("interview_slots.starts_at > ?", *from)
+	}
+
+	var slots []*models.InterviewSlot
+	if err := query.Order("interview_slots.starts_at").Find(&slots).Error; err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+func (r *interviewSlotRepository) Delete(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("NOT EXISTS (SELECT 1 FROM interviews i WHERE i.slot_id = interview_slots.id AND i.status IN ?)", slotHoldingStatuses).
+		Delete(&models.InterviewSlot{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *interviewSlotRepository) Book(ctx context.Context, interviewID string, slotID string, previousSlotID *string) (bool, error) {
+	booked := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокировка окна упорядочивает одновременную запись нескольких кандидатов
+		var slot models.InterviewSlot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&slot, "id = ?", slotID).Error; err != nil {
+			return fmt.Errorf("interview slot not found: %w", err)
+		}
+
+		var taken int64
+		if err := tx.Model(&models.Interview{}).
+			Where("slot_id = ? AND status IN ? AND id <> ?", slotID, slotHoldingStatuses, interviewID).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken >= int64(slot.Capacity) {
+			return ErrSlotFull
+		}
+
+		updates := map[string]interface{}{
+			"slot_id":    slotID,
+			"date_start": slot.StartsAt,
+			"updated_at": slot.EndsAt, // ссылка действует до конца окна
+		}
+		query := tx.Model(&models.Interview{}).Where("id = ? AND status = ?", interviewID, models.InterviewStatusPending)
+		if previousSlotID == nil {
+			query = query.Where("slot_id IS NULL")
+		} else {
+			query = query.Where("slot_id = ?", *previousSlotID)
+			updates["reschedule_count"] = gorm.Expr("reschedule_count + 1")
+		}
+
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		booked = result.RowsAffected == 1
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return booked, nil
+}
+
+// withBooked добавляет к окну число занятых мест
+func (r *interviewSlotRepository) withBooked(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&models.InterviewSlot{}).
+		Select("interview_slots.*, (SELECT COUNT(*) FROM interviews i WHERE i.slot_id = interview_slots.id AND i.status IN ?) AS booked", slotHoldingStatuses)
+}
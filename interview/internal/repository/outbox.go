@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"interview/internal/models"
+	"time"
+)
+
+type OutboxRepository interface {
+	// ClaimPending захватывает готовые к отправке сообщения по порядку создания.
+	// Захват не держит транзакцию на время отправки: сообщения, захваченные раньше lockedBefore,
+	// считаются брошенными упавшей репликой и выдаются снова.
+	ClaimPending(ctx context.Context, limit int, lockedBefore time.Time) ([]*models.OutboxMessage, error)
+	// MarkSent помечает сообщение отправленным, если его захват не перехватила другая реплика
+	MarkSent(ctx context.Context, msg *models.OutboxMessage) (bool, error)
+	// MarkRetry откладывает сообщение до nextAttemptAt и увеличивает счетчик попыток
+	MarkRetry(ctx context.Context, msg *models.OutboxMessage, nextAttemptAt time.Time, lastError string) error
+	// MarkDead завершает сообщение без отправки: попытки исчерпаны
+	MarkDead(ctx context.Context, msg *models.OutboxMessage, lastError string) error
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int, lockedBefore time.Time) ([]*models.OutboxMessage, error) {
+	var messages []*models.OutboxMessage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Postgres хранит микросекунды; иначе MarkSent не найдет строку по locked_at
+		now := time.Now().Truncate(time.Microsecond)
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Where("locked_at IS NULL OR locked_at < ?", lockedBefore).
+			Order("created_at").
+			Limit(limit).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			ids = append(ids, msg.ID)
+			msg.LockedAt = &now
+		}
+
+		return tx.Model(&models.OutboxMessage{}).
+			Where("id IN ?", ids).
+			Update("locked_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// claimed ограничивает обновление строкой, которую захватил именно этот вызов ClaimPending
+func claimed(tx *gorm.DB, msg *models.OutboxMessage) *gorm.DB {
+	return tx.Model(&models.OutboxMessage{}).
+		Where("id = ? AND sent_at IS NULL AND locked_at = ?", msg.ID, msg.LockedAt)
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, msg *models.OutboxMessage) (bool, error) {
+	result := claimed(r.db.WithContext(ctx), msg).
+		Updates(map[string]interface{}{
+			"sent_at":   time.Now(),
+			"locked_at": nil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *outboxRepository) MarkRetry(ctx context.Context, msg *models.OutboxMessage, nextAttemptAt time.Time, lastError string) error {
+	return claimed(r.db.WithContext(ctx), msg).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+			"locked_at":       nil,
+		}).Error
+}
+
+func (r *outboxRepository) MarkDead(ctx context.Context, msg *models.OutboxMessage, lastError string) error {
+	return claimed(r.db.WithContext(ctx), msg).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": lastError,
+			"dead_at":    time.Now(),
+			"locked_at":  nil,
+		}).Error
+}
+
+func (r *outboxRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("sent_at IS NOT NULL AND sent_at < ?", before).
+		Delete(&models.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"interview/internal/models"
+	"time"
+)
+
+type ProcessingJobRepository interface {
+	// Enqueue ставит резюме в очередь обработки. Существующее задание сбрасывается.
+	Enqueue(ctx context.Context, resumeID string) error
+	// ClaimDue захватывает готовые к запуску задания. Несколько реплик не получат одно задание.
+	ClaimDue(ctx context.Context, limit int) ([]*models.ResumeProcessingJob, error)
+	// MarkDone, MarkRetry и MarkFailed записывают результат, только если задание все еще захвачено этим
+	// вызовом ClaimDue (status = running и тот же locked_at). Иначе его уже вернул в очередь RecoverStale.
+	MarkDone(ctx context.Context, job *models.ResumeProcessingJob) (bool, error)
+	MarkRetry(ctx context.Context, job *models.ResumeProcessingJob, nextRunAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, job *models.ResumeProcessingJob, lastError string) (bool, error)
+	// RecoverStale возвращает в очередь задания, захваченные раньше lockedBefore (упавший или зависший воркер).
+	// Задания, исчерпавшие maxAttempts, помечаются failed; возвращаются id их резюме.
+	RecoverStale(ctx context.Context, lockedBefore time.Time, maxAttempts int) (int64, []string, error)
+	GetByResume(ctx context.Context, resumeID string) (*models.ResumeProcessingJob, error)
+	List(ctx context.Context, status string) ([]*models.ResumeProcessingJob, error)
+}
+
+type processingJobRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessingJobRepository(db *gorm.DB) ProcessingJobRepository {
+	return &processingJobRepository{db: db}
+}
+
+func (r *processingJobRepository) Enqueue(ctx context.Context, resumeID string) error {
+	now := time.Now()
+	job := &models.ResumeProcessingJob{
+		ResumeID:  resumeID,
+		Status:    models.ProcessingJobPending,
+		NextRunAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "resume_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"status":      models.ProcessingJobPending,
+				"attempts":    0,
+				"last_error":  "",
+				"next_run_at": now,
+				"locked_at":   nil,
+				"updated_at":  now,
+			}),
+		}).
+		Create(job).Error
+}
+
+func (r *processingJobRepository) ClaimDue(ctx context.Context, limit int) ([]*models.ResumeProcessingJob, error) {
+	var jobs []*models.ResumeProcessingJob
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", models.ProcessingJobPending, time.Now()).
+			Order("next_run_at").
+			Limit(limit).
+			Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		// locked_at сравнивается при записи результата, поэтому точность та же, что у timestamptz
+		now := time.Now().Truncate(time.Microsecond)
+		ids := make([]string, 0, len(jobs))
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+			job.Status = models.ProcessingJobRunning
+			job.Attempts++
+			job.LockedAt = &now
+		}
+
+		return tx.Model(&models.ResumeProcessingJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":     models.ProcessingJobRunning,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"locked_at":  now,
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// claimedJob выбирает задание, только пока оно захвачено тем же вызовом ClaimDue
+func claimedJob(db *gorm.DB, job *models.ResumeProcessingJob) *gorm.DB {
+	return db.Model(&models.ResumeProcessingJob{}).
+		Where("id = ? AND status = ? AND locked_at = ?", job.ID, models.ProcessingJobRunning, job.LockedAt)
+}
+
+func (r *processingJobRepository) MarkDone(ctx context.Context, job *models.ResumeProcessingJob) (bool, error) {
+	result := claimedJob(r.db.WithContext(ctx), job).
+		Updates(map[string]interface{}{
+			"status":     models.ProcessingJobDone,
+			"last_error": "",
+			"locked_at":  nil,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *processingJobRepository) MarkRetry(ctx context.Context, job *models.ResumeProcessingJob, nextRunAt time.Time, lastError string) error {
+	return claimedJob(r.db.WithContext(ctx), job).
+		Updates(map[string]interface{}{
+			"status":      models.ProcessingJobPending,
+			"last_error":  lastError,
+			"next_run_at": nextRunAt,
+			"locked_at":   nil,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+func (r *processingJobRepository) MarkFailed(ctx context.Context, job *models.ResumeProcessingJob, lastError string) (bool, error) {
+	result := claimedJob(r.db.WithContext(ctx), job).
+		Updates(map[string]interface{}{
+			"status":     models.ProcessingJobFailed,
+			"last_error": lastError,
+			"locked_at":  nil,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *processingJobRepository) RecoverStale(ctx context.Context, lockedBefore time.Time, maxAttempts int) (int64, []string, error) {
+	var recovered int64
+	var failed []string
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exhausted []*models.ResumeProcessingJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND locked_at < ? AND attempts >= ?", models.ProcessingJobRunning, lockedBefore, maxAttempts).
+			Find(&exhausted).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if len(exhausted) > 0 {
+			ids := make([]string, 0, len(exhausted))
+			for _, job := range exhausted {
+				ids = append(ids, job.ID)
+				failed = append(failed, job.ResumeID)
+			}
+			err = tx.Model(&models.ResumeProcessingJob{}).
+				Where("id IN ?", ids).
+				Updates(map[string]interface{}{
+					"status":     models.ProcessingJobFailed,
+					"last_error": "processing did not finish within the job timeout",
+					"locked_at":  nil,
+					"updated_at": now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&models.ResumeProcessingJob{}).
+			Where("status = ? AND locked_at < ? AND attempts < ?", models.ProcessingJobRunning, lockedBefore, maxAttempts).
+			Updates(map[string]interface{}{
+				"status":      models.ProcessingJobPending,
+				"next_run_at": now,
+				"locked_at":   nil,
+				"updated_at":  now,
+			})
+		recovered = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return recovered, failed, nil
+}
+
+func (r *processingJobRepository) GetByResume(ctx context.Context, resumeID string) (*models.ResumeProcessingJob, error) {
+	var job models.ResumeProcessingJob
+	err := r.db.WithContext(ctx).Where("resume_id = ?", resumeID).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *processingJobRepository) List(ctx context.Context, status string) ([]*models.ResumeProcessingJob, error) {
+	var jobs []*models.ResumeProcessingJob
+	query := r.db.WithContext(ctx).Order("updated_at DESC").Limit(500)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
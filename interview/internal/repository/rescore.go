@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"interview/internal/models"
+	"time"
+)
+
+type RescoreJobRepository interface {
+	Create(ctx context.Context, job *models.VacancyRescoreJob) error
+	GetByID(ctx context.Context, id string) (*models.VacancyRescoreJob, error)
+	ListByVacancy(ctx context.Context, vacancyID string) ([]*models.VacancyRescoreJob, error)
+	// ClaimNext переводит самое старое ожидающее задание в running. Если заданий нет, возвращает nil.
+	ClaimNext(ctx context.Context) (*models.VacancyRescoreJob, error)
+	Update(ctx context.Context, job *models.VacancyRescoreJob) error
+	// ResetRunning возвращает в очередь задания, прерванные остановкой сервиса
+	ResetRunning(ctx context.Context) (int64, error)
+}
+
+type rescoreJobRepository struct {
+	db *gorm.DB
+}
+
+func NewRescoreJobRepository(db *gorm.DB) RescoreJobRepository {
+	return &rescoreJobRepository{db: db}
+}
+
+func (r *rescoreJobRepository) Create(ctx context.Context, job *models.VacancyRescoreJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *rescoreJobRepository) GetByID(ctx context.Context, id string) (*models.VacancyRescoreJob, error) {
+	var job models.VacancyRescoreJob
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *rescoreJobRepository) ListByVacancy(ctx context.Context, vacancyID string) ([]*models.VacancyRescoreJob, error) {
+	var jobs []*models.VacancyRescoreJob
+	err := r.db.WithContext(ctx).
+		Omit("ranking_before", "ranking_after").
+		Where("vacancy_id = ?", vacancyID).
+		Order("created_at DESC").
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *rescoreJobRepository) ClaimNext(ctx context.Context) (*models.VacancyRescoreJob, error) {
+	var job models.VacancyRescoreJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.RescoreStatusPending).
+			Order("created_at").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = models.RescoreStatusRunning
+		job.StartedAt = &now
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":     job.Status,
+			"started_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *rescoreJobRepository) Update(ctx context.Context, job *models.VacancyRescoreJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+func (r *rescoreJobRepository) ResetRunning(ctx context.Context) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.VacancyRescoreJob{}).
+		Where("status = ?", models.RescoreStatusRunning).
+		Update("status", models.RescoreStatusPending)
+	return res.RowsAffected, res.Error
+}
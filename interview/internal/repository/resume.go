@@ -2,21 +2,56 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"interview/internal/models"
+	"strings"
+	"time"
 )
 
+// ErrStatusChanged - статус резюме изменился между чтением и записью
+var ErrStatusChanged = errors.New("resume status was changed concurrently")
+
+// ErrDuplicateContent - на вакансию уже загружено резюме с тем же файлом
+var ErrDuplicateContent = errors.New("resume with the same file already exists for this vacancy")
+
+// resumeContentIndex - уникальный индекс (vacancy_id, content_hash), как в database/init.sql
+const resumeContentIndex = "idx_resumes_vacancy_content_hash"
+
 type ResumeRepository interface {
-	Create(ctx context.Context, resume *models.Resume) error
+	// Create сохраняет резюме, первую запись в истории статусов и задание обработки одной транзакцией.
+	// Если тот же файл уже загружен на вакансию, возвращает ErrDuplicateContent.
+	Create(ctx context.Context, resume *models.Resume, history *models.ResumeStatusHistory) error
 	GetByID(ctx context.Context, id string) (*models.Resume, error)
 	GetByVacancy(ctx context.Context, id string) ([]*models.Resume, error)
+	// ListByVacancy возвращает страницу облегченных записей, начиная после курсора (nil - с начала)
+	ListByVacancy(ctx context.Context, vacancyID string, filter models.ResumeFilter, after *models.ResumeCursor) ([]*models.ResumeListItem, error)
 	Delete(ctx context.Context, id string) error
-	UpdateStatus(ctx context.Context, id, status string) error
-	UpdateStatusAndResult(ctx context.Context, id, status string, result map[string]interface{}) error
+	// ChangeStatus переводит резюме из change.FromStatus в change.ToStatus и записывает переход в историю.
+	// Если статус уже другой, возвращает ErrStatusChanged. result, если задан, сохраняется в resume_analysis_jsonb.
+	ChangeStatus(ctx context.Context, change *models.ResumeStatusHistory, result map[string]interface{}) error
+	GetStatusHistory(ctx context.Context, id string) ([]*models.ResumeStatusHistory, error)
+	// UpdateResult сохраняет результат собеседования в result_jsonb
 	UpdateResult(ctx context.Context, id string, result map[string]interface{}) error
+	// UpdateAnalysis сохраняет анализ резюме в resume_analysis_jsonb
+	UpdateAnalysis(ctx context.Context, id string, analysis map[string]interface{}) error
 	Update(ctx context.Context, resume *models.Resume) error
 	UpdateText(ctx context.Context, id, text string) error // ← добавлено
-
+	SetMailIfEmpty(ctx context.Context, id, mail string) error
+	UpdateProfile(ctx context.Context, id string, profile *models.CandidateProfile) error
+	// SaveProcessed сохраняет текст и профиль вместе с сообщением в outbox одной транзакцией
+	SaveProcessed(ctx context.Context, id, text string, profile *models.CandidateProfile, outbox *models.OutboxMessage) error
+	UpdateFingerprints(ctx context.Context, id, emailFingerprint, phoneFingerprint string) error
+	MarkDuplicate(ctx context.Context, id, originalID string) error
+	FindByFingerprints(ctx context.Context, excludeID, contentHash, emailFingerprint, phoneFingerprint string) ([]*models.Resume, error)
+	GetDuplicatesByResume(ctx context.Context, id string) ([]models.ResumeDuplicateMatch, error)
+	GetDuplicatesByVacancy(ctx context.Context, vacancyID string) ([]models.ResumeDuplicateMatch, error)
+	// GetScoresByVacancy возвращает оценки резюме вакансии, кроме отклоненных, ошибочных и повторных откликов
+	GetScoresByVacancy(ctx context.Context, vacancyID string) ([]*models.ResumeScores, error)
 }
 
 type resumeRepository struct {
@@ -27,8 +62,28 @@ func NewResumeRepository(db *gorm.DB) ResumeRepository {
 	return &resumeRepository{db: db}
 }
 
-func (r *resumeRepository) Create(ctx context.Context, resume *models.Resume) error {
-	return r.db.WithContext(ctx).Create(resume).Error
+func (r *resumeRepository) Create(ctx context.Context, resume *models.Resume, history *models.ResumeStatusHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(resume).Error; err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == resumeContentIndex {
+				return ErrDuplicateContent
+			}
+			return err
+		}
+		history.ResumeID = resume.ID
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Create(&models.ResumeProcessingJob{
+			ResumeID:  resume.ID,
+			Status:    models.ProcessingJobPending,
+			NextRunAt: now,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}).Error
+	})
 }
 
 func (r *resumeRepository) GetByID(ctx context.Context, id string) (*models.Resume, error) {
@@ -49,23 +104,182 @@ func (r *resumeRepository) GetByVacancy(ctx context.Context, id string) ([]*mode
 	return resumes, nil
 }
 
+// resumeScoreKey - ключ сортировки по баллу: резюме без оценки идут после оцененных
+const resumeScoreKey = "COALESCE(score, -1)"
+
+func (r *resumeRepository) ListByVacancy(ctx context.Context, vacancyID string, filter models.ResumeFilter, after *models.ResumeCursor) ([]*models.ResumeListItem, error) {
+	columns := []string{
+		"id", "vacancy_id", "created_at", "COALESCE(status, '') AS status", "mail", "score", "duplicate_of",
+		"profile_jsonb->>'full_name' AS full_name",
+		"profile_jsonb->'skills' AS skills",
+	}
+	if filter.IncludeAnalysis {
+		columns = append(columns, "resume_analysis_jsonb")
+	}
+
+	db := r.db.WithContext(ctx).
+		Model(&models.Resume{}).
+		Select(strings.Join(columns, ", ")).
+		Where("vacancy_id = ?", vacancyID)
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		db = db.Where("status IN ?", statuses)
+	}
+	if filter.MinScore != nil {
+		db = db.Where("score >= ?", *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		db = db.Where("score <= ?", *filter.MaxScore)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if len(filter.Skills) > 0 {
+		// Использует GIN индекс по profile_jsonb
+		skills, err := json.Marshal(map[string][]string{"skills": filter.Skills})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal skills filter: %w", err)
+		}
+		db = db.Where("profile_jsonb @> ?::jsonb", string(skills))
+	}
+
+	key := "created_at"
+	if filter.Sort == models.ResumeSortScore {
+		key = resumeScoreKey
+	}
+	direction, compare := "ASC", ">"
+	if filter.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if after != nil {
+		var value interface{} = after.CreatedAt
+		if filter.Sort == models.ResumeSortScore {
+			value = after.Score
+		}
+		db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", key, compare), value, after.ID)
+	}
+
+	var items []*models.ResumeListItem
+	err := db.Order(fmt.Sprintf("%s %s, id %s", key, direction, direction)).
+		Limit(filter.Limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func (r *resumeRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&models.Resume{}, "id = ?", id).Error
 }
 
-func (r *resumeRepository) UpdateStatus(ctx context.Context, id, status string) error {
+func (r *resumeRepository) ChangeStatus(ctx context.Context, change *models.ResumeStatusHistory, result map[string]interface{}) error {
+	updates := map[string]interface{}{
+		"status": change.ToStatus,
+	}
+	if result != nil {
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal analysis result: %w", err)
+		}
+		updates["resume_analysis_jsonb"] = datatypes.JSON(resultJSON)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return applyStatusChange(tx, change, updates)
+	})
+}
+
+// applyStatusChange меняет статус резюме, если он все еще change.FromStatus, и пишет переход в историю.
+// Вызывается внутри транзакции.
+func applyStatusChange(tx *gorm.DB, change *models.ResumeStatusHistory, updates map[string]interface{}) error {
+	res := tx.Model(&models.Resume{}).
+		Where("id = ? AND COALESCE(status, '') = ?", change.ResumeID, change.FromStatus).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrStatusChanged
+	}
+	return tx.Create(change).Error
+}
+
+func (r *resumeRepository) GetStatusHistory(ctx context.Context, id string) ([]*models.ResumeStatusHistory, error) {
+	var history []*models.ResumeStatusHistory
+	err := r.db.WithContext(ctx).
+		Where("resume_id = ?", id).
+		Order("created_at, id").
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (r *resumeRepository) Update(ctx context.Context, resume *models.Resume) error {
+	return r.db.WithContext(ctx).Save(resume).Error
+}
+
+func (r *resumeRepository) UpdateResult(ctx context.Context, id string, result map[string]interface{}) error {
+	return r.updateJSON(ctx, id, "result_jsonb", result)
+}
+
+func (r *resumeRepository) UpdateAnalysis(ctx context.Context, id string, analysis map[string]interface{}) error {
+	return r.updateJSON(ctx, id, "resume_analysis_jsonb", analysis)
+}
+
+func (r *resumeRepository) updateJSON(ctx context.Context, id, column string, value map[string]interface{}) error {
+	if value == nil {
+		return nil
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", column, err)
+	}
+
 	return r.db.WithContext(ctx).
 		Model(&models.Resume{}).
 		Where("id = ?", id).
-		Update("status", status).Error
+		Update(column, datatypes.JSON(valueJSON)).Error
 }
 
-func (r *resumeRepository) UpdateStatusAndResult(ctx context.Context, id, status string, result map[string]interface{}) error {
+// SetMailIfEmpty записывает email, если он еще не был найден в тексте резюме
+func (r *resumeRepository) SetMailIfEmpty(ctx context.Context, id, mail string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Resume{}).
+		Where("id = ? AND (mail IS NULL OR mail = '')", id).
+		Update("mail", mail).Error
+}
+
+func (r *resumeRepository) UpdateText(ctx context.Context, id, text string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Resume{}).
+		Where("id = ?", id).
+		Update("text", text).Error
+}
+
+// UpdateProfile сохраняет структурированный профиль кандидата.
+// Email из профиля записывается в колонку mail, если он найден.
+func (r *resumeRepository) UpdateProfile(ctx context.Context, id string, profile *models.CandidateProfile) error {
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal candidate profile: %w", err)
+	}
+
 	updates := map[string]interface{}{
-		"status": status,
+		"profile_jsonb": datatypes.JSON(profileJSON),
 	}
-	if result != nil {
-		updates["result"] = result
+	if profile.Email != "" {
+		updates["mail"] = profile.Email
 	}
 
 	return r.db.WithContext(ctx).
@@ -74,25 +288,126 @@ func (r *resumeRepository) UpdateStatusAndResult(ctx context.Context, id, status
 		Updates(updates).Error
 }
 
-func (r *resumeRepository) Update(ctx context.Context, resume *models.Resume) error {
-	return r.db.WithContext(ctx).Save(resume).Error
+func (r *resumeRepository) SaveProcessed(ctx context.Context, id, text string, profile *models.CandidateProfile, outbox *models.OutboxMessage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := &resumeRepository{db: tx}
+		if err := txRepo.UpdateText(ctx, id, text); err != nil {
+			return err
+		}
+		if err := txRepo.UpdateProfile(ctx, id, profile); err != nil {
+			return err
+		}
+		return tx.Create(outbox).Error
+	})
 }
 
-func (r *resumeRepository) UpdateResult(ctx context.Context, id string, result map[string]interface{}) error {
-	updates := map[string]interface{}{}
-	if result != nil {
-		updates["result"] = result
-	}
-
+func (r *resumeRepository) UpdateFingerprints(ctx context.Context, id, emailFingerprint, phoneFingerprint string) error {
 	return r.db.WithContext(ctx).
 		Model(&models.Resume{}).
 		Where("id = ?", id).
-		Updates(updates).Error
+		Updates(map[string]interface{}{
+			"email_fingerprint": emailFingerprint,
+			"phone_fingerprint": phoneFingerprint,
+		}).Error
 }
 
-func (r *resumeRepository) UpdateText(ctx context.Context, id, text string) error {
+func (r *resumeRepository) MarkDuplicate(ctx context.Context, id, originalID string) error {
 	return r.db.WithContext(ctx).
 		Model(&models.Resume{}).
 		Where("id = ?", id).
-		Update("text", text).Error
+		Update("duplicate_of", originalID).Error
+}
+
+// FindByFingerprints ищет резюме с тем же файлом, email или телефоном, самые ранние первыми.
+// Пустые отпечатки не участвуют в поиске.
+func (r *resumeRepository) FindByFingerprints(ctx context.Context, excludeID, contentHash, emailFingerprint, phoneFingerprint string) ([]*models.Resume, error) {
+	var conditions []string
+	var args []interface{}
+	if contentHash != "" {
+		conditions = append(conditions, "content_hash = ?")
+		args = append(args, contentHash)
+	}
+	if emailFingerprint != "" {
+		conditions = append(conditions, "email_fingerprint = ?")
+		args = append(args, emailFingerprint)
+	}
+	if phoneFingerprint != "" {
+		conditions = append(conditions, "phone_fingerprint = ?")
+		args = append(args, phoneFingerprint)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	db := r.db.WithContext(ctx).Where("("+strings.Join(conditions, " OR ")+")", args...)
+	if excludeID != "" {
+		db = db.Where("id <> ?", excludeID)
+	}
+
+	var resumes []*models.Resume
+	err := db.Omit("text").Order("created_at").Find(&resumes).Error
+	if err != nil {
+		return nil, err
+	}
+	return resumes, nil
+}
+
+// duplicateMatchesQuery соединяет резюме с другими резюме того же кандидата
+const duplicateMatchesQuery = `
+SELECT r.id AS resume_id,
+       d.id AS duplicate_id,
+       d.vacancy_id,
+       v.title AS vacancy_title,
+       COALESCE(d.status, '') AS status,
+       d.created_at,
+       r.content_hash <> '' AND d.content_hash = r.content_hash AS same_content,
+       r.email_fingerprint <> '' AND d.email_fingerprint = r.email_fingerprint AS same_email,
+       r.phone_fingerprint <> '' AND d.phone_fingerprint = r.phone_fingerprint AS same_phone
+FROM resumes r
+JOIN resumes d ON d.id <> r.id AND (
+        (r.content_hash <> '' AND d.content_hash = r.content_hash)
+     OR (r.email_fingerprint <> '' AND d.email_fingerprint = r.email_fingerprint)
+     OR (r.phone_fingerprint <> '' AND d.phone_fingerprint = r.phone_fingerprint))
+JOIN vacancies v ON v.id = d.vacancy_id
+WHERE %s
+ORDER BY r.created_at, d.created_at`
+
+func (r *resumeRepository) GetDuplicatesByResume(ctx context.Context, id string) ([]models.ResumeDuplicateMatch, error) {
+	var matches []models.ResumeDuplicateMatch
+	err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf(duplicateMatchesQuery, "r.id = ?"), id).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+func (r *resumeRepository) GetDuplicatesByVacancy(ctx context.Context, vacancyID string) ([]models.ResumeDuplicateMatch, error) {
+	var matches []models.ResumeDuplicateMatch
+	err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf(duplicateMatchesQuery, "r.vacancy_id = ?"), vacancyID).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+func (r *resumeRepository) GetScoresByVacancy(ctx context.Context, vacancyID string) ([]*models.ResumeScores, error) {
+	var scores []*models.ResumeScores
+	err := r.db.WithContext(ctx).
+		Model(&models.Resume{}).
+		Select("id, COALESCE(status, '') AS status, mail, created_at, profile_jsonb->>'full_name' AS full_name, resume_analysis_jsonb, result_jsonb").
+		Where("vacancy_id = ?", vacancyID).
+		// Повторный отклик кандидата на ту же вакансию не ранжируется отдельно
+		Where("NOT EXISTS (SELECT 1 FROM resumes o WHERE o.id = resumes.duplicate_of AND o.vacancy_id = resumes.vacancy_id)").
+		Where("COALESCE(status, '') NOT IN ?", []string{string(models.ResumeStatusRejected), string(models.ResumeStatusError)}).
+		Where("(resume_analysis_jsonb IS NOT NULL OR result_jsonb IS NOT NULL)").
+		Order("created_at").
+		Scan(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	return scores, nil
 }
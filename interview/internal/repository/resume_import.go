@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"interview/internal/models"
+	"time"
+)
+
+type ResumeImportRepository interface {
+	Create(ctx context.Context, job *models.ResumeImportJob) error
+	GetByID(ctx context.Context, id string) (*models.ResumeImportJob, error)
+	UpdateJob(ctx context.Context, job *models.ResumeImportJob) error
+	UpdateItem(ctx context.Context, item *models.ResumeImportItem) error
+	// FailStale завершает незаконченные задания без heartbeat с heartbeatBefore:
+	// необработанные файлы помечаются failed с ошибкой reason
+	FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error)
+}
+
+type resumeImportRepository struct {
+	db *gorm.DB
+}
+
+func NewResumeImportRepository(db *gorm.DB) ResumeImportRepository {
+	return &resumeImportRepository{db: db}
+}
+
+// Create сохраняет задание вместе со всеми файлами
+func (r *resumeImportRepository) Create(ctx context.Context, job *models.ResumeImportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *resumeImportRepository) GetByID(ctx context.Context, id string) (*models.ResumeImportJob, error) {
+	var job models.ResumeImportJob
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Where("id = ?", id).
+		First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *resumeImportRepository) UpdateJob(ctx context.Context, job *models.ResumeImportJob) error {
+	return r.db.WithContext(ctx).Omit("Items").Save(job).Error
+}
+
+func (r *resumeImportRepository) UpdateItem(ctx context.Context, item *models.ResumeImportItem) error {
+	return r.db.WithContext(ctx).Save(item).Error
+}
+
+func (r *resumeImportRepository) FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	var failed int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		err := tx.Model(&models.ResumeImportJob{}).
+			Where("status IN ?", []string{models.ImportStatusPending, models.ImportStatusProcessing}).
+			Where("COALESCE(heartbeat_at, created_at) < ?", heartbeatBefore).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		now := time.Now()
+		err = tx.Model(&models.ResumeImportItem{}).
+			Where("job_id IN ? AND status = ?", ids, models.ImportItemPending).
+			Updates(map[string]interface{}{
+				"status":     models.ImportItemFailed,
+				"error":      reason,
+				"updated_at": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.ResumeImportJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status": models.ImportStatusFailed,
+				"failed": gorm.Expr(`(SELECT COUNT(*) FROM resume_import_items i
+					WHERE i.job_id = resume_import_jobs.id AND i.status = ?)`, models.ImportItemFailed),
+				"finished_at": now,
+			})
+		failed = result.RowsAffected
+		return result.Error
+	})
+	return failed, err
+}
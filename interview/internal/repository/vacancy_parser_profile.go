@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"interview/internal/models"
+)
+
+type VacancyParserProfileRepository interface {
+	Create(ctx context.Context, profile *models.VacancyParserProfile) error
+	GetByID(ctx context.Context, id string) (*models.VacancyParserProfile, error)
+	GetAll(ctx context.Context) ([]*models.VacancyParserProfile, error)
+	GetActive(ctx context.Context) ([]*models.VacancyParserProfile, error)
+	Update(ctx context.Context, profile *models.VacancyParserProfile) error
+	Delete(ctx context.Context, id string) error
+}
+
+type vacancyParserProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewVacancyParserProfileRepository(db *gorm.DB) VacancyParserProfileRepository {
+	return &vacancyParserProfileRepository{db: db}
+}
+
+func (r *vacancyParserProfileRepository) Create(ctx context.Context, profile *models.VacancyParserProfile) error {
+	return r.db.WithContext(ctx).Create(profile).Error
+}
+
+func (r *vacancyParserProfileRepository) GetByID(ctx context.Context, id string) (*models.VacancyParserProfile, error) {
+	var profile models.VacancyParserProfile
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *vacancyParserProfileRepository) GetAll(ctx context.Context) ([]*models.VacancyParserProfile, error) {
+	var profiles []*models.VacancyParserProfile
+	err := r.db.WithContext(ctx).Order("priority DESC, created_at").Find(&profiles).Error
+	return profiles, err
+}
+
+// GetActive возвращает включенные профили в порядке приоритета
+func (r *vacancyParserProfileRepository) GetActive(ctx context.Context) ([]*models.VacancyParserProfile, error) {
+	var profiles []*models.VacancyParserProfile
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("priority DESC, created_at").
+		Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *vacancyParserProfileRepository) Update(ctx context.Context, profile *models.VacancyParserProfile) error {
+	return r.db.WithContext(ctx).Save(profile).Error
+}
+
+func (r *vacancyParserProfileRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.VacancyParserProfile{}, "id = ?", id).Error
+}
@@ -4,24 +4,45 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"sync"
-	"time"
 
 	"github.com/streadway/amqp"
+	"interview/internal/broker"
 	"interview/internal/models"
 )
 
+// ChunkHandler получает части ответа AI по мере генерации
+type ChunkHandler func(chunk *AIResponse)
+
 type AIService interface {
 	GenerateResponse(ctx context.Context, userInput string, conversation []models.ChatMessage, interviewID string) (*AIResponse, error)
+	// GenerateResponseStream просит AI присылать ответ частями. Итоговый ответ возвращается целиком,
+	// как и в GenerateResponse; RequestID в нем связывает его с переданными в onChunk частями.
+	GenerateResponseStream(ctx context.Context, userInput string, conversation []models.ChatMessage, interviewID string, onChunk ChunkHandler) (*AIResponse, error)
 	GenerateWelcomeResponse(ctx context.Context, vacancyJSON, resumeText, interviewID string) (*AIResponse, error)
+	// GenerateFinalResult просит AI подвести итог по имеющейся переписке без новых вопросов.
+	// reason - причина завершения (FinishReason*).
+	GenerateFinalResult(ctx context.Context, conversation []models.ChatMessage, interviewID, reason string) (*AIResponse, error)
 }
 
+// AIActionFinalResult - запрос итогового результата вместо следующего вопроса
+const AIActionFinalResult = "final_result"
+
+// aiChunkBuffer - сколько частей ответа ждут передачи в onChunk. Если обработчик не успевает,
+// лишние части отбрасываются: итоговый ответ все равно приходит целиком.
+const aiChunkBuffer = 256
+
+// AIResponse - ответ AI сервиса. При потоковой генерации сервис сначала присылает в ту же
+// очередь ответы с partial=true и очередной частью текста в delta, затем итоговый ответ.
 type AIResponse struct {
 	RequestID   string `json:"request_id,omitempty"`
 	Response    string `json:"response"`
 	MessageType string `json:"message_type"`
 	Error       string `json:"error,omitempty"`
 	Result      string `json:"result,omitempty"`
+	Partial     bool   `json:"partial,omitempty"`
+	Delta       string `json:"delta,omitempty"`
 }
 
 type AIRequest struct {
@@ -32,6 +53,8 @@ type AIRequest struct {
 	VacancyJSON  string               `json:"vacancy_json,omitempty"`
 	ResumeText   string               `json:"resume_text,omitempty"`
 	Action       string               `json:"action,omitempty"`
+	FinishReason string               `json:"finish_reason,omitempty"` // почему запрошен итог (action=final_result)
+	Stream       bool                 `json:"stream,omitempty"`        // клиент принимает ответ частями
 }
 
 type AIServiceImpl struct {
@@ -63,6 +86,23 @@ func (a *AIServiceImpl) GenerateResponse(
 	return brokerResp, nil
 }
 
+func (a *AIServiceImpl) GenerateResponseStream(
+	ctx context.Context,
+	userInput string,
+	conversation []models.ChatMessage,
+	interviewID string,
+	onChunk ChunkHandler,
+) (*AIResponse, error) {
+	req := &AIRequest{
+		UserMessage:  userInput,
+		Conversation: conversation,
+		InterviewID:  interviewID,
+		Stream:       true,
+	}
+
+	return a.rabbitmq.SendAIRequestStream(ctx, req, onChunk)
+}
+
 func (a *AIServiceImpl) GenerateWelcomeResponse(
 	ctx context.Context,
 	vacancyJSON, resumeText, interviewID string,
@@ -84,138 +124,294 @@ func (a *AIServiceImpl) GenerateWelcomeResponse(
 	return brokerResp, nil
 }
 
+func (a *AIServiceImpl) GenerateFinalResult(
+	ctx context.Context,
+	conversation []models.ChatMessage,
+	interviewID, reason string,
+) (*AIResponse, error) {
+	req := &AIRequest{
+		Conversation: conversation,
+		InterviewID:  interviewID,
+		Action:       AIActionFinalResult,
+		FinishReason: reason,
+	}
+
+	brokerResp, err := a.rabbitmq.SendAIRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	brokerResp.RequestID = ""
+	return brokerResp, nil
+}
+
+// RabbitMQService - RPC клиент AI сервиса поверх общего ConnectionManager.
+// Каждый экземпляр сервиса читает ответы из своей эксклюзивной очереди с именем от брокера,
+// поэтому ответ AI приходит в тот процесс, который ждет его в pendingRequests.
+// После переподключения очередь объявляется заново под новым именем; ответы на запросы,
+// отправленные до обрыва, теряются, и такие запросы завершаются по таймауту.
 type RabbitMQService struct {
-	conn            *amqp.Connection
-	channel         *amqp.Channel
+	conn            *broker.ConnectionManager
 	requestQueue    string
-	responseQueue   string
-	pendingRequests map[string]chan *AIResponse
+	pendingRequests map[string]*pendingRequest
 	mu              sync.RWMutex
+
+	replyMu    sync.Mutex
+	replyQueue string
+	replyReady chan struct{} // закрыт, пока очередь ответов объявлена и читается
+
+	publishMu sync.Mutex
+	channel   *amqp.Channel
 }
 
-func NewRabbitMQService(amqpURL string) (*RabbitMQService, error) {
-	conn, err := amqp.Dial(amqpURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+// pendingRequest - запрос, ожидающий ответа. Части ответа копятся в chunks
+// и передаются в onChunk отдельной горутиной, чтобы не задерживать чтение очереди ответов.
+type pendingRequest struct {
+	response chan *AIResponse
+	chunks   chan *AIResponse
+}
+
+func NewRabbitMQService(conn *broker.ConnectionManager) (*RabbitMQService, error) {
+	service := &RabbitMQService{
+		conn:            conn,
+		requestQueue:    "ai_requests",
+		pendingRequests: make(map[string]*pendingRequest),
+		replyReady:      make(chan struct{}),
 	}
-	ch, err := conn.Channel()
+
+	err := conn.DeclareTopology(func(ch *amqp.Channel) error {
+		if _, err := ch.QueueDeclare(service.requestQueue, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare request queue: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+		return nil, err
 	}
 
-	requestQueue := "ai_requests"
-	responseQueue := "ai_responses"
+	conn.Consume("ai_replies", service.consumeReplies, service.handleResponse)
+
+	return service, nil
+}
 
-	_, err = ch.QueueDeclare(requestQueue, true, false, false, false, nil)
+// consumeReplies объявляет эксклюзивную очередь ответов этого экземпляра и начинает ее читать.
+// Очередь удаляется брокером вместе с соединением или последним потребителем.
+func (r *RabbitMQService) consumeReplies(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+	r.resetReplyQueue()
+
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare request queue: %w", err)
+		return nil, fmt.Errorf("failed to declare reply queue: %w", err)
 	}
-	_, err = ch.QueueDeclare(responseQueue, true, false, false, false, nil)
+	deliveries, err := ch.Consume(q.Name, "", true, true, false, false, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare response queue: %w", err)
+		return nil, err
 	}
 
-	service := &RabbitMQService{
-		conn:            conn,
-		channel:         ch,
-		requestQueue:    requestQueue,
-		responseQueue:   responseQueue,
-		pendingRequests: make(map[string]chan *AIResponse),
+	r.replyMu.Lock()
+	r.replyQueue = q.Name
+	close(r.replyReady)
+	r.replyMu.Unlock()
+
+	// Как только канал закрылся, имя очереди больше нельзя отдавать в ReplyTo
+	out := make(chan amqp.Delivery)
+	go func() {
+		defer close(out)
+		for delivery := range deliveries {
+			out <- delivery
+		}
+		r.resetReplyQueue()
+	}()
+	return out, nil
+}
+
+func (r *RabbitMQService) resetReplyQueue() {
+	r.replyMu.Lock()
+	defer r.replyMu.Unlock()
+	if r.replyQueue != "" {
+		r.replyQueue = ""
+		r.replyReady = make(chan struct{})
 	}
+}
 
-	go service.startResponseListener()
-	return service, nil
+// waitReplyQueue возвращает имя очереди ответов, дожидаясь ее объявления
+func (r *RabbitMQService) waitReplyQueue(ctx context.Context) (string, error) {
+	for {
+		r.replyMu.Lock()
+		name, ready := r.replyQueue, r.replyReady
+		r.replyMu.Unlock()
+		if name != "" {
+			return name, nil
+		}
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return "", fmt.Errorf("AI reply queue is not ready: %w", ctx.Err())
+		}
+	}
 }
 
 func (r *RabbitMQService) SendAIRequest(ctx context.Context, request *AIRequest) (*AIResponse, error) {
+	return r.SendAIRequestStream(ctx, request, nil)
+}
+
+// SendAIRequestStream отправляет запрос и ждет итогового ответа, передавая части ответа в onChunk
+func (r *RabbitMQService) SendAIRequestStream(ctx context.Context, request *AIRequest, onChunk ChunkHandler) (*AIResponse, error) {
 	request.RequestID = generateRequestID()
 
+	pending := &pendingRequest{response: make(chan *AIResponse, 1)}
+	if onChunk != nil {
+		pending.chunks = make(chan *AIResponse, aiChunkBuffer)
+		stop := deliverChunks(pending.chunks, onChunk)
+		defer stop()
+	}
+
 	r.mu.Lock()
-	respChan := make(chan *AIResponse, 1)
-	r.pendingRequests[request.RequestID] = respChan
+	r.pendingRequests[request.RequestID] = pending
 	r.mu.Unlock()
 
-	body, err := json.Marshal(request)
-	if err != nil {
+	defer func() {
 		r.mu.Lock()
 		delete(r.pendingRequests, request.RequestID)
 		r.mu.Unlock()
+	}()
+
+	body, err := json.Marshal(request)
+	if err != nil {
 		return nil, err
 	}
 
-	err = r.channel.Publish(
-		"", r.requestQueue, false, false,
-		amqp.Publishing{
-			ContentType:   "application/json",
-			Body:          body,
-			ReplyTo:       r.responseQueue,
-			CorrelationId: request.RequestID,
-		},
-	)
+	replyTo, err := r.waitReplyQueue(ctx)
 	if err != nil {
-		r.mu.Lock()
-		delete(r.pendingRequests, request.RequestID)
-		r.mu.Unlock()
+		return nil, err
+	}
+
+	if err := r.publish(ctx, request.RequestID, replyTo, body); err != nil {
 		return nil, err
 	}
 
 	select {
-	case resp := <-respChan:
-		r.mu.Lock()
-		delete(r.pendingRequests, request.RequestID)
-		r.mu.Unlock()
+	case resp := <-pending.response:
+		resp.RequestID = request.RequestID
 		return resp, nil
 	case <-ctx.Done():
-		r.mu.Lock()
-		delete(r.pendingRequests, request.RequestID)
-		r.mu.Unlock()
 		return nil, fmt.Errorf("timeout or canceled")
 	}
 }
 
-func (r *RabbitMQService) startResponseListener() {
-	msgs, err := r.channel.Consume(
-		r.responseQueue,
-		"",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+// deliverChunks передает части ответа в onChunk, пока не вызвана stop. stop дожидается,
+// пока будут переданы уже полученные части, поэтому они не обгонят итоговый ответ.
+func deliverChunks(chunks <-chan *AIResponse, onChunk ChunkHandler) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case chunk := <-chunks:
+				onChunk(chunk)
+			case <-done:
+				for {
+					select {
+					case chunk := <-chunks:
+						onChunk(chunk)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// publish отправляет запрос. Если канал закрылся вместе с прошлым соединением,
+// открывается новый и отправка повторяется один раз.
+func (r *RabbitMQService) publish(ctx context.Context, requestID, replyTo string, body []byte) error {
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if r.channel == nil {
+			ch, chErr := r.conn.Channel(ctx)
+			if chErr != nil {
+				return chErr
+			}
+			r.channel = ch
+		}
+
+		err = r.channel.Publish(
+			"", r.requestQueue, false, false,
+			amqp.Publishing{
+				ContentType:   "application/json",
+				Body:          body,
+				ReplyTo:       replyTo,
+				CorrelationId: requestID,
+			},
+		)
+		if err == nil {
+			return nil
+		}
+		r.channel.Close()
+		r.channel = nil
+	}
+	return fmt.Errorf("failed to publish AI request: %w", err)
+}
+
+func (r *RabbitMQService) handleResponse(msg amqp.Delivery) {
+	var resp AIResponse
+	err := json.Unmarshal(msg.Body, &resp)
 	if err != nil {
-		fmt.Printf("Failed to register response consumer: %v\n", err)
+		fmt.Printf("Failed to unmarshal AI response: %v\n", err)
 		return
 	}
 
-	for msg := range msgs {
-		var resp AIResponse
-		err := json.Unmarshal(msg.Body, &resp)
-		if err != nil {
-			fmt.Printf("Failed to unmarshal AI response: %v\n", err)
-			continue
-		}
+	requestID := msg.CorrelationId
+	if requestID == "" {
+		requestID = resp.RequestID
+	}
 
-		r.mu.RLock()
-		ch, exists := r.pendingRequests[resp.RequestID]
-		r.mu.RUnlock()
+	r.mu.RLock()
+	pending, exists := r.pendingRequests[requestID]
+	r.mu.RUnlock()
+	if !exists {
+		return
+	}
 
-		if exists {
-			ch <- &resp
+	// Части приходят по порядку: ответы читает один потребитель
+	if resp.Partial {
+		if pending.chunks == nil {
+			return
+		}
+		resp.RequestID = requestID
+		select {
+		case pending.chunks <- &resp:
+		default:
+			log.Printf("⚠️ AI response chunk for %s dropped: chunk handler is too slow", requestID)
 		}
+		return
+	}
+
+	select {
+	case pending.response <- &resp:
+	default:
 	}
 }
 
 func (r *RabbitMQService) Close() error {
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
 	if r.channel != nil {
-		r.channel.Close()
-	}
-	if r.conn != nil {
-		return r.conn.Close()
+		return r.channel.Close()
 	}
 	return nil
 }
 
 func generateRequestID() string {
-	return fmt.Sprintf("req_%d", time.Now().UnixNano())
+	return "req_" + generateToken()
 }
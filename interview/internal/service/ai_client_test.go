@@ -0,0 +1,59 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chunkDelivery(t *testing.T, requestID, delta string) amqp.Delivery {
+	body, err := json.Marshal(AIResponse{Partial: true, Delta: delta})
+	require.NoError(t, err)
+	return amqp.Delivery{CorrelationId: requestID, Body: body}
+}
+
+func TestHandleResponse_SlowChunkHandler(t *testing.T) {
+	release := make(chan struct{})
+	var deltas []string
+	onChunk := func(chunk *AIResponse) {
+		<-release
+		deltas = append(deltas, chunk.Delta)
+	}
+
+	pending := &pendingRequest{response: make(chan *AIResponse, 1), chunks: make(chan *AIResponse, aiChunkBuffer)}
+	r := &RabbitMQService{pendingRequests: map[string]*pendingRequest{"req": pending}}
+	stop := deliverChunks(pending.chunks, onChunk)
+
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		for _, delta := range []string{"Какой ", "у вас ", "опыт?"} {
+			r.handleResponse(chunkDelivery(t, "req", delta))
+		}
+	}()
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("чтение ответов ждет обработчик частей")
+	}
+
+	close(release)
+	stop()
+	assert.Equal(t, []string{"Какой ", "у вас ", "опыт?"}, deltas, "полученные части переданы по порядку до остановки")
+}
+
+func TestHandleResponse_DropsChunksWhenBufferIsFull(t *testing.T) {
+	pending := &pendingRequest{response: make(chan *AIResponse, 1), chunks: make(chan *AIResponse, 1)}
+	r := &RabbitMQService{pendingRequests: map[string]*pendingRequest{"req": pending}}
+
+	r.handleResponse(chunkDelivery(t, "req", "первая"))
+	r.handleResponse(chunkDelivery(t, "req", "лишняя"))
+
+	require.Len(t, pending.chunks, 1)
+	assert.Equal(t, "первая", (<-pending.chunks).Delta)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// METHOD календарного приглашения (RFC 5546)
+const (
+	CalendarMethodRequest = "REQUEST"
+	CalendarMethodCancel  = "CANCEL"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// CalendarEvent - событие календаря. Обновление или отмена события отправляются с тем же UID
+// и большим Sequence, тогда календарь кандидата заменит прежнее событие.
+type CalendarEvent struct {
+	UID         string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+	Organizer   string // почта организатора
+	Attendee    string // почта кандидата
+	Cancelled   bool
+}
+
+func (e CalendarEvent) Method() string {
+	if e.Cancelled {
+		return CalendarMethodCancel
+	}
+	return CalendarMethodRequest
+}
+
+// ICS собирает файл iCalendar (RFC 5545) с одним событием
+func (e CalendarEvent) ICS() []byte {
+	status := "CONFIRMED"
+	if e.Cancelled {
+		status = "CANCELLED"
+	}
+
+	var b bytes.Buffer
+	line := func(s string) {
+		b.WriteString(foldICSLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//AI-HR//Interview Service//RU")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:" + e.Method())
+	line("BEGIN:VEVENT")
+	line("UID:" + e.UID)
+	line("DTSTAMP:" + time.Now().UTC().Format(icsTimeFormat))
+	line("SEQUENCE:" + strconv.Itoa(e.Sequence))
+	line("DTSTART:" + e.Start.UTC().Format(icsTimeFormat))
+	line("DTEND:" + e.End.UTC().Format(icsTimeFormat))
+	line("SUMMARY:" + escapeICSText(e.Summary))
+	if e.Description != "" {
+		line("DESCRIPTION:" + escapeICSText(e.Description))
+	}
+	if e.URL != "" {
+		line("URL:" + e.URL)
+	}
+	if e.Organizer != "" {
+		line("ORGANIZER:mailto:" + e.Organizer)
+	}
+	if e.Attendee != "" {
+		line("ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;RSVP=FALSE:mailto:" + e.Attendee)
+	}
+	line("STATUS:" + status)
+	line("END:VEVENT")
+	line("END:VCALENDAR")
+	return b.Bytes()
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeICSText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// foldICSLine переносит строки длиннее 75 байт (RFC 5545, 3.1), не разрывая символы UTF-8
+func foldICSLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := utf8.RuneLen(r)
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1 // пробел в начале продолжения тоже считается
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
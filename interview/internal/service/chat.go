@@ -3,111 +3,273 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"interview/internal/models"
+	"interview/internal/repository"
+	"log"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+// chatTurnTTL - на сколько захватывается ход чата: с запасом больше таймаута ответа AI
+const chatTurnTTL = 2 * time.Minute
+
+// ChatReplyTimeout - сколько ждать ответа AI на сообщение кандидата
+const ChatReplyTimeout = 45 * time.Second
+
+var (
+	ErrChatSessionNotFound = errors.New("session not found")
+	ErrChatBusy            = errors.New("previous message is still being processed")
+	ErrChatSessionClosed   = errors.New("session is closed")
 )
 
+// limitReachedMessage завершает собеседование, если AI не смог подвести итог
+const limitReachedMessage = "Собеседование завершено. Спасибо за ответы! Результаты будут переданы HR."
+
 type ChatService interface {
 	CreateSession(interviewID string, resumeID, vacancyID string) (*models.ChatSession, error)
 	GetSession(interviewID string) (*models.ChatSession, error)
 	AddCandidateMessage(interviewID string, msg *models.ChatMessage) (*models.ChatMessage, error) // Новый метод
 	AddAIMessage(interviewID string, msg *models.ChatMessage) error                               // Новый метод
+	// SubmitCandidateMessage сохраняет сообщение кандидата и получает ответ AI в фоне.
+	// Ответ и его части приходят подписчикам событий чата.
+	SubmitCandidateMessage(interviewID string, msg *models.ChatMessage) error
 	GetMessages(interviewID string) ([]models.ChatMessage, error)
 	GetStatus(interviewID string) (*models.InterviewStatus, error)
 	CloseSession(interviewID string) error
+	// FinishByLimit завершает собеседование, исчерпавшее ограничение вакансии: AI подводит итог
+	// по имеющейся переписке. Возвращает ErrChatBusy, пока AI отвечает кандидату.
+	FinishByLimit(interviewID, reason string) error
+	// Wait ждет ответов AI, начатых SubmitCandidateMessage в фоне
+	Wait()
 }
 
+// ChatServiceImpl не держит состояния в памяти: сессия собирается из interview_messages
+// и interviews при каждом обращении, поэтому сервис может работать в нескольких репликах.
+// Ход чата (сообщение кандидата и ответ AI) сериализуется арендой в строке interviews.
+// Ограничения собеседования берутся из вакансии, незаданные - из policy.
 type ChatServiceImpl struct {
-	mu         sync.RWMutex
-	sessions   map[string]*models.ChatSession
 	aiSvc      AIService
 	resumeSvc  ResumeService
 	vacancySvc VacancyService
+	messages   repository.ChatMessageRepository
+	interviews repository.InterviewRepository
+	events     ChatEventBus
+	policy     InterviewPolicy
+	replies    sync.WaitGroup // фоновые ответы SubmitCandidateMessage
 }
 
-func NewChatService(aiSvc AIService, resumeSvc ResumeService, vacancySvc VacancyService) ChatService {
+func NewChatService(
+	aiSvc AIService,
+	resumeSvc ResumeService,
+	vacancySvc VacancyService,
+	messages repository.ChatMessageRepository,
+	interviews repository.InterviewRepository,
+	events ChatEventBus,
+	policy InterviewPolicy,
+) ChatService {
 	return &ChatServiceImpl{
-		sessions:   make(map[string]*models.ChatSession),
 		aiSvc:      aiSvc,
 		resumeSvc:  resumeSvc,
 		vacancySvc: vacancySvc,
+		messages:   messages,
+		interviews: interviews,
+		events:     events,
+		policy:     policy,
 	}
 }
 
+func (s *ChatServiceImpl) Wait() {
+	s.replies.Wait()
+}
+
 func (s *ChatServiceImpl) AddCandidateMessage(interviewID string, msg *models.ChatMessage) (*models.ChatMessage, error) {
-	err := s.addMessage(interviewID, msg)
+	release, err := s.claimTurn(interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = s.addMessage(interviewID, msg, "")
 	if err != nil {
 		return nil, err
 	}
 
-	history, err := s.GetMessages(interviewID)
+	return s.reply(interviewID, msg)
+}
+
+func (s *ChatServiceImpl) SubmitCandidateMessage(interviewID string, msg *models.ChatMessage) error {
+	release, err := s.claimTurn(interviewID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addMessage(interviewID, msg, ""); err != nil {
+		release()
+		return err
+	}
+
+	s.replies.Add(1)
+	go func() {
+		defer s.replies.Done()
+		defer release()
+		if _, err := s.reply(interviewID, msg); err != nil {
+			log.Printf("❌ Failed to reply to candidate in interview %s: %v", interviewID, err)
+			s.emit(models.ChatEvent{Type: models.ChatEventError, InterviewID: interviewID, Error: "failed to get AI response"})
+		}
+	}()
+	return nil
+}
+
+// reply получает ответ AI на сообщение кандидата. Ход чата должен быть захвачен.
+// Если собеседование исчерпало ограничения, вместо следующего вопроса AI подводит итог.
+func (s *ChatServiceImpl) reply(interviewID string, msg *models.ChatMessage) (*models.ChatMessage, error) {
+	s.emit(models.ChatEvent{Type: models.ChatEventTyping, InterviewID: interviewID})
+
+	session, err := s.GetSession(interviewID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chat history: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), ChatReplyTimeout)
 	defer cancel()
 
-	aiResponse, err := s.aiSvc.GenerateResponse(ctx, msg.Content, history, interviewID)
+	if reason := s.policyFor(ctx, session.VacancyID).Exceeded(session, time.Now()); reason != "" {
+		return s.finalize(ctx, session, reason)
+	}
+
+	// Части ответа сразу уходят кандидату, в БД сохраняется только итоговое сообщение
+	aiResponse, err := s.aiSvc.GenerateResponseStream(ctx, msg.Content, session.Messages, interviewID, func(chunk *AIResponse) {
+		s.emit(models.ChatEvent{
+			Type:        models.ChatEventChunk,
+			InterviewID: interviewID,
+			RequestID:   chunk.RequestID,
+			Delta:       chunk.Delta,
+		})
+	})
 	if err != nil {
 		errorMsg := &models.ChatMessage{
 			Type:    models.MessageTypeError,
 			Content: "Извините, произошла техническая ошибка. Пожалуйста, повторите ваш вопрос.",
 			Sender:  "ai",
 		}
-		s.addMessage(interviewID, errorMsg)
+		s.addMessage(interviewID, errorMsg, "")
 		return errorMsg, fmt.Errorf("AI service error: %w", err)
 	}
 
 	if aiResponse.MessageType == "result" {
-		aiMsg := &models.ChatMessage{
-			Type:    models.MessageTypeResult,
-			Content: aiResponse.Response,
-			Sender:  "ai",
-		}
-
-		err = s.addMessage(interviewID, aiMsg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to save result message: %w", err)
-		}
+		return s.finish(ctx, interviewID, aiResponse)
+	}
 
-		err = s.saveResultToResume(interviewID, aiResponse.Result)
-		if err != nil {
-			fmt.Printf("Failed to save result to resume: %v\n", err) // Логируем, но не останавливаем процесс
-		}
+	aiMsg := &models.ChatMessage{
+		Type:    models.MessageTypeQuestion,
+		Content: aiResponse.Response,
+		Sender:  "ai",
+	}
 
-		session, err := s.GetSession(interviewID)
-		if err != nil {
-			return nil, err
-		}
+	err = s.addMessage(interviewID, aiMsg, aiResponse.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save AI message: %w", err)
+	}
 
-		err = s.resumeSvc.UpdateStatus(ctx, session.ResumeID, "Прошел собеседование")
-		if err != nil {
-			return nil, err
-		}
+	return aiMsg, nil
+}
 
-		// Закрываем сессию
-		s.CloseSession(interviewID)
+// finalize просит AI подвести итог собеседования, исчерпавшего ограничения.
+// Ответ AI считается итоговым в любом случае, а при ошибке AI собеседование завершается без оценки.
+func (s *ChatServiceImpl) finalize(ctx context.Context, session *models.ChatSession, reason string) (*models.ChatMessage, error) {
+	log.Printf("⏱️ Interview %s reached its limit (%s), requesting final result", session.InterviewID, reason)
 
-		return aiMsg, nil
+	aiResponse, err := s.aiSvc.GenerateFinalResult(ctx, session.Messages, session.InterviewID, reason)
+	if err != nil {
+		log.Printf("⚠️ AI failed to produce final result for interview %s: %v", session.InterviewID, err)
+		aiResponse = &AIResponse{Response: limitReachedMessage}
+	}
+	if aiResponse.Response == "" {
+		aiResponse.Response = limitReachedMessage
 	}
+	return s.finish(ctx, session.InterviewID, aiResponse)
+}
 
+// finish сохраняет итоговое сообщение AI, результат в резюме и закрывает сессию
+func (s *ChatServiceImpl) finish(ctx context.Context, interviewID string, aiResponse *AIResponse) (*models.ChatMessage, error) {
 	aiMsg := &models.ChatMessage{
-		Type:    models.MessageTypeQuestion,
+		Type:    models.MessageTypeResult,
 		Content: aiResponse.Response,
 		Sender:  "ai",
 	}
 
-	err = s.addMessage(interviewID, aiMsg)
+	err := s.addMessage(interviewID, aiMsg, aiResponse.RequestID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save AI message: %w", err)
+		return nil, fmt.Errorf("failed to save result message: %w", err)
+	}
+
+	if aiResponse.Result != "" {
+		err = s.saveResultToResume(interviewID, aiResponse.Result)
+		if err != nil {
+			fmt.Printf("Failed to save result to resume: %v\n", err) // Логируем, но не останавливаем процесс
+		}
+	}
+
+	session, err := s.GetSession(interviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Итоговое сообщение уже сохранено, поэтому сессия закрывается, даже если статус резюме не обновился
+	err = s.changeResumeStatus(ctx, session.ResumeID, StatusChange{
+		To:     models.ResumeStatusInterviewed,
+		Actor:  models.ResumeActorInterview,
+		Reason: "interview " + interviewID + " finished",
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to update resume %s after interview %s: %v", session.ResumeID, interviewID, err)
+	}
+
+	// Закрываем сессию и сохраняем переписку
+	if err := s.CloseSession(interviewID); err != nil {
+		log.Printf("⚠️ Failed to close chat session %s: %v", interviewID, err)
 	}
 
 	return aiMsg, nil
 }
 
+func (s *ChatServiceImpl) FinishByLimit(interviewID, reason string) error {
+	release, err := s.claimTurn(interviewID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	session, err := s.GetSession(interviewID)
+	if err != nil {
+		return err
+	}
+	if !session.IsActive {
+		return ErrChatSessionClosed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ChatReplyTimeout)
+	defer cancel()
+
+	s.emit(models.ChatEvent{Type: models.ChatEventTyping, InterviewID: interviewID})
+	_, err = s.finalize(ctx, session, reason)
+	return err
+}
+
+// policyFor - ограничения собеседования по вакансии. Если вакансия недоступна, действуют ограничения по умолчанию.
+func (s *ChatServiceImpl) policyFor(ctx context.Context, vacancyID string) InterviewPolicy {
+	vacancy, err := s.vacancySvc.GetVacancy(ctx, vacancyID)
+	if err != nil {
+		log.Printf("⚠️ Failed to get interview policy of vacancy %s: %v", vacancyID, err)
+		return s.policy
+	}
+	return s.policy.ForVacancy(vacancy)
+}
+
 func (s *ChatServiceImpl) saveResultToResume(interviewID, resultString string) error {
 	session, err := s.GetSession(interviewID)
 	if err != nil {
@@ -140,39 +302,82 @@ func (s *ChatServiceImpl) saveResultToResume(interviewID, resultString string) e
 
 func (s *ChatServiceImpl) AddAIMessage(interviewID string, msg *models.ChatMessage) error {
 	msg.Sender = "ai"
-	return s.addMessage(interviewID, msg)
+	return s.addMessage(interviewID, msg, "")
 }
 
-func (s *ChatServiceImpl) addMessage(interviewID string, msg *models.ChatMessage) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	session, exists := s.sessions[interviewID]
-	if !exists {
-		return fmt.Errorf("session not found")
+// addMessage сохраняет сообщение и рассылает его подписчикам.
+// requestID связывает ответ AI с уже отправленными частями.
+func (s *ChatServiceImpl) addMessage(interviewID string, msg *models.ChatMessage, requestID string) error {
+	session, err := s.GetSession(interviewID)
+	if err != nil {
+		return err
 	}
+
 	if !session.IsActive {
-		return fmt.Errorf("session is closed")
+		return ErrChatSessionClosed
 	}
 
-	msg.ID = generateID()
 	msg.InterviewID = interviewID
+	if err := s.messages.Append(context.Background(), msg); err != nil {
+		return fmt.Errorf("failed to save message: %w", err)
+	}
 
-	session.Messages = append(session.Messages, *msg)
-	session.MessageCount++
-
+	s.emit(models.ChatEvent{Type: models.ChatEventMessage, InterviewID: interviewID, RequestID: requestID, Message: msg})
 	return nil
 }
 
+func (s *ChatServiceImpl) emit(event models.ChatEvent) {
+	if s.events != nil {
+		s.events.Publish(event)
+	}
+}
+
+// emitStatus рассылает текущее состояние собеседования
+func (s *ChatServiceImpl) emitStatus(interviewID string) {
+	if s.events == nil {
+		return
+	}
+	status, err := s.GetStatus(interviewID)
+	if err != nil {
+		return
+	}
+	s.emit(models.ChatEvent{Type: models.ChatEventStatus, InterviewID: interviewID, Status: status})
+}
+
+// claimTurn захватывает ход чата собеседования. Возвращает функцию освобождения.
+func (s *ChatServiceImpl) claimTurn(interviewID string) (func(), error) {
+	owner := uuid.NewString()
+	claimed, err := s.interviews.ClaimChatTurn(context.Background(), interviewID, owner, chatTurnTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim chat turn: %w", err)
+	}
+	if !claimed {
+		return nil, ErrChatBusy
+	}
+
+	return func() {
+		if err := s.interviews.ReleaseChatTurn(context.Background(), interviewID, owner); err != nil {
+			log.Printf("⚠️ Failed to release chat turn of interview %s: %v", interviewID, err)
+		}
+	}, nil
+}
+
 func (s *ChatServiceImpl) CreateSession(interviewID string, resumeID, vacancyID string) (*models.ChatSession, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	release, err := s.claimTurn(interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	if _, exists := s.sessions[interviewID]; exists {
+	ctx := context.Background()
+	existing, err := s.messages.ListByInterview(ctx, interviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	if len(existing) > 0 {
 		return nil, fmt.Errorf("session already exists")
 	}
 
-	ctx := context.Background()
 	vacancy, err := s.vacancySvc.GetVacancy(ctx, vacancyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vacancy: %w", err)
@@ -204,86 +409,149 @@ func (s *ChatServiceImpl) CreateSession(interviewID string, resumeID, vacancyID
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	welcomeMsg := models.ChatMessage{
+		InterviewID: interviewID,
+		Type:        models.MessageTypeQuestion,
+		Content:     "Добро пожаловать на собеседование! Расскажите немного о себе.",
+		Sender:      "ai",
+	}
 	aiResponse, err := s.aiSvc.GenerateWelcomeResponse(ctx, string(vacancyJSON), resumeText, interviewID)
-	if err != nil {
-		welcomeMsg := models.ChatMessage{
-			Type:    models.MessageTypeQuestion,
-			Content: "Добро пожаловать на собеседование! Расскажите немного о себе.",
-			Sender:  "ai",
-		}
-		welcomeMsg.ID = generateID()
-		welcomeMsg.InterviewID = interviewID
-		session.Messages = append(session.Messages, welcomeMsg)
-		session.MessageCount = 1
-	} else {
-		welcomeMsg := models.ChatMessage{
-			Type:    models.MessageTypeQuestion,
-			Content: aiResponse.Response,
-			Sender:  "ai",
-		}
-		welcomeMsg.ID = generateID()
-		welcomeMsg.InterviewID = interviewID
-		session.Messages = append(session.Messages, welcomeMsg)
-		session.MessageCount = 1
+	if err == nil {
+		welcomeMsg.Content = aiResponse.Response
 	}
-
-	s.sessions[interviewID] = session
-
-	err = s.resumeSvc.UpdateStatus(ctx, resumeID, "Проходит собеседование")
+	if err := s.messages.Append(ctx, &welcomeMsg); err != nil {
+		return nil, fmt.Errorf("failed to save welcome message: %w", err)
+	}
+	s.emit(models.ChatEvent{Type: models.ChatEventMessage, InterviewID: interviewID, Message: &welcomeMsg})
+	session.Messages = append(session.Messages, welcomeMsg)
+	session.MessageCount = 1
+
+	err = s.changeResumeStatus(ctx, resumeID, StatusChange{
+		To:     models.ResumeStatusInterviewing,
+		Actor:  models.ResumeActorInterview,
+		Reason: "interview " + interviewID + " started",
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	s.emitStatus(interviewID)
 	return session, nil
 }
 
+// changeResumeStatus переводит резюме кандидата. Если резюме уже в другом статусе
+// (например, рекрутер его отклонил), переход только логируется.
+func (s *ChatServiceImpl) changeResumeStatus(ctx context.Context, resumeID string, change StatusChange) error {
+	if resumeID == "" {
+		return nil
+	}
+	err := s.resumeSvc.ChangeStatus(ctx, resumeID, change)
+	if errors.Is(err, ErrInvalidStatusTransition) {
+		log.Printf("⚠️ Resume %s status is not changed: %v", resumeID, err)
+		return nil
+	}
+	return err
+}
+
 func (s *ChatServiceImpl) GetSession(interviewID string) (*models.ChatSession, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	session, exists := s.sessions[interviewID]
-	if !exists {
-		return nil, fmt.Errorf("session not found")
+	return s.loadSession(context.Background(), interviewID)
+}
+
+// loadSession собирает сессию из сохраненной переписки. Сессия закрыта, если собеседование
+// завершено или отменено, переписка уже сохранена в text_jsonb или получен итоговый результат.
+func (s *ChatServiceImpl) loadSession(ctx context.Context, interviewID string) (*models.ChatSession, error) {
+	messages, err := s.messages.ListByInterview(ctx, interviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, ErrChatSessionNotFound
+	}
+
+	interview, err := s.interviews.GetByID(ctx, interviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interview: %w", err)
+	}
+
+	session := &models.ChatSession{
+		InterviewID:  interviewID,
+		Messages:     messages,
+		MessageCount: len(messages),
+		VacancyID:    interview.VacancyID,
+		StartedAt:    interview.StartedAt,
+		IsActive: interview.Status == models.InterviewStatusStarted &&
+			len(interview.TextJSONB) == 0 &&
+			messages[len(messages)-1].Type != models.MessageTypeResult,
+	}
+	if interview.ResumeID != nil {
+		session.ResumeID = *interview.ResumeID
 	}
 	return session, nil
 }
 
 func (s *ChatServiceImpl) GetMessages(interviewID string) ([]models.ChatMessage, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	session, exists := s.sessions[interviewID]
-	if !exists {
-		return nil, fmt.Errorf("session not found")
+	session, err := s.GetSession(interviewID)
+	if err != nil {
+		return nil, err
 	}
 	return session.Messages, nil
 }
 
 func (s *ChatServiceImpl) GetStatus(interviewID string) (*models.InterviewStatus, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	session, exists := s.sessions[interviewID]
-	if !exists {
-		return nil, fmt.Errorf("session not found")
-	}
-	return &models.InterviewStatus{
-		InterviewID:  session.InterviewID,
-		Status:       ifStatus(session.IsActive),
-		IsActive:     session.IsActive,
-		MessageCount: session.MessageCount,
-	}, nil
+	session, err := s.GetSession(interviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.InterviewStatus{
+		InterviewID:    session.InterviewID,
+		Status:         ifStatus(session.IsActive),
+		IsActive:       session.IsActive,
+		MessageCount:   session.MessageCount,
+		QuestionsAsked: countQuestions(session.Messages),
+	}
+	if !session.IsActive {
+		return status, nil
+	}
+
+	policy := s.policyFor(context.Background(), session.VacancyID)
+	status.MaxQuestions = policy.MaxQuestions
+	if deadline := policy.Deadline(sessionStartedAt(session)); deadline != nil {
+		remaining := int(time.Until(*deadline).Seconds())
+		if remaining < 0 {
+			remaining = 0
+		}
+		status.Deadline = deadline
+		status.RemainingSeconds = &remaining
+	}
+	return status, nil
 }
 
+// CloseSession закрывает сессию, сохраняя полную переписку в interviews.text_jsonb,
+// и завершает начатое собеседование
 func (s *ChatServiceImpl) CloseSession(interviewID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	session, exists := s.sessions[interviewID]
-	if !exists {
-		return fmt.Errorf("session not found")
+	session, err := s.GetSession(interviewID)
+	if err != nil {
+		return err
+	}
+
+	transcript, err := json.Marshal(session.Messages)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+
+	ctx := context.Background()
+	if err := s.interviews.UpdateTranscript(ctx, interviewID, transcript); err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
 	}
-	session.IsActive = false
-	return nil
-}
 
-func generateID() string {
-	return fmt.Sprintf("msg_%d", time.Now().UnixNano())
+	// Собеседование, которое уже завершили или отменили, остается в своем статусе
+	if _, err := s.interviews.TransitionStatus(ctx, interviewID, models.InterviewStatusStarted, models.InterviewStatusFinished); err != nil {
+		return fmt.Errorf("failed to finish interview: %w", err)
+	}
+
+	s.emitStatus(interviewID)
+	return nil
 }
 
 func ifStatus(active bool) string {
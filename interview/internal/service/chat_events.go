@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+	"interview/internal/broker"
+	"interview/internal/models"
+)
+
+// chatEventBuffer - сколько событий ждет медленного подписчика, дальше события отбрасываются
+const chatEventBuffer = 64
+
+// ChatEventBus доставляет события чата подписчикам собеседования
+type ChatEventBus interface {
+	Publish(event models.ChatEvent)
+	// Subscribe подписывает на события собеседования. Вторым значением возвращается отписка.
+	Subscribe(interviewID string) (<-chan models.ChatEvent, func())
+}
+
+// ChatEventHub - подписчики одного процесса
+type ChatEventHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan models.ChatEvent]struct{}
+}
+
+func NewChatEventHub() *ChatEventHub {
+	return &ChatEventHub{subscribers: make(map[string]map[chan models.ChatEvent]struct{})}
+}
+
+func (h *ChatEventHub) Publish(event models.ChatEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[event.InterviewID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *ChatEventHub) Subscribe(interviewID string) (<-chan models.ChatEvent, func()) {
+	ch := make(chan models.ChatEvent, chatEventBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[interviewID] == nil {
+		h.subscribers[interviewID] = make(map[chan models.ChatEvent]struct{})
+	}
+	h.subscribers[interviewID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers[interviewID], ch)
+			if len(h.subscribers[interviewID]) == 0 {
+				delete(h.subscribers, interviewID)
+			}
+			close(ch)
+		})
+	}
+}
+
+// RabbitChatEventBus рассылает события чата всем репликам через topic exchange.
+// Кандидат может слушать события на одной реплике, а сообщение обработает другая.
+// Каждая реплика читает exchange своей эксклюзивной очередью и раздает события локальным подписчикам.
+type RabbitChatEventBus struct {
+	conn     *broker.ConnectionManager
+	exchange string
+	hub      *ChatEventHub
+
+	mu      sync.Mutex
+	channel *amqp.Channel
+}
+
+func NewRabbitChatEventBus(conn *broker.ConnectionManager, exchange string) (*RabbitChatEventBus, error) {
+	bus := &RabbitChatEventBus{conn: conn, exchange: exchange, hub: NewChatEventHub()}
+
+	err := conn.DeclareTopology(func(ch *amqp.Channel) error {
+		if err := ch.ExchangeDeclare(exchange, "topic", false, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare chat events exchange: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conn.Consume("chat_events", bus.consume, bus.handle)
+	return bus, nil
+}
+
+func (b *RabbitChatEventBus) consume(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare chat events queue: %w", err)
+	}
+	if err := ch.QueueBind(q.Name, "#", b.exchange, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to bind chat events queue: %w", err)
+	}
+	return ch.Consume(q.Name, "", true, true, false, false, nil)
+}
+
+func (b *RabbitChatEventBus) handle(msg amqp.Delivery) {
+	var event models.ChatEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("⚠️ Invalid chat event: %v", err)
+		return
+	}
+	b.hub.Publish(event)
+}
+
+// Publish отправляет событие в exchange. События не переживают рестарт брокера:
+// пропущенное кандидат увидит в истории сообщений.
+func (b *RabbitChatEventBus) Publish(event models.ChatEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("⚠️ Failed to marshal chat event: %v", err)
+		return
+	}
+
+	if err := b.publish(body, event.InterviewID); err != nil {
+		// Подписчики этой реплики все равно получат событие
+		log.Printf("⚠️ Failed to publish chat event: %v", err)
+		b.hub.Publish(event)
+	}
+}
+
+func (b *RabbitChatEventBus) publish(body []byte, routingKey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.channel == nil {
+			ch, chErr := b.conn.Channel(ctx)
+			if chErr != nil {
+				return chErr
+			}
+			b.channel = ch
+		}
+
+		err = b.channel.Publish(b.exchange, routingKey, false, false, amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		})
+		if err == nil {
+			return nil
+		}
+		b.channel.Close()
+		b.channel = nil
+	}
+	return err
+}
+
+func (b *RabbitChatEventBus) Subscribe(interviewID string) (<-chan models.ChatEvent, func()) {
+	return b.hub.Subscribe(interviewID)
+}
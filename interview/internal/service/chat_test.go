@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeChatMessages struct {
+	mu       sync.Mutex
+	messages map[string][]models.ChatMessage
+}
+
+func (r *fakeChatMessages) Append(ctx context.Context, msg *models.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.Seq = len(r.messages[msg.InterviewID]) + 1
+	msg.ID = fmt.Sprintf("%s-%d", msg.InterviewID, msg.Seq)
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	r.messages[msg.InterviewID] = append(r.messages[msg.InterviewID], *msg)
+	return nil
+}
+
+func (r *fakeChatMessages) ListByInterview(ctx context.Context, interviewID string) ([]models.ChatMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.ChatMessage(nil), r.messages[interviewID]...), nil
+}
+
+type fakeChatInterviews struct {
+	repository.InterviewRepository
+	interview *models.Interview
+	lockOwner string
+}
+
+func (r *fakeChatInterviews) GetByID(ctx context.Context, id string) (*models.Interview, error) {
+	return r.interview, nil
+}
+
+func (r *fakeChatInterviews) TransitionStatus(ctx context.Context, id string, from, to string) (bool, error) {
+	if r.interview.Status != from {
+		return false, nil
+	}
+	r.interview.Status = to
+	return true, nil
+}
+
+func (r *fakeChatInterviews) UpdateTranscript(ctx context.Context, id string, transcript datatypes.JSON) error {
+	r.interview.TextJSONB = transcript
+	return nil
+}
+
+func (r *fakeChatInterviews) ClaimChatTurn(ctx context.Context, id string, owner string, ttl time.Duration) (bool, error) {
+	if r.lockOwner != "" {
+		return false, nil
+	}
+	r.lockOwner = owner
+	return true, nil
+}
+
+func (r *fakeChatInterviews) ReleaseChatTurn(ctx context.Context, id string, owner string) error {
+	if r.lockOwner == owner {
+		r.lockOwner = ""
+	}
+	return nil
+}
+
+type fakeAI struct {
+	response    *AIResponse
+	final       *AIResponse // ответ на запрос итога, nil - AI недоступен
+	finalReason string
+}
+
+func (a *fakeAI) GenerateResponse(ctx context.Context, userInput string, conversation []models.ChatMessage, interviewID string) (*AIResponse, error) {
+	return a.response, nil
+}
+
+func (a *fakeAI) GenerateResponseStream(ctx context.Context, userInput string, conversation []models.ChatMessage, interviewID string, onChunk ChunkHandler) (*AIResponse, error) {
+	for _, word := range strings.SplitAfter(a.response.Response, " ") {
+		onChunk(&AIResponse{RequestID: "req", Partial: true, Delta: word})
+	}
+	resp := *a.response
+	resp.RequestID = "req"
+	return &resp, nil
+}
+
+func (a *fakeAI) GenerateFinalResult(ctx context.Context, conversation []models.ChatMessage, interviewID, reason string) (*AIResponse, error) {
+	a.finalReason = reason
+	if a.final == nil {
+		return nil, fmt.Errorf("AI unavailable")
+	}
+	return a.final, nil
+}
+
+func (a *fakeAI) GenerateWelcomeResponse(ctx context.Context, vacancyJSON, resumeText, interviewID string) (*AIResponse, error) {
+	return &AIResponse{Response: "Здравствуйте! Расскажите о себе.", MessageType: "question"}, nil
+}
+
+type fakeChatResumes struct {
+	ResumeService
+	statusErr error
+}
+
+func (s *fakeChatResumes) GetResume(ctx context.Context, id string) (*models.Resume, error) {
+	return &models.Resume{ID: id, Text: "resume"}, nil
+}
+
+func (s *fakeChatResumes) ChangeStatus(ctx context.Context, id string, change StatusChange) error {
+	return s.statusErr
+}
+
+func (s *fakeChatResumes) UpdateResult(ctx context.Context, id string, result map[string]interface{}) error {
+	return nil
+}
+
+type fakeChatVacancies struct {
+	VacancyService
+	vacancy *models.Vacancy
+}
+
+func (s *fakeChatVacancies) GetVacancy(ctx context.Context, id string) (*models.Vacancy, error) {
+	if s.vacancy != nil {
+		return s.vacancy, nil
+	}
+	return &models.Vacancy{ID: id}, nil
+}
+
+func TestChatService_Persistence(t *testing.T) {
+	resumeID := "resume"
+	newRepos := func() (*fakeChatMessages, *fakeChatInterviews) {
+		return &fakeChatMessages{messages: map[string][]models.ChatMessage{}},
+			&fakeChatInterviews{interview: &models.Interview{ID: "interview", VacancyID: "vacancy", ResumeID: &resumeID, Status: "started"}}
+	}
+	newService := func(ai *fakeAI, messages *fakeChatMessages, interviews *fakeChatInterviews) ChatService {
+		return NewChatService(ai, &fakeChatResumes{}, &fakeChatVacancies{}, messages, interviews, nil, DefaultInterviewPolicy)
+	}
+	question := &fakeAI{response: &AIResponse{Response: "Какой у вас опыт?", MessageType: "question"}}
+
+	t.Run("переписка общая для всех реплик", func(t *testing.T) {
+		messages, interviews := newRepos()
+		svc := newService(question, messages, interviews)
+
+		_, err := svc.CreateSession("interview", resumeID, "vacancy")
+		require.NoError(t, err)
+		_, err = svc.AddCandidateMessage("interview", &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Пять лет", Sender: "candidate"})
+		require.NoError(t, err)
+
+		replica := newService(question, messages, interviews)
+		history, err := replica.GetMessages("interview")
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, []int{1, 2, 3}, []int{history[0].Seq, history[1].Seq, history[2].Seq})
+		assert.Equal(t, "Пять лет", history[1].Content)
+
+		session, err := replica.GetSession("interview")
+		require.NoError(t, err)
+		assert.True(t, session.IsActive)
+		assert.Equal(t, resumeID, session.ResumeID)
+
+		_, err = replica.CreateSession("interview", resumeID, "vacancy")
+		assert.Error(t, err, "сессия уже есть в БД")
+	})
+
+	t.Run("итоговый результат закрывает сессию и сохраняет переписку", func(t *testing.T) {
+		messages, interviews := newRepos()
+		result := &fakeAI{response: &AIResponse{Response: "Спасибо!", MessageType: "result", Result: `{"overall_assessment":{}}`}}
+		svc := newService(result, messages, interviews)
+
+		_, err := svc.CreateSession("interview", resumeID, "vacancy")
+		require.NoError(t, err)
+		_, err = svc.AddCandidateMessage("interview", &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Готово", Sender: "candidate"})
+		require.NoError(t, err)
+
+		var transcript []models.ChatMessage
+		require.NoError(t, json.Unmarshal(interviews.interview.TextJSONB, &transcript))
+		assert.Len(t, transcript, 3)
+
+		status, err := newService(result, messages, interviews).GetStatus("interview")
+		require.NoError(t, err)
+		assert.False(t, status.IsActive)
+		assert.Equal(t, 3, status.MessageCount)
+		assert.Equal(t, models.InterviewStatusFinished, interviews.interview.Status)
+	})
+
+	t.Run("резюме уже отклонено - сессия все равно закрывается", func(t *testing.T) {
+		messages, interviews := newRepos()
+		result := &fakeAI{response: &AIResponse{Response: "Спасибо!", MessageType: "result", Result: `{"overall_assessment":{}}`}}
+		resumes := &fakeChatResumes{statusErr: fmt.Errorf("%w: rejected -> interviewing", ErrInvalidStatusTransition)}
+		svc := NewChatService(result, resumes, &fakeChatVacancies{}, messages, interviews, nil, DefaultInterviewPolicy)
+
+		_, err := svc.CreateSession("interview", resumeID, "vacancy")
+		require.NoError(t, err)
+		reply, err := svc.AddCandidateMessage("interview", &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Готово", Sender: "candidate"})
+		require.NoError(t, err)
+		assert.Equal(t, models.MessageTypeResult, reply.Type)
+		assert.Equal(t, models.InterviewStatusFinished, interviews.interview.Status)
+	})
+
+	t.Run("ход занят другой репликой", func(t *testing.T) {
+		messages, interviews := newRepos()
+		svc := newService(question, messages, interviews)
+		_, err := svc.CreateSession("interview", resumeID, "vacancy")
+		require.NoError(t, err)
+		require.Empty(t, interviews.lockOwner, "ход освобождается после создания сессии")
+
+		interviews.lockOwner = "other"
+		_, err = svc.AddCandidateMessage("interview", &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Ответ", Sender: "candidate"})
+		assert.ErrorIs(t, err, ErrChatBusy)
+
+		history, err := svc.GetMessages("interview")
+		require.NoError(t, err)
+		assert.Len(t, history, 1, "сообщение не сохранено")
+		assert.Equal(t, "other", interviews.lockOwner, "чужой ход не освобождается")
+	})
+
+	t.Run("фоновый ответ можно дождаться", func(t *testing.T) {
+		messages, interviews := newRepos()
+		svc := newService(question, messages, interviews)
+		_, err := svc.CreateSession("interview", resumeID, "vacancy")
+		require.NoError(t, err)
+
+		err = svc.SubmitCandidateMessage("interview", &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Пять лет", Sender: "candidate"})
+		require.NoError(t, err)
+		svc.Wait()
+
+		history, err := svc.GetMessages("interview")
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, "ai", history[2].Sender)
+		assert.Empty(t, interviews.lockOwner, "ход освобожден")
+	})
+
+	t.Run("сессии нет", func(t *testing.T) {
+		messages, interviews := newRepos()
+		_, err := newService(question, messages, interviews).GetMessages("interview")
+		assert.ErrorIs(t, err, ErrChatSessionNotFound)
+	})
+}
+
+func TestChatService_Events(t *testing.T) {
+	resumeID := "resume"
+	hub := NewChatEventHub()
+	svc := NewChatService(
+		&fakeAI{response: &AIResponse{Response: "Какой у вас опыт?", MessageType: "question"}},
+		&fakeChatResumes{},
+		&fakeChatVacancies{},
+		&fakeChatMessages{messages: map[string][]models.ChatMessage{}},
+		&fakeChatInterviews{interview: &models.Interview{ID: "interview", VacancyID: "vacancy", ResumeID: &resumeID, Status: "started"}},
+		hub,
+		DefaultInterviewPolicy,
+	)
+	_, err := svc.CreateSession("interview", resumeID, "vacancy")
+	require.NoError(t, err)
+
+	events, unsubscribe := hub.Subscribe("interview")
+	_, err = svc.AddCandidateMessage("interview", &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Здравствуйте", Sender: "candidate"})
+	require.NoError(t, err)
+	unsubscribe()
+
+	var types []string
+	var streamed strings.Builder
+	var reply *models.ChatEvent
+	for event := range events {
+		types = append(types, event.Type)
+		switch {
+		case event.Type == models.ChatEventChunk:
+			assert.Equal(t, "req", event.RequestID)
+			streamed.WriteString(event.Delta)
+		case event.Type == models.ChatEventMessage && event.Message.Sender == "ai":
+			reply = &event
+		}
+	}
+
+	assert.Equal(t, []string{"message", "typing", "chunk", "chunk", "chunk", "chunk", "message"}, types)
+	assert.Equal(t, "Какой у вас опыт?", streamed.String())
+	require.NotNil(t, reply)
+	assert.Equal(t, "req", reply.RequestID, "итоговое сообщение связано с частями")
+}
+
+func TestChatService_Limits(t *testing.T) {
+	resumeID := "resume"
+	intPtr := func(v int) *int { return &v }
+	answer := func() *models.ChatMessage {
+		return &models.ChatMessage{Type: models.MessageTypeAnswer, Content: "Ответ", Sender: "candidate"}
+	}
+	newService := func(ai *fakeAI, vacancy *models.Vacancy, startedAt time.Time) (ChatService, *fakeChatInterviews) {
+		interviews := &fakeChatInterviews{interview: &models.Interview{
+			ID: "interview", VacancyID: "vacancy", ResumeID: &resumeID, Status: "started", StartedAt: &startedAt,
+		}}
+		svc := NewChatService(ai, &fakeChatResumes{}, &fakeChatVacancies{vacancy: vacancy},
+			&fakeChatMessages{messages: map[string][]models.ChatMessage{}}, interviews, nil, DefaultInterviewPolicy)
+		_, err := svc.CreateSession("interview", resumeID, "vacancy")
+		require.NoError(t, err)
+		return svc, interviews
+	}
+	final := &AIResponse{Response: "Спасибо, собеседование окончено.", MessageType: "result", Result: `{"overall_assessment":{}}`}
+
+	t.Run("вопросы закончились", func(t *testing.T) {
+		ai := &fakeAI{response: &AIResponse{Response: "Какой у вас опыт?", MessageType: "question"}, final: final}
+		svc, interviews := newService(ai, &models.Vacancy{ID: "vacancy", InterviewMaxQuestions: intPtr(2)}, time.Now())
+
+		reply, err := svc.AddCandidateMessage("interview", answer())
+		require.NoError(t, err)
+		assert.Equal(t, models.MessageTypeQuestion, reply.Type, "второй вопрос в пределах лимита")
+
+		reply, err = svc.AddCandidateMessage("interview", answer())
+		require.NoError(t, err)
+		assert.Equal(t, models.MessageTypeResult, reply.Type)
+		assert.Equal(t, FinishReasonQuestionLimit, ai.finalReason)
+		assert.Equal(t, models.InterviewStatusFinished, interviews.interview.Status)
+	})
+
+	t.Run("время вышло", func(t *testing.T) {
+		ai := &fakeAI{response: &AIResponse{Response: "Какой у вас опыт?", MessageType: "question"}, final: final}
+		svc, interviews := newService(ai, &models.Vacancy{ID: "vacancy", InterviewMaxMinutes: intPtr(20)}, time.Now().Add(-21*time.Minute))
+
+		reply, err := svc.AddCandidateMessage("interview", answer())
+		require.NoError(t, err)
+		assert.Equal(t, "Спасибо, собеседование окончено.", reply.Content)
+		assert.Equal(t, FinishReasonTimeLimit, ai.finalReason)
+		assert.Equal(t, models.InterviewStatusFinished, interviews.interview.Status)
+
+		_, err = svc.AddCandidateMessage("interview", answer())
+		assert.ErrorIs(t, err, ErrChatSessionClosed)
+	})
+
+	t.Run("AI не подвел итог", func(t *testing.T) {
+		ai := &fakeAI{response: &AIResponse{Response: "Какой у вас опыт?", MessageType: "question"}}
+		svc, interviews := newService(ai, nil, time.Now())
+
+		require.NoError(t, svc.FinishByLimit("interview", FinishReasonIdle))
+		history, err := svc.GetMessages("interview")
+		require.NoError(t, err)
+		assert.Equal(t, limitReachedMessage, history[len(history)-1].Content)
+		assert.Equal(t, models.InterviewStatusFinished, interviews.interview.Status)
+
+		assert.ErrorIs(t, svc.FinishByLimit("interview", FinishReasonIdle), ErrChatSessionClosed)
+	})
+
+	t.Run("статус показывает оставшееся время", func(t *testing.T) {
+		ai := &fakeAI{response: &AIResponse{Response: "Какой у вас опыт?", MessageType: "question"}}
+		vacancy := &models.Vacancy{ID: "vacancy", InterviewMaxMinutes: intPtr(30), InterviewMaxQuestions: intPtr(10)}
+		svc, _ := newService(ai, vacancy, time.Now().Add(-10*time.Minute))
+
+		status, err := svc.GetStatus("interview")
+		require.NoError(t, err)
+		require.NotNil(t, status.RemainingSeconds)
+		assert.InDelta(t, 20*60, *status.RemainingSeconds, 5)
+		assert.Equal(t, 1, status.QuestionsAsked)
+		assert.Equal(t, 10, status.MaxQuestions)
+	})
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb" // Apache License 2.0
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	docWordIdent     = 0xA5EC
+	docFlagEncrypted = 0x0100
+	docFlagTable1    = 0x0200
+	docCcpTextOffset = 0x004C
+	docFcClxOffset   = 0x01A2
+	docLcbClxOffset  = 0x01A6
+	docMinFibSize    = docLcbClxOffset + 4
+	docFcCompressed  = 0x40000000
+)
+
+// oleSignature - сигнатура составного файла OLE2 (Word 97-2003, Excel и т.д.)
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// readOLEStreams читает потоки составного файла OLE2 по именам
+func readOLEStreams(fileData []byte, names ...string) (map[string][]byte, error) {
+	reader, err := mscfb.New(bytes.NewReader(fileData))
+	if err != nil {
+		return nil, fmt.Errorf("не является валидным OLE2 файлом: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	streams := make(map[string][]byte)
+	for entry, err := reader.Next(); err == nil; entry, err = reader.Next() {
+		if !wanted[entry.Name] || len(entry.Path) > 0 {
+			continue
+		}
+		data, err := io.ReadAll(entry)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать поток %s: %w", entry.Name, err)
+		}
+		streams[entry.Name] = data
+	}
+
+	return streams, nil
+}
+
+// isWordOLE проверяет, что составной файл содержит документ Word, а не таблицу или презентацию
+func isWordOLE(fileData []byte) bool {
+	streams, err := readOLEStreams(fileData, "WordDocument")
+	if err != nil {
+		return false
+	}
+	_, ok := streams["WordDocument"]
+	return ok
+}
+
+// extractFromDOC извлекает текст из бинарного документа Word 97-2003 по таблице фрагментов (CLX)
+func extractFromDOC(fileData []byte) (string, error) {
+	streams, err := readOLEStreams(fileData, "WordDocument", "0Table", "1Table")
+	if err != nil {
+		return "", err
+	}
+
+	wordDoc, ok := streams["WordDocument"]
+	if !ok {
+		return "", fmt.Errorf("не найден поток WordDocument")
+	}
+	if len(wordDoc) < docMinFibSize || binary.LittleEndian.Uint16(wordDoc) != docWordIdent {
+		return "", fmt.Errorf("поврежденный заголовок документа Word")
+	}
+
+	flags := binary.LittleEndian.Uint16(wordDoc[0x0A:])
+	if flags&docFlagEncrypted != 0 {
+		return "", fmt.Errorf("DOC защищен паролем")
+	}
+
+	tableName := "0Table"
+	if flags&docFlagTable1 != 0 {
+		tableName = "1Table"
+	}
+	table, ok := streams[tableName]
+	if !ok {
+		return "", fmt.Errorf("не найден поток %s", tableName)
+	}
+
+	ccpText := binary.LittleEndian.Uint32(wordDoc[docCcpTextOffset:])
+	fcClx := binary.LittleEndian.Uint32(wordDoc[docFcClxOffset:])
+	lcbClx := binary.LittleEndian.Uint32(wordDoc[docLcbClxOffset:])
+	if uint64(fcClx)+uint64(lcbClx) > uint64(len(table)) {
+		return "", fmt.Errorf("таблица фрагментов выходит за пределы потока %s", tableName)
+	}
+
+	plcPcd, err := findPlcPcd(table[fcClx : fcClx+lcbClx])
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := readDOCPieces(wordDoc, plcPcd, ccpText)
+	if err != nil {
+		return "", err
+	}
+
+	text := cleanDOCText(raw)
+	if text == "" {
+		return "", fmt.Errorf("документ не содержит текста")
+	}
+	return text, nil
+}
+
+// findPlcPcd пропускает блоки Prc и возвращает содержимое Pcdt
+func findPlcPcd(clx []byte) ([]byte, error) {
+	for i := 0; i < len(clx); {
+		switch clx[i] {
+		case 0x01: // Prc
+			if i+3 > len(clx) {
+				return nil, fmt.Errorf("поврежденная таблица фрагментов")
+			}
+			cb := int(int16(binary.LittleEndian.Uint16(clx[i+1:])))
+			// Длина знаковая: отрицательная зациклила бы разбор или увела индекс назад
+			if cb < 0 || i+3+cb > len(clx) {
+				return nil, fmt.Errorf("поврежденная таблица фрагментов")
+			}
+			i += 3 + cb
+		case 0x02: // Pcdt
+			if i+5 > len(clx) {
+				return nil, fmt.Errorf("поврежденная таблица фрагментов")
+			}
+			lcb := int(binary.LittleEndian.Uint32(clx[i+1:]))
+			if i+5+lcb > len(clx) {
+				return nil, fmt.Errorf("поврежденная таблица фрагментов")
+			}
+			return clx[i+5 : i+5+lcb], nil
+		default:
+			return nil, fmt.Errorf("неизвестный блок таблицы фрагментов: 0x%02X", clx[i])
+		}
+	}
+	return nil, fmt.Errorf("не найден блок Pcdt")
+}
+
+// readDOCPieces собирает текст основного потока документа из фрагментов,
+// каждый из которых хранится либо в cp1252, либо в UTF-16LE
+func readDOCPieces(wordDoc, plcPcd []byte, ccpText uint32) (string, error) {
+	// PlcPcd: (n+1) позиций символов по 4 байта и n дескрипторов по 8 байт
+	if len(plcPcd) < 4 || (len(plcPcd)-4)%12 != 0 {
+		return "", fmt.Errorf("поврежденный список фрагментов")
+	}
+	n := (len(plcPcd) - 4) / 12
+	decoder := charmap.Windows1252.NewDecoder()
+
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		cpStart := binary.LittleEndian.Uint32(plcPcd[i*4:])
+		cpEnd := binary.LittleEndian.Uint32(plcPcd[(i+1)*4:])
+		if cpStart >= ccpText {
+			break
+		}
+		if cpEnd > ccpText {
+			cpEnd = ccpText
+		}
+		count := int(cpEnd - cpStart)
+
+		pcd := plcPcd[4*(n+1)+i*8:]
+		fc := binary.LittleEndian.Uint32(pcd[2:])
+
+		if fc&docFcCompressed != 0 {
+			offset := int((fc &^ docFcCompressed) / 2)
+			if offset+count > len(wordDoc) {
+				return "", fmt.Errorf("фрагмент выходит за пределы документа")
+			}
+			decoded, err := decoder.Bytes(wordDoc[offset : offset+count])
+			if err != nil {
+				return "", fmt.Errorf("не удалось декодировать фрагмент: %w", err)
+			}
+			b.Write(decoded)
+			continue
+		}
+
+		offset := int(fc)
+		if offset+count*2 > len(wordDoc) {
+			return "", fmt.Errorf("фрагмент выходит за пределы документа")
+		}
+		units := make([]uint16, count)
+		for j := range units {
+			units[j] = binary.LittleEndian.Uint16(wordDoc[offset+j*2:])
+		}
+		b.WriteString(string(utf16.Decode(units)))
+	}
+
+	return b.String(), nil
+}
+
+// cleanDOCText заменяет служебные символы Word и убирает коды полей,
+// оставляя только их отображаемый результат
+func cleanDOCText(raw string) string {
+	var b strings.Builder
+	var fields []bool // стек полей: true, пока читается код поля, а не его результат
+
+	for _, r := range raw {
+		switch r {
+		case 0x13: // начало поля
+			fields = append(fields, true)
+			continue
+		case 0x14: // разделитель кода и результата поля
+			if n := len(fields); n > 0 {
+				fields[n-1] = false
+			}
+			continue
+		case 0x15: // конец поля
+			if n := len(fields); n > 0 {
+				fields = fields[:n-1]
+			}
+			continue
+		}
+		if inFieldInstruction(fields) {
+			continue
+		}
+
+		switch r {
+		case '\r', 0x0B, 0x0C:
+			b.WriteByte('\n')
+		case 0x07: // конец ячейки таблицы
+			b.WriteByte('\t')
+		case '\t':
+			b.WriteByte('\t')
+		case 0xA0:
+			b.WriteByte(' ')
+		default:
+			if r >= 0x20 {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	return normalizeExtractedText(b.String())
+}
+
+// inFieldInstruction сообщает, находится ли текущая позиция внутри кода какого-либо поля
+func inFieldInstruction(fields []bool) bool {
+	for _, instruction := range fields {
+		if instruction {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeExtractedText убирает пробелы по краям строк и схлопывает пустые строки
+func normalizeExtractedText(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
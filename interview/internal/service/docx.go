@@ -0,0 +1,137 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const docxMainNS = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// docxTableRow - строка таблицы DOCX, собираемая по мере чтения XML
+type docxTableRow struct {
+	cells []string
+	cell  []string // абзацы текущей ячейки
+}
+
+// extractDOCXLayoutText извлекает текст DOCX с сохранением разметки шаблона:
+// каждый абзац - отдельная строка, строка таблицы - ячейки через табуляцию.
+// Вложенные таблицы сворачиваются в текст ячейки внешней таблицы.
+func extractDOCXLayoutText(fileData []byte) (string, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		return "", fmt.Errorf("не является валидным ZIP архивом: %w", err)
+	}
+
+	for _, file := range zipReader.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("не удалось открыть document.xml: %w", err)
+		}
+		defer rc.Close()
+
+		return parseDOCXLayout(rc)
+	}
+
+	return "", fmt.Errorf("не найден document.xml в архиве")
+}
+
+func parseDOCXLayout(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var out strings.Builder
+	var paragraph strings.Builder
+	var rows []*docxTableRow // стек строк вложенных таблиц
+	inText := false
+
+	// emit добавляет готовую строку в ячейку текущей таблицы или в документ
+	emit := func(line string) {
+		if n := len(rows); n > 0 {
+			rows[n-1].cell = append(rows[n-1].cell, line)
+			return
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("не удалось разобрать document.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != docxMainNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				paragraph.Reset()
+			case "t":
+				inText = true
+			case "tab":
+				if len(rows) > 0 {
+					paragraph.WriteByte(' ')
+				} else {
+					paragraph.WriteByte('\t')
+				}
+			case "br", "cr":
+				paragraph.WriteByte(' ')
+			case "tr":
+				rows = append(rows, &docxTableRow{})
+			case "tc":
+				if n := len(rows); n > 0 {
+					rows[n-1].cell = nil
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != docxMainNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if line := strings.TrimSpace(paragraph.String()); line != "" {
+					emit(line)
+				}
+				paragraph.Reset()
+			case "tc":
+				if n := len(rows); n > 0 {
+					row := rows[n-1]
+					row.cells = append(row.cells, strings.Join(row.cell, " "))
+					row.cell = nil
+				}
+			case "tr":
+				if n := len(rows); n > 0 {
+					row := rows[n-1]
+					rows = rows[:n-1]
+					separator := "\t"
+					if len(rows) > 0 {
+						separator = " "
+					}
+					if line := strings.TrimSpace(strings.Join(row.cells, separator)); line != "" {
+						emit(line)
+					}
+				}
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
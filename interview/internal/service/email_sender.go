@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// EmailSenderConfig - настройки отправки писем из очереди
+type EmailSenderConfig struct {
+	RatePerMinute int           // писем в минуту с одной реплики, ограничение SMTP провайдера
+	MaxAttempts   int           // после стольких неудач письмо помечается failed
+	PollInterval  time.Duration // как часто проверять очередь
+	RetryDelay    time.Duration // задержка перед первым повтором, дальше удваивается
+	MaxDelay      time.Duration
+	SendTimeout   time.Duration // письмо дольше считается зависшим
+}
+
+// emailMarkTimeout ограничивает запись результата отправки
+const emailMarkTimeout = 10 * time.Second
+
+var DefaultEmailSenderConfig = EmailSenderConfig{
+	RatePerMinute: 30,
+	MaxAttempts:   6,
+	PollInterval:  5 * time.Second,
+	RetryDelay:    time.Minute,
+	MaxDelay:      time.Hour,
+	SendTimeout:   time.Minute,
+}
+
+// EmailSender отправляет письма из таблицы email_outbox через Mailer.
+// Временные ошибки повторяются с растущей задержкой, отклоненные сервером адреса помечаются bounced.
+type EmailSender struct {
+	repo     repository.EmailOutboxRepository
+	mailer   Mailer
+	cfg      EmailSenderConfig
+	interval time.Duration // минимальный интервал между письмами
+}
+
+func NewEmailSender(repo repository.EmailOutboxRepository, mailer Mailer, cfg EmailSenderConfig) *EmailSender {
+	interval := time.Duration(0)
+	if cfg.RatePerMinute > 0 {
+		interval = time.Minute / time.Duration(cfg.RatePerMinute)
+	}
+	return &EmailSender{repo: repo, mailer: mailer, cfg: cfg, interval: interval}
+}
+
+// Run отправляет письма до отмены ctx
+func (s *EmailSender) Run(ctx context.Context) {
+	log.Printf("📧 Email sender started: %d per minute", s.cfg.RatePerMinute)
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var lastSent time.Time
+	for {
+		s.recoverStale(ctx)
+
+		// Письма захватываются по одному после ожидания по ограничению скорости: захваченное письмо
+		// отправляется сразу, и RecoverStale не вернет его в очередь, пока отправка идет.
+		for ctx.Err() == nil {
+			if wait := s.interval - time.Since(lastSent); wait > 0 {
+				select {
+				case <-ctx.Done():
+					log.Println("✅ Email sender stopped")
+					return
+				case <-time.After(wait):
+				}
+			}
+
+			msg, err := s.repo.ClaimNext(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("❌ Failed to claim email: %v", err)
+				}
+				break
+			}
+			if msg == nil {
+				break
+			}
+			s.send(msg)
+			lastSent = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("✅ Email sender stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *EmailSender) recoverStale(ctx context.Context) {
+	recovered, err := s.repo.RecoverStale(ctx, time.Now().Add(-2*s.cfg.SendTimeout))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("❌ Failed to recover stale emails: %v", err)
+		}
+		return
+	}
+	if recovered > 0 {
+		log.Printf("🔄 Recovered %d unsent emails", recovered)
+	}
+}
+
+// send отправляет одно письмо. Контекст не наследуется от Run, чтобы начатая отправка
+// успела записать результат при остановке сервиса.
+func (s *EmailSender) send(msg *models.EmailMessage) {
+	sendCtx, cancelSend := context.WithTimeout(context.Background(), s.cfg.SendTimeout)
+	defer cancelSend()
+
+	mail := MailMessage{To: msg.Recipient, Subject: msg.Subject, HTMLBody: msg.HTMLBody}
+	if msg.CalendarICS != "" {
+		mail.Calendar = &MailCalendar{Method: msg.CalendarMethod, ICS: []byte(msg.CalendarICS)}
+	}
+	err := s.mailer.Send(sendCtx, mail)
+
+	// Результат записывается на своем контексте: долгая отправка могла исчерпать SendTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), emailMarkTimeout)
+	defer cancel()
+
+	if err == nil {
+		ok, markErr := s.repo.MarkSent(ctx, msg)
+		switch {
+		case markErr != nil:
+			log.Printf("❌ Email %s to %s sent, but not marked as sent: %v", msg.ID, msg.Recipient, markErr)
+		case !ok:
+			log.Printf("⚠️ Email %s was returned to the queue while it was being sent", msg.ID)
+		default:
+			log.Printf("📨 Email %s sent to %s", msg.ID, msg.Recipient)
+		}
+		return
+	}
+
+	if errors.Is(err, ErrMailRejected) || msg.Attempts >= s.cfg.MaxAttempts {
+		status := models.EmailStatusFailed
+		if errors.Is(err, ErrMailRejected) {
+			status = models.EmailStatusBounced
+		}
+		log.Printf("❌ Email %s to %s %s after %d attempts: %v", msg.ID, msg.Recipient, status, msg.Attempts, err)
+		if markErr := s.repo.MarkFailed(ctx, msg, status, err.Error()); markErr != nil {
+			log.Printf("❌ Failed to mark email %s as %s: %v", msg.ID, status, markErr)
+		}
+		return
+	}
+
+	delay := retryDelay(msg.Attempts, s.cfg.RetryDelay, s.cfg.MaxDelay)
+	log.Printf("⚠️ Email %s to %s failed (attempt %d/%d), retry in %s: %v",
+		msg.ID, msg.Recipient, msg.Attempts, s.cfg.MaxAttempts, delay, err)
+	if markErr := s.repo.MarkRetry(ctx, msg, time.Now().Add(delay), err.Error()); markErr != nil {
+		log.Printf("❌ Failed to reschedule email %s: %v", msg.ID, markErr)
+	}
+}
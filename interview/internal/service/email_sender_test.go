@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeEmailSenderRepo struct {
+	repository.EmailOutboxRepository
+	status    string
+	lastError string
+	nextRunAt time.Time
+	markErr   error // ошибка контекста в момент записи результата
+
+	mu      sync.Mutex
+	queued  []*models.EmailMessage
+	claimed int // захвачено и еще не отмечено
+	maxHeld int // больше всего писем, захваченных одновременно
+	sent    []string
+}
+
+func (r *fakeEmailSenderRepo) ClaimNext(ctx context.Context) (*models.EmailMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queued) == 0 {
+		return nil, nil
+	}
+	msg := r.queued[0]
+	r.queued = r.queued[1:]
+	r.claimed++
+	r.maxHeld = max(r.maxHeld, r.claimed)
+	return msg, nil
+}
+
+func (r *fakeEmailSenderRepo) RecoverStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeEmailSenderRepo) MarkSent(ctx context.Context, msg *models.EmailMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status, r.markErr = models.EmailStatusSent, ctx.Err()
+	r.claimed--
+	r.sent = append(r.sent, msg.ID)
+	return true, nil
+}
+
+func (r *fakeEmailSenderRepo) MarkRetry(ctx context.Context, msg *models.EmailMessage, nextRunAt time.Time, lastError string) error {
+	r.status, r.nextRunAt, r.lastError = models.EmailStatusQueued, nextRunAt, lastError
+	return nil
+}
+
+func (r *fakeEmailSenderRepo) MarkFailed(ctx context.Context, msg *models.EmailMessage, status string, lastError string) error {
+	r.status, r.lastError = status, lastError
+	return nil
+}
+
+type failingMailer struct {
+	err error
+}
+
+func (m *failingMailer) Send(ctx context.Context, msg MailMessage) error {
+	return m.err
+}
+
+func TestEmailSender_Send(t *testing.T) {
+	cfg := DefaultEmailSenderConfig
+	cfg.MaxAttempts = 3
+
+	tests := []struct {
+		name       string
+		err        error
+		attempts   int
+		wantStatus string
+	}{
+		{name: "письмо отправлено", attempts: 1, wantStatus: models.EmailStatusSent},
+		{name: "временная ошибка - повтор", err: errors.New("connection refused"), attempts: 1, wantStatus: models.EmailStatusQueued},
+		{name: "попытки закончились", err: errors.New("connection refused"), attempts: 3, wantStatus: models.EmailStatusFailed},
+		{name: "адрес отклонен - без повтора", err: fmt.Errorf("%w: 550 no such user", ErrMailRejected), attempts: 1, wantStatus: models.EmailStatusBounced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEmailSenderRepo{}
+			sender := NewEmailSender(repo, &failingMailer{err: tt.err}, cfg)
+
+			sender.send(&models.EmailMessage{ID: "email", Recipient: "candidate@example.com", Attempts: tt.attempts})
+
+			assert.Equal(t, tt.wantStatus, repo.status)
+			if tt.err != nil {
+				assert.Contains(t, repo.lastError, tt.err.Error())
+			}
+			if tt.wantStatus == models.EmailStatusQueued {
+				assert.True(t, repo.nextRunAt.After(time.Now()))
+			}
+		})
+	}
+}
+
+// slowMailer отправляет письмо к самому концу таймаута отправки
+type slowMailer struct{}
+
+func (m *slowMailer) Send(ctx context.Context, msg MailMessage) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestEmailSender_SendMarksAfterTimeout(t *testing.T) {
+	cfg := DefaultEmailSenderConfig
+	cfg.SendTimeout = time.Millisecond
+	repo := &fakeEmailSenderRepo{}
+	sender := NewEmailSender(repo, &slowMailer{}, cfg)
+
+	sender.send(&models.EmailMessage{ID: "email", Recipient: "candidate@example.com", Attempts: 1})
+
+	assert.Equal(t, models.EmailStatusSent, repo.status)
+	assert.NoError(t, repo.markErr, "результат пишется не на контексте отправки")
+}
+
+func TestEmailSender_RunClaimsOneAtATime(t *testing.T) {
+	repo := &fakeEmailSenderRepo{}
+	for i := 0; i < 3; i++ {
+		repo.queued = append(repo.queued, &models.EmailMessage{ID: fmt.Sprintf("email-%d", i)})
+	}
+	cfg := DefaultEmailSenderConfig
+	cfg.RatePerMinute = 6000
+	sender := NewEmailSender(repo, &failingMailer{}, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sender.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return len(repo.sent) == 3
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"email-0", "email-1", "email-2"}, repo.sent)
+	assert.Equal(t, 1, repo.maxHeld, "письмо захватывается только перед отправкой")
+}
@@ -3,10 +3,7 @@ package service
 import (
 	"archive/zip"
 	"bytes"
-	"context"
 	"fmt"
-	"github.com/guylaor/goword"
-	"interview/internal/storage"
 	"io"
 	"log"
 	"os"
@@ -23,6 +20,8 @@ const (
 	FileTypeDOC
 	FileTypePDF
 	FileTypeTXT
+	FileTypeRTF
+	FileTypeODT
 	FileTypeUnknown
 )
 
@@ -73,87 +72,19 @@ type DocxText struct {
 	Value string `xml:",chardata"`
 }
 
-func ExtractVacancyFromS3Key(ctx context.Context, storage *storage.S3Storage, storageKey string) (*Job, error) {
-	reader, err := storage.DownloadFile(ctx, storageKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download file from S3: %w", err)
-	}
-	defer reader.Close()
-
-	tempFile, err := os.CreateTemp("", "extraction_*.docx")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(tempFile.Name())
-	defer tempFile.Close()
-
-	_, err = io.Copy(tempFile, reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file: %w", err)
+// extractVacancyText извлекает текст вакансии с сохранением разбиения на строки.
+// DOCX разбирается с сохранением строк таблиц, остальные форматы - через ExtractTextFromFile.
+func extractVacancyText(fileData []byte, filename string) (string, error) {
+	if detectFileType(fileData) != FileTypeDOCX {
+		return ExtractTextFromFile(fileData, filename)
 	}
 
-	text, err := goword.ParseText(tempFile.Name())
+	text, err := extractDOCXLayoutText(fileData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse docx file: %w", err)
-	}
-
-	lines := strings.Split(text, "\n")
-	for i := range lines {
-		lines[i] = strings.TrimSpace(lines[i])
-	}
-
-	job := &Job{}
-
-	keyMap := map[string]*string{
-		"Статус":                       &job.Статус,
-		"Название":                     &job.Название,
-		"Регион":                       &job.Регион,
-		"Город":                        &job.Город,
-		"Адрес":                        &job.Адрес,
-		"Тип трудового":                &job.ТипТрудового,
-		"Тип занятости":                &job.ТипЗанятости,
-		"Текст график работы":          &job.График,
-		"Доход (руб/мес)":              &job.Доход,
-		"Оклад макс. (руб/мес)":        &job.ОкладМакс,
-		"Оклад мин. (руб/мес)":         &job.ОкладМин,
-		"Годовая премия (%)":           &job.ГодоваяПремия,
-		"Тип премирования. Описание":   &job.ТипПремирования,
-		"Обязанности (для публикации)": &job.Обязанности,
-		"Требования (для публикации)":  &job.Требования,
-		"Уровень образования":          &job.Образование,
-		"Требуемый опыт работы":        &job.Опыт,
-		"Знание специальных программ":  &job.ЗнаниеПрограмм,
-		"Навыки работы на компьютере":  &job.НавыкиКомпьютера,
-		"Знание иностранных языков":    &job.ИностранныеЯзыки,
-		"Уровень владения языка":       &job.УровеньЯзыка,
-		"Наличие командировок":         &job.Командировки,
-		"Дополнительная информация":    &job.ДопИнформация,
-	}
-
-	var currentKey *string
-	var buffer []string
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		if ptr, ok := keyMap[line]; ok {
-			if currentKey != nil {
-				*currentKey = strings.Join(buffer, " ")
-			}
-			currentKey = ptr
-			buffer = nil
-		} else if currentKey != nil {
-			buffer = append(buffer, line)
-		}
+		return "", fmt.Errorf("failed to parse docx file: %w", err)
 	}
 
-	if currentKey != nil {
-		*currentKey = strings.Join(buffer, " ")
-	}
-
-	return job, nil
+	return text, nil
 }
 
 //	func extractResumeFromDocx(file io.Reader) (string, error) {
@@ -183,9 +114,25 @@ func detectFileType(data []byte) FileType {
 		return FileTypeUnknown
 	}
 
-	// DOCX файлы начинаются с ZIP signature
+	// DOCX и ODT файлы начинаются с ZIP signature, различаем по содержимому архива
 	if bytes.Equal(data[:4], []byte{0x50, 0x4B, 0x03, 0x04}) {
-		return FileTypeDOCX
+		mimeType, hasWordDocument := inspectZipDocument(data)
+		switch {
+		case hasWordDocument:
+			return FileTypeDOCX
+		case mimeType == odtMimeType:
+			return FileTypeODT
+		default:
+			return FileTypeUnknown
+		}
+	}
+
+	// DOC (Word 97-2003) - составной файл OLE2 с потоком WordDocument
+	if bytes.HasPrefix(data, oleSignature) {
+		if isWordOLE(data) {
+			return FileTypeDOC
+		}
+		return FileTypeUnknown
 	}
 
 	// PDF файлы начинаются с "%PDF"
@@ -193,6 +140,11 @@ func detectFileType(data []byte) FileType {
 		return FileTypePDF
 	}
 
+	// RTF - текстовый формат, поэтому проверяем до TXT
+	if bytes.HasPrefix(data, []byte("{\\rtf")) {
+		return FileTypeRTF
+	}
+
 	// Проверяем на текстовый файл
 	if isTextFile(data) {
 		return FileTypeTXT
@@ -227,16 +179,10 @@ func isTextFile(data []byte) bool {
 
 // getFileTypeName возвращает название типа файла
 func getFileTypeName(fileType FileType) string {
-	switch fileType {
-	case FileTypeDOCX:
-		return "DOCX"
-	case FileTypePDF:
-		return "PDF"
-	case FileTypeTXT:
-		return "TXT"
-	default:
-		return "Unknown"
+	if format, ok := lookupFormat(fileType); ok {
+		return format.Name
 	}
+	return "Unknown"
 }
 
 // ExtractTextFromFile извлекает текст из файла с использованием только бесплатных библиотек
@@ -249,18 +195,12 @@ func ExtractTextFromFile(fileData []byte, filename string) (string, error) {
 
 	log.Printf("🔍 Обнаружен тип файла: %s для %s", getFileTypeName(fileType), filename)
 
-	switch fileType {
-	case FileTypeDOCX:
-		return extractFromDOCXFree(fileData)
-	case FileTypePDF:
-		//return extractFromPDFFree(fileData)
-		return "", fmt.Errorf("неподдерживаемый формат файла: %s", getFileTypeName(fileType))
-
-	case FileTypeTXT:
-		return extractFromTXT(fileData)
-	default:
+	format, ok := lookupFormat(fileType)
+	if !ok {
 		return "", fmt.Errorf("неподдерживаемый формат файла: %s", getFileTypeName(fileType))
 	}
+
+	return format.Extract(fileData)
 }
 
 // extractFromDOCXFree извлекает текст из DOCX используя бесплатные библиотеки
@@ -358,26 +298,3 @@ func parseDocumentXML(xmlContent []byte) string {
 
 	return strings.TrimSpace(result.String())
 }
-
-// extractFromPDFFree извлекает текст из PDF используя бесплатные библиотеки
-//func extractFromPDFFree(fileData []byte) (string, error) {
-//	tempFile, err := os.CreateTemp("", "extract_*.pdf")
-//	if err != nil {
-//		return "", err
-//	}
-//	defer os.Remove(tempFile.Name())
-//	defer tempFile.Close()
-//
-//	if _, err := tempFile.Write(fileData); err != nil {
-//		return "", err
-//	}
-//	tempFile.Close()
-//
-//	// Извлекаем текст
-//	text, err := api.ExtractTextFile(tempFile.Name(), nil, nil)
-//	if err != nil {
-//		return "", fmt.Errorf("pdfcpu extraction failed: %w", err)
-//	}
-//
-//	return strings.TrimSpace(string(text)), nil
-//}
@@ -3,11 +3,16 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"interview/internal/models"
 	"interview/internal/repository"
 )
@@ -16,8 +21,24 @@ const (
 	InterviewLinkDuration = 7 * 24 * time.Hour // Неделя
 )
 
+var (
+	ErrInvalidInterview      = errors.New("invalid interview")
+	ErrInterviewStateChanged = errors.New("interview cannot change state")
+)
+
 type InterviewService interface {
-	CreateInterview(ctx context.Context, interview *models.Interview, baseURL string) error // Убрал scheduledTime
+	// CreateInterview создает собеседование и заполняет interview.URL - ссылку кандидата.
+	// Ссылка показывается один раз: в базе хранится только хэш токена.
+	CreateInterview(ctx context.Context, interview *models.Interview) error
+	// CreateInvitedInterview создает собеседование вместе с переводом резюме по change и письмом,
+	// которое invitation собирает по готовой ссылке, - одной транзакцией.
+	// Если статус резюме уже не change.FromStatus, возвращает repository.ErrStatusChanged.
+	CreateInvitedInterview(
+		ctx context.Context,
+		interview *models.Interview,
+		change *models.ResumeStatusHistory,
+		invitation func(interview *models.Interview) (*models.EmailMessage, error),
+	) error
 	StartInterview(ctx context.Context, token string) error
 	FinishInterview(ctx context.Context, token string) error
 	GetInterview(ctx context.Context, id string) (*models.Interview, error)
@@ -25,50 +46,155 @@ type InterviewService interface {
 	IsInterviewAccessible(ctx context.Context, token string) (bool, string, error)
 	// Дополнительные полезные методы
 	GetInterviewsByVacancy(ctx context.Context, vacancyID string) ([]*models.Interview, error)
+	// CancelInterview отменяет еще не завершенное собеседование, ссылка кандидата перестает работать
+	CancelInterview(ctx context.Context, id string) (*models.Interview, error)
+	// ReissueLink выпускает новую ссылку ожидающего собеседования и продлевает срок. Старая ссылка перестает работать.
+	ReissueLink(ctx context.Context, id string) (*models.Interview, error)
+	// ReissueLinkWithInvitation выпускает новую ссылку и вместе с ней ставит в очередь письмо,
+	// собранное invitation. Неотправленные приглашения со старой ссылкой отменяются.
+	ReissueLinkWithInvitation(
+		ctx context.Context,
+		id string,
+		invitation func(interview *models.Interview) (*models.EmailMessage, error),
+	) (*models.EmailMessage, error)
+	// RevokeLink отзывает ссылку кандидата, не меняя статус собеседования
+	RevokeLink(ctx context.Context, id string) (*models.Interview, error)
 }
 
 type interviewService struct {
-	repo repository.InterviewRepository
+	repo      repository.InterviewRepository
+	vacancies repository.VacancyRepository
+	resumes   repository.ResumeRepository
+	resumeSvc ResumeService
+	chat      ChatService
+	publicURL string
+	calendar  InterviewCalendarService
+}
+
+// NewInterviewService - publicURL - адрес сервиса, как его видит кандидат, из него собираются ссылки
+func NewInterviewService(
+	repo repository.InterviewRepository,
+	vacancies repository.VacancyRepository,
+	resumes repository.ResumeRepository,
+	resumeSvc ResumeService,
+	chat ChatService,
+	publicURL string,
+	calendar InterviewCalendarService,
+) InterviewService {
+	return &interviewService{
+		repo:      repo,
+		vacancies: vacancies,
+		resumes:   resumes,
+		resumeSvc: resumeSvc,
+		chat:      chat,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		calendar:  calendar,
+	}
+}
+
+func (s *interviewService) CreateInterview(ctx context.Context, interview *models.Interview) error {
+	token, err := s.prepare(ctx, interview)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, interview); err != nil {
+		return err
+	}
+	interview.URL = s.interviewURL(token)
+	return nil
 }
 
-func NewInterviewService(repo repository.InterviewRepository) InterviewService {
-	return &interviewService{repo: repo}
+func (s *interviewService) CreateInvitedInterview(
+	ctx context.Context,
+	interview *models.Interview,
+	change *models.ResumeStatusHistory,
+	invitation func(interview *models.Interview) (*models.EmailMessage, error),
+) error {
+	token, err := s.prepare(ctx, interview)
+	if err != nil {
+		return err
+	}
+	// Письмо ссылается на собеседование, поэтому id назначается до сохранения
+	if interview.ID == "" {
+		interview.ID = uuid.NewString()
+	}
+	interview.URL = s.interviewURL(token)
+
+	msg, err := invitation(interview)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateInvited(ctx, interview, change, msg)
 }
 
-func (s *interviewService) CreateInterview(ctx context.Context, interview *models.Interview, baseURL string) error {
+// prepare проверяет собеседование, выпускает токен ссылки и задает срок. Возвращает токен.
+func (s *interviewService) prepare(ctx context.Context, interview *models.Interview) (string, error) {
 	if interview.VacancyID == "" {
-		return errors.New("vacancy_id is required")
+		return "", fmt.Errorf("%w: vacancy_id is required", ErrInvalidInterview)
+	}
+	vacancy, err := s.vacancies.GetByID(ctx, interview.VacancyID)
+	if err != nil {
+		return "", fmt.Errorf("vacancy not found: %w", err)
+	}
+	if interview.ResumeID != nil {
+		resume, err := s.resumes.GetByID(ctx, *interview.ResumeID)
+		if err != nil {
+			return "", fmt.Errorf("resume not found: %w", err)
+		}
+		if resume.VacancyID != interview.VacancyID {
+			return "", fmt.Errorf("%w: resume belongs to another vacancy", ErrInvalidInterview)
+		}
 	}
 
-	// Генерируем токен
 	token := generateToken()
-	fullURL := fmt.Sprintf("%s/api/interview/%s", baseURL, token)
-	interview.URLToken = &fullURL
-	interview.Status = "pending"
+	tokenHash := hashToken(token)
+	interview.TokenHash = &tokenHash
+	interview.Status = models.InterviewStatusPending
 
 	// Ссылка действует неделю с момента создания
 	now := time.Now()
-	interview.ScheduledAt = &now // Доступна сразу
+	if !vacancy.RequiresSlot {
+		interview.ScheduledAt = &now // Доступна сразу
+	}
+	// Иначе время назначит кандидат, выбрав окно: InterviewSlotService.BookSlot
 
 	expirationTime := now.Add(InterviewLinkDuration) // Неделя
 	interview.ExpiresAt = &expirationTime
-
-	return s.repo.Create(ctx, interview)
+	return token, nil
 }
 
 func (s *interviewService) IsInterviewAccessible(ctx context.Context, token string) (bool, string, error) {
-	interview, err := s.repo.GetByToken(ctx, token)
+	interview, err := s.getByToken(ctx, token)
 	if err != nil {
 		return false, "Interview not found", err
 	}
 
-	// Проверяем статус - если завершен, недоступен
-	if interview.Status == "finished" {
+	// Проверяем статус - если завершен или отменен, недоступен
+	if interview.Status == models.InterviewStatusFinished {
 		return false, "Interview has already been completed", nil
 	}
+	if interview.Status == models.InterviewStatusCancelled {
+		return false, "Interview has been cancelled", nil
+	}
+	if interview.Status == models.InterviewStatusExpired {
+		return false, "Interview link has expired", nil
+	}
+	// Начатое собеседование доводится до конца, брошенное закрывает InterviewSweeper
+	if interview.Status != models.InterviewStatusPending {
+		return true, "Interview is accessible", nil
+	}
+
+	// Собеседование открывается только в выбранное окно, ExpiresAt тогда - конец окна
+	now := time.Now()
+	if interview.ScheduledAt == nil {
+		return false, "Choose an interview time slot first", nil
+	}
+	if now.Before(*interview.ScheduledAt) {
+		return false, "Interview opens at " + interview.ScheduledAt.Format(time.RFC3339), nil
+	}
 
 	// Проверяем срок действия
-	if interview.ExpiresAt != nil && time.Now().After(*interview.ExpiresAt) {
+	if interview.ExpiresAt != nil && now.After(*interview.ExpiresAt) {
 		return false, "Interview link has expired", nil
 	}
 
@@ -80,7 +206,7 @@ func (s *interviewService) GetInterviewByToken(ctx context.Context, token string
 		return nil, errors.New("token cannot be empty")
 	}
 
-	interview, err := s.repo.GetByToken(ctx, token)
+	interview, err := s.getByToken(ctx, token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get interview: %w", err)
 	}
@@ -98,37 +224,63 @@ func (s *interviewService) StartInterview(ctx context.Context, token string) err
 		return errors.New(message)
 	}
 
-	interview, err := s.repo.GetByToken(ctx, token)
+	interview, err := s.getByToken(ctx, token)
 	if err != nil {
 		return fmt.Errorf("failed to get interview: %w", err)
 	}
 
-	if interview.Status != "pending" {
-		return fmt.Errorf("interview cannot be started from status: %s", interview.Status)
+	// Условный переход: из двух одновременных запусков успешно только один
+	ok, err := s.repo.StartIfPending(ctx, interview.ID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to start interview: %w", err)
 	}
-
-	// Обновляем статус и фактическое время начала
-	interview.Status = "started"
-	now := time.Now()
-	interview.StartedAt = &now
-
-	return s.repo.Update(ctx, interview)
+	if !ok {
+		return fmt.Errorf("%w: cannot be started from status %s", ErrInterviewStateChanged, interview.Status)
+	}
+	return nil
 }
 
 func (s *interviewService) FinishInterview(ctx context.Context, token string) error {
-	interview, err := s.repo.GetByToken(ctx, token)
+	interview, err := s.getByToken(ctx, token)
 	if err != nil {
 		return fmt.Errorf("failed to get interview: %w", err)
 	}
 
-	if interview.Status != "started" {
-		return fmt.Errorf("interview cannot be finished from status: %s", interview.Status)
+	// Условный переход: из двух одновременных завершений успешно только одно
+	ok, err := s.repo.TransitionStatus(ctx, interview.ID, models.InterviewStatusStarted, models.InterviewStatusFinished)
+	if err != nil {
+		return fmt.Errorf("failed to finish interview: %w", err)
 	}
+	if !ok {
+		return fmt.Errorf("%w: cannot be finished from status %s", ErrInterviewStateChanged, interview.Status)
+	}
+	interview.Status = models.InterviewStatusFinished
+
+	s.closeChat(interview)
+	s.changeResumeStatus(ctx, interview, StatusChange{
+		To:     models.ResumeStatusInterviewed,
+		Actor:  models.ResumeActorInterview,
+		Reason: "interview " + interview.ID + " finished by candidate",
+	})
+	return nil
+}
 
-	// Обновляем статус на завершен
-	interview.Status = "finished"
+// closeChat сохраняет переписку и сообщает открытым вкладкам кандидата о завершении
+func (s *interviewService) closeChat(interview *models.Interview) {
+	if err := s.chat.CloseSession(interview.ID); err != nil && !errors.Is(err, ErrChatSessionNotFound) {
+		log.Printf("⚠️ Failed to close chat of interview %s: %v", interview.ID, err)
+	}
+}
 
-	return s.repo.Update(ctx, interview)
+// changeResumeStatus переводит резюме собеседования. Собеседование уже сохранено, поэтому ошибка только пишется в лог.
+func (s *interviewService) changeResumeStatus(ctx context.Context, interview *models.Interview, change StatusChange) {
+	if interview.ResumeID == nil {
+		return
+	}
+	err := s.resumeSvc.ChangeStatus(ctx, *interview.ResumeID, change)
+	if err != nil && !errors.Is(err, ErrInvalidStatusTransition) {
+		log.Printf("⚠️ Failed to update resume %s after interview %s: %v", *interview.ResumeID, interview.ID, err)
+	}
 }
 
 func (s *interviewService) GetInterview(ctx context.Context, id string) (*models.Interview, error) {
@@ -145,10 +297,48 @@ func (s *interviewService) GetInterviewsByVacancy(ctx context.Context, vacancyID
 	return s.repo.GetByVacancyID(ctx, vacancyID)
 }
 
+func (s *interviewService) CancelInterview(ctx context.Context, id string) (*models.Interview, error) {
+	interview, err := s.GetInterview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if interview.Status != models.InterviewStatusPending && interview.Status != models.InterviewStatusStarted {
+		return nil, fmt.Errorf("%w: cannot be cancelled from status %s", ErrInterviewStateChanged, interview.Status)
+	}
+
+	// Кандидат мог начать или завершить собеседование между чтением и отменой
+	from := interview.Status
+	ok, err := s.repo.TransitionStatus(ctx, interview.ID, from, models.InterviewStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel interview: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: status changed from %s", ErrInterviewStateChanged, from)
+	}
+	interview.Status = models.InterviewStatusCancelled
+
+	if from == models.InterviewStatusStarted {
+		s.closeChat(interview)
+	}
+	// Собеседования не будет - резюме возвращается к HR
+	s.changeResumeStatus(ctx, interview, StatusChange{
+		To:     models.ResumeStatusAnalyzed,
+		Actor:  models.ResumeActorInterview,
+		Reason: "interview " + interview.ID + " cancelled",
+	})
+
+	// Отмена уже сохранена, письмо кандидату не должно ее откатывать
+	if err := s.calendar.Cancelled(ctx, interview); err != nil {
+		log.Printf("⚠️ Failed to queue cancellation of interview %s: %v", interview.ID, err)
+	}
+	return interview, nil
+}
+
 // Дополнительные utility методы
 
 func (s *interviewService) GetTimeUntilExpiry(ctx context.Context, token string) (time.Duration, error) {
-	interview, err := s.repo.GetByToken(ctx, token)
+	interview, err := s.getByToken(ctx, token)
 	if err != nil {
 		return 0, err
 	}
@@ -166,7 +356,7 @@ func (s *interviewService) GetTimeUntilExpiry(ctx context.Context, token string)
 }
 
 func (s *interviewService) IsInterviewActive(ctx context.Context, token string) (bool, error) {
-	interview, err := s.repo.GetByToken(ctx, token)
+	interview, err := s.getByToken(ctx, token)
 	if err != nil {
 		return false, err
 	}
@@ -179,7 +369,100 @@ func (s *interviewService) IsInterviewActive(ctx context.Context, token string)
 	}
 
 	// Проверяем статус
-	return interview.Status == "pending" || interview.Status == "started", nil
+	return interview.Status == models.InterviewStatusPending || interview.Status == models.InterviewStatusStarted, nil
+}
+
+func (s *interviewService) ReissueLink(ctx context.Context, id string) (*models.Interview, error) {
+	interview, err := s.GetInterview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt := newLink(interview)
+	ok, err := s.repo.ReissueToken(ctx, interview.ID, hashToken(token), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reissue link: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: link can be reissued only for pending interview, current status %s", ErrInterviewStateChanged, interview.Status)
+	}
+
+	interview.ExpiresAt = &expiresAt
+	interview.URL = s.interviewURL(token)
+	return interview, nil
+}
+
+func (s *interviewService) ReissueLinkWithInvitation(
+	ctx context.Context,
+	id string,
+	invitation func(interview *models.Interview) (*models.EmailMessage, error),
+) (*models.EmailMessage, error) {
+	interview, err := s.GetInterview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt := newLink(interview)
+	status := interview.Status
+	interview.ExpiresAt = &expiresAt
+	interview.URL = s.interviewURL(token)
+	msg, err := invitation(interview)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.ReissueWithInvitation(ctx, interview.ID, hashToken(token), expiresAt, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reissue link: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: link can be reissued only for pending interview, current status %s", ErrInterviewStateChanged, status)
+	}
+	return msg, nil
+}
+
+// newLink выпускает токен новой ссылки и ее срок
+func newLink(interview *models.Interview) (string, time.Time) {
+	expiresAt := time.Now().Add(InterviewLinkDuration)
+	if interview.SlotID != nil && interview.ExpiresAt != nil {
+		expiresAt = *interview.ExpiresAt // новая ссылка действует до конца выбранного окна
+	}
+	return generateToken(), expiresAt
+}
+
+func (s *interviewService) RevokeLink(ctx context.Context, id string) (*models.Interview, error) {
+	interview, err := s.GetInterview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.RevokeToken(ctx, interview.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke link: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: link cannot be revoked from status %s", ErrInterviewStateChanged, interview.Status)
+	}
+	return interview, nil
+}
+
+// getByToken ищет собеседование по хэшу токена. Сравнение в индексе идет по хэшу, а не по самому токену,
+// поэтому время ответа не подсказывает, насколько подобранный токен похож на существующий.
+func (s *interviewService) getByToken(ctx context.Context, token string) (*models.Interview, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	return s.repo.GetByTokenHash(ctx, hashToken(token))
+}
+
+func (s *interviewService) interviewURL(token string) string {
+	return s.publicURL + "/api/interview/" + token
+}
+
+// hashToken - в базе хранится только SHA-256 токена. Токен случайный и длинный, поэтому соль не нужна.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func generateToken() string {
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+var ErrInterviewNotScheduled = errors.New("interview has no scheduled time")
+
+// InterviewCalendarConfig - настройки календарных писем
+type InterviewCalendarConfig struct {
+	ReminderOffsets []time.Duration // за сколько до начала отправлять напоминания
+	Organizer       string          // адрес отправителя писем, ORGANIZER события
+}
+
+var DefaultInterviewCalendarConfig = InterviewCalendarConfig{
+	ReminderOffsets: []time.Duration{24 * time.Hour, time.Hour},
+}
+
+// InterviewCalendarService - календарь собеседований, время которых выбрал кандидат:
+// файл .ics, письма о записи, переносе и отмене и напоминания перед началом
+type InterviewCalendarService interface {
+	// Event - событие назначенного собеседования. false, если время не выбрано.
+	Event(interview *models.Interview, vacancyTitle, attendee, url string) (CalendarEvent, bool)
+	// CalendarByToken - файл .ics собеседования для кандидата
+	CalendarByToken(ctx context.Context, token string) ([]byte, error)
+	// Scheduled ставит в очередь подтверждение записи с .ics и напоминания, отменяя напоминания о прежнем времени.
+	// token - токен ссылки кандидата, с которым он записался: сам токен не хранится.
+	Scheduled(ctx context.Context, interview *models.Interview, token string) error
+	// Cancelled отменяет напоминания и отправляет кандидату отмену события
+	Cancelled(ctx context.Context, interview *models.Interview) error
+}
+
+type interviewCalendarService struct {
+	interviews repository.InterviewRepository
+	vacancies  repository.VacancyRepository
+	resumes    repository.ResumeRepository
+	outbox     repository.EmailOutboxRepository
+	publicURL  string
+	cfg        InterviewCalendarConfig
+}
+
+func NewInterviewCalendarService(
+	interviews repository.InterviewRepository,
+	vacancies repository.VacancyRepository,
+	resumes repository.ResumeRepository,
+	outbox repository.EmailOutboxRepository,
+	publicURL string,
+	cfg InterviewCalendarConfig,
+) InterviewCalendarService {
+	return &interviewCalendarService{
+		interviews: interviews,
+		vacancies:  vacancies,
+		resumes:    resumes,
+		outbox:     outbox,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		cfg:        cfg,
+	}
+}
+
+func (s *interviewCalendarService) Event(interview *models.Interview, vacancyTitle, attendee, url string) (CalendarEvent, bool) {
+	if interview.SlotID == nil || interview.ScheduledAt == nil || interview.ExpiresAt == nil {
+		return CalendarEvent{}, false
+	}
+
+	description := "AI-собеседование на позицию " + vacancyTitle + ". "
+	if url != "" {
+		description += "Ссылка на собеседование: " + url
+	} else {
+		description += "Откройте собеседование по ссылке из письма-приглашения."
+	}
+	return CalendarEvent{
+		UID:         interview.ID + "@interview.ai-hr",
+		Sequence:    interview.RescheduleCount, // каждый перенос - новая версия события
+		Start:       *interview.ScheduledAt,
+		End:         *interview.ExpiresAt,
+		Summary:     "Собеседование: " + vacancyTitle,
+		Description: description,
+		URL:         url,
+		Organizer:   s.cfg.Organizer,
+		Attendee:    attendee,
+	}, true
+}
+
+func (s *interviewCalendarService) CalendarByToken(ctx context.Context, token string) ([]byte, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	interview, err := s.interviews.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	vacancy, err := s.vacancies.GetByID(ctx, interview.VacancyID)
+	if err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+
+	event, ok := s.Event(interview, vacancy.Title, "", s.interviewURL(token))
+	if !ok {
+		return nil, ErrInterviewNotScheduled
+	}
+	if interview.Status == models.InterviewStatusCancelled {
+		event.Sequence++
+		event.Cancelled = true
+	}
+	return event.ICS(), nil
+}
+
+func (s *interviewCalendarService) Scheduled(ctx context.Context, interview *models.Interview, token string) error {
+	vacancy, to, err := s.recipient(ctx, interview)
+	if err != nil || to == "" {
+		return err
+	}
+
+	url := ""
+	if token != "" {
+		url = s.interviewURL(token)
+	}
+	event, ok := s.Event(interview, vacancy.Title, to, url)
+	if !ok {
+		return ErrInterviewNotScheduled
+	}
+
+	// Напоминания о прежнем времени больше не нужны
+	if _, err := s.outbox.CancelQueued(ctx, interview.ID, models.EmailKindReminder); err != nil {
+		return fmt.Errorf("failed to cancel reminders: %w", err)
+	}
+
+	data := ScheduleMail{
+		VacancyTitle:          vacancy.Title,
+		InterviewURL:          url,
+		StartsAt:              event.Start,
+		EndsAt:                event.End,
+		Rescheduled:           interview.RescheduleCount > 0,
+		ReschedulesLeft:       InterviewMaxReschedules - interview.RescheduleCount,
+		RescheduleCutoffHours: int(InterviewRescheduleCutoff / time.Hour),
+	}
+	mail, err := RenderScheduled(to, data)
+	if err != nil {
+		return err
+	}
+	if err := s.enqueue(ctx, interview, models.EmailKindScheduled, mail, &event, time.Time{}); err != nil {
+		return err
+	}
+
+	// Напоминание, время которого уже прошло, не отправляется
+	data.InterviewURL = ""
+	now := time.Now()
+	for _, offset := range s.cfg.ReminderOffsets {
+		at := event.Start.Add(-offset)
+		if !at.After(now) {
+			continue
+		}
+		reminder, err := RenderReminder(to, data)
+		if err != nil {
+			return err
+		}
+		if err := s.enqueue(ctx, interview, models.EmailKindReminder, reminder, nil, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *interviewCalendarService) Cancelled(ctx context.Context, interview *models.Interview) error {
+	if interview.SlotID == nil {
+		return nil
+	}
+	if _, err := s.outbox.CancelQueued(ctx, interview.ID, models.EmailKindReminder); err != nil {
+		return fmt.Errorf("failed to cancel reminders: %w", err)
+	}
+	// Окно уже прошло - событие в календаре кандидата ничему не мешает
+	if interview.ExpiresAt == nil || interview.ExpiresAt.Before(time.Now()) {
+		return nil
+	}
+
+	vacancy, to, err := s.recipient(ctx, interview)
+	if err != nil || to == "" {
+		return err
+	}
+	event, ok := s.Event(interview, vacancy.Title, to, "")
+	if !ok {
+		return nil
+	}
+	event.Sequence++
+	event.Cancelled = true
+
+	mail, err := RenderCancellation(to, ScheduleMail{VacancyTitle: vacancy.Title, StartsAt: event.Start, EndsAt: event.End})
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, interview, models.EmailKindCancellation, mail, &event, time.Time{})
+}
+
+// recipient - вакансия и почта кандидата. Пустая почта - писать некому.
+func (s *interviewCalendarService) recipient(ctx context.Context, interview *models.Interview) (*models.Vacancy, string, error) {
+	if interview.ResumeID == nil {
+		return nil, "", nil
+	}
+	resume, err := s.resumes.GetByID(ctx, *interview.ResumeID)
+	if err != nil {
+		return nil, "", fmt.Errorf("resume not found: %w", err)
+	}
+	if resume.Mail == "" {
+		return nil, "", nil
+	}
+	vacancy, err := s.vacancies.GetByID(ctx, interview.VacancyID)
+	if err != nil {
+		return nil, "", fmt.Errorf("vacancy not found: %w", err)
+	}
+	return vacancy, resume.Mail, nil
+}
+
+// enqueue ставит письмо в очередь. Нулевое sendAt - отправить сразу.
+func (s *interviewCalendarService) enqueue(ctx context.Context, interview *models.Interview, kind string, mail MailMessage, event *CalendarEvent, sendAt time.Time) error {
+	msg := &models.EmailMessage{
+		Kind:        kind,
+		InterviewID: &interview.ID,
+		VacancyID:   &interview.VacancyID,
+		ResumeID:    interview.ResumeID,
+		Recipient:   mail.To,
+		Subject:     mail.Subject,
+		HTMLBody:    mail.HTMLBody,
+		NextRunAt:   sendAt,
+	}
+	if event != nil {
+		msg.CalendarICS, msg.CalendarMethod = string(event.ICS()), event.Method()
+	}
+	if err := s.outbox.Enqueue(ctx, msg); err != nil {
+		return fmt.Errorf("failed to queue %s email: %w", kind, err)
+	}
+	return nil
+}
+
+func (s *interviewCalendarService) interviewURL(token string) string {
+	return s.publicURL + "/api/interview/" + token
+}
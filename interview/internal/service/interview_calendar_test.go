@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+)
+
+// calendarWithoutMail - календарь для собеседований без выбранного времени или без кандидата:
+// такие собеседования не обращаются к репозиториям
+func calendarWithoutMail() InterviewCalendarService {
+	return NewInterviewCalendarService(nil, nil, nil, nil, "", DefaultInterviewCalendarConfig)
+}
+
+func TestCalendarEvent_ICS(t *testing.T) {
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	event := CalendarEvent{
+		UID:         "interview@interview.ai-hr",
+		Sequence:    2,
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Собеседование: Go-разработчик; backend, senior",
+		Description: strings.Repeat("Длинное описание собеседования. ", 5),
+		Organizer:   "hr@example.com",
+		Attendee:    "candidate@example.com",
+	}
+
+	t.Run("приглашение", func(t *testing.T) {
+		ics := string(event.ICS())
+		assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+		assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+		assert.Contains(t, ics, "METHOD:REQUEST\r\n")
+		assert.Contains(t, ics, "SEQUENCE:2\r\n")
+		assert.Contains(t, ics, "DTSTART:20260310T090000Z\r\n", "время в UTC")
+		assert.Contains(t, ics, "DTEND:20260310T100000Z\r\n")
+		unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+		assert.Contains(t, unfolded, `SUMMARY:Собеседование: Go-разработчик\; backend\, senior`)
+		assert.Contains(t, unfolded, "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;RSVP=FALSE:mailto:candidate@example.com\r\n")
+		assert.Contains(t, ics, "STATUS:CONFIRMED\r\n")
+
+		for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), 75, "строка не перенесена: %q", line)
+		}
+	})
+
+	t.Run("отмена", func(t *testing.T) {
+		cancelled := event
+		cancelled.Cancelled = true
+		ics := string(cancelled.ICS())
+		assert.Equal(t, CalendarMethodCancel, cancelled.Method())
+		assert.Contains(t, ics, "METHOD:CANCEL\r\n")
+		assert.Contains(t, ics, "STATUS:CANCELLED\r\n")
+	})
+}
+
+func TestFoldICSLine_KeepsRunes(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("я", 100)
+	folded := foldICSLine(line)
+	assert.Equal(t, line, strings.ReplaceAll(folded, "\r\n ", ""))
+	for _, part := range strings.Split(folded, "\r\n") {
+		assert.LessOrEqual(t, len(part), 75)
+		assert.True(t, utf8.ValidString(part), "символ разорван: %q", part)
+	}
+}
+
+func TestInterviewCalendarService_Scheduled(t *testing.T) {
+	resumeID, slotID := "resume", "slot"
+	vacancy := &models.Vacancy{ID: "vacancy", Title: "Go-разработчик"}
+	resume := &models.Resume{ID: resumeID, VacancyID: "vacancy", Mail: "candidate@example.com"}
+
+	tests := []struct {
+		name          string
+		startsIn      time.Duration
+		reschedules   int
+		wantReminders int
+	}{
+		{name: "запись за несколько дней", startsIn: 72 * time.Hour, wantReminders: 2},
+		{name: "запись меньше чем за сутки", startsIn: 5 * time.Hour, wantReminders: 1},
+		{name: "запись меньше чем за час", startsIn: 30 * time.Minute},
+		{name: "перенос", startsIn: 72 * time.Hour, reschedules: 1, wantReminders: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now().Add(tt.startsIn)
+			end := start.Add(time.Hour)
+			interview := &models.Interview{
+				ID: "interview", VacancyID: "vacancy", ResumeID: &resumeID, Status: models.InterviewStatusPending,
+				SlotID: &slotID, ScheduledAt: &start, ExpiresAt: &end, RescheduleCount: tt.reschedules,
+			}
+			outbox := &fakeEmailOutbox{}
+			// Напоминание о прежнем времени
+			outbox.queued = append(outbox.queued, &models.EmailMessage{Kind: models.EmailKindReminder, Status: models.EmailStatusQueued})
+
+			calendar := NewInterviewCalendarService(nil, &fakeRankingVacancies{vacancy: vacancy}, &fakeInterviewResumes{resume: resume},
+				outbox, "https://hr.example.com", DefaultInterviewCalendarConfig)
+			require.NoError(t, calendar.Scheduled(context.Background(), interview, "token"))
+
+			assert.Equal(t, models.EmailStatusCancelled, outbox.queued[0].Status, "старое напоминание отменено")
+			queued := outbox.queued[1:]
+			require.Len(t, queued, 1+tt.wantReminders)
+
+			confirmation := queued[0]
+			assert.Equal(t, models.EmailKindScheduled, confirmation.Kind)
+			assert.Equal(t, CalendarMethodRequest, confirmation.CalendarMethod)
+			assert.Contains(t, confirmation.CalendarICS, "UID:interview@interview.ai-hr")
+			assert.Contains(t, confirmation.CalendarICS, fmt.Sprintf("SEQUENCE:%d\r\n", tt.reschedules))
+			assert.Contains(t, confirmation.HTMLBody, "https://hr.example.com/api/interview/token")
+			assert.True(t, confirmation.NextRunAt.IsZero(), "подтверждение отправляется сразу")
+
+			for _, reminder := range queued[1:] {
+				assert.Equal(t, models.EmailKindReminder, reminder.Kind)
+				assert.True(t, reminder.NextRunAt.After(time.Now()))
+				assert.True(t, reminder.NextRunAt.Before(start))
+				assert.Empty(t, reminder.CalendarICS)
+			}
+		})
+	}
+}
+
+func TestInterviewCalendarService_Cancelled(t *testing.T) {
+	resumeID, slotID := "resume", "slot"
+	start := time.Now().Add(48 * time.Hour)
+	end := start.Add(time.Hour)
+	interview := &models.Interview{
+		ID: "interview", VacancyID: "vacancy", ResumeID: &resumeID, Status: models.InterviewStatusCancelled,
+		SlotID: &slotID, ScheduledAt: &start, ExpiresAt: &end, RescheduleCount: 1,
+	}
+	outbox := &fakeEmailOutbox{}
+	outbox.queued = append(outbox.queued, &models.EmailMessage{Kind: models.EmailKindReminder, Status: models.EmailStatusQueued})
+
+	calendar := NewInterviewCalendarService(nil,
+		&fakeRankingVacancies{vacancy: &models.Vacancy{ID: "vacancy", Title: "Go-разработчик"}},
+		&fakeInterviewResumes{resume: &models.Resume{ID: resumeID, Mail: "candidate@example.com"}},
+		outbox, "", DefaultInterviewCalendarConfig)
+	require.NoError(t, calendar.Cancelled(context.Background(), interview))
+
+	assert.Equal(t, models.EmailStatusCancelled, outbox.queued[0].Status)
+	require.Len(t, outbox.queued, 2)
+	cancellation := outbox.queued[1]
+	assert.Equal(t, models.EmailKindCancellation, cancellation.Kind)
+	assert.Equal(t, CalendarMethodCancel, cancellation.CalendarMethod)
+	assert.Contains(t, cancellation.CalendarICS, "SEQUENCE:2\r\n", "версия выше последнего переноса")
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"time"
+
+	"interview/internal/models"
+)
+
+// Верхние границы ограничений, которые HR может задать вакансии
+const (
+	MaxInterviewMinutes   = 240
+	MaxInterviewQuestions = 100
+)
+
+// Причины принудительного завершения собеседования. Передаются AI вместе с запросом итога.
+const (
+	FinishReasonTimeLimit     = "time_limit"
+	FinishReasonQuestionLimit = "question_limit"
+	FinishReasonIdle          = "idle_timeout"
+)
+
+// InterviewPolicy - ограничения собеседования. Нулевое значение поля - ограничения нет.
+type InterviewPolicy struct {
+	MaxDuration  time.Duration // сколько длится собеседование с начала
+	MaxQuestions int           // сколько вопросов задает AI до итогового результата
+	IdleTimeout  time.Duration // сколько ждать ответа кандидата
+}
+
+var DefaultInterviewPolicy = InterviewPolicy{
+	MaxDuration:  30 * time.Minute,
+	MaxQuestions: 15,
+	IdleTimeout:  30 * time.Minute,
+}
+
+// ForVacancy - ограничения с учетом настроек вакансии
+func (p InterviewPolicy) ForVacancy(vacancy *models.Vacancy) InterviewPolicy {
+	if vacancy == nil {
+		return p
+	}
+	if v := vacancy.InterviewMaxMinutes; v != nil {
+		p.MaxDuration = time.Duration(*v) * time.Minute
+	}
+	if v := vacancy.InterviewMaxQuestions; v != nil {
+		p.MaxQuestions = *v
+	}
+	if v := vacancy.InterviewIdleMinutes; v != nil {
+		p.IdleTimeout = time.Duration(*v) * time.Minute
+	}
+	return p
+}
+
+// Deadline - когда истекает время собеседования, начатого в startedAt. nil - время не ограничено.
+func (p InterviewPolicy) Deadline(startedAt time.Time) *time.Time {
+	if p.MaxDuration <= 0 {
+		return nil
+	}
+	deadline := startedAt.Add(p.MaxDuration)
+	return &deadline
+}
+
+// Exceeded возвращает причину завершения, если сессия исчерпала ограничения к моменту now.
+// Idle-таймаут проверяет InterviewSweeper: пока кандидат пишет, он не истекает.
+func (p InterviewPolicy) Exceeded(session *models.ChatSession, now time.Time) string {
+	if deadline := p.Deadline(sessionStartedAt(session)); deadline != nil && !now.Before(*deadline) {
+		return FinishReasonTimeLimit
+	}
+	if p.MaxQuestions > 0 && countQuestions(session.Messages) >= p.MaxQuestions {
+		return FinishReasonQuestionLimit
+	}
+	return ""
+}
+
+// sessionStartedAt - начало собеседования; у собеседований без started_at - время первого сообщения
+func sessionStartedAt(session *models.ChatSession) time.Time {
+	if session.StartedAt != nil {
+		return *session.StartedAt
+	}
+	if len(session.Messages) > 0 {
+		return session.Messages[0].CreatedAt
+	}
+	return time.Now()
+}
+
+// countQuestions - сколько вопросов AI задал кандидату, включая приветствие
+func countQuestions(messages []models.ChatMessage) int {
+	count := 0
+	for _, msg := range messages {
+		if msg.Sender == "ai" && msg.Type == models.MessageTypeQuestion {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+const (
+	InterviewMaxReschedules   = 2             // сколько раз кандидат может перенести собеседование
+	InterviewRescheduleCutoff = 2 * time.Hour // перенос закрывается за это время до начала окна
+)
+
+var (
+	ErrInvalidSlot          = errors.New("invalid interview slot")
+	ErrSlotUnavailable      = errors.New("interview slot is not available")
+	ErrSlotBooked           = errors.New("interview slot has bookings")
+	ErrRescheduleNotAllowed = errors.New("interview cannot be rescheduled")
+)
+
+// InterviewSlotService - окна для собеседований, в которые кандидат записывается сам
+type InterviewSlotService interface {
+	CreateSlot(ctx context.Context, slot *models.InterviewSlot) error
+	// ListSlots - все окна вакансии с числом записанных кандидатов
+	ListSlots(ctx context.Context, vacancyID string) ([]*models.InterviewSlot, error)
+	// DeleteSlot удаляет окно, в которое еще никто не записан
+	DeleteSlot(ctx context.Context, vacancyID, slotID string) error
+	// AvailableSlots - будущие окна вакансии со свободными местами для кандидата с этой ссылкой
+	AvailableSlots(ctx context.Context, token string) ([]*models.InterviewSlot, error)
+	// BookSlot записывает кандидата в окно. Повторная запись в другое окно - перенос,
+	// их число ограничено InterviewMaxReschedules, и закрываются они за InterviewRescheduleCutoff до начала.
+	BookSlot(ctx context.Context, token, slotID string) (*models.Interview, error)
+}
+
+type interviewSlotService struct {
+	slots      repository.InterviewSlotRepository
+	interviews repository.InterviewRepository
+	vacancies  repository.VacancyRepository
+	calendar   InterviewCalendarService
+}
+
+func NewInterviewSlotService(
+	slots repository.InterviewSlotRepository,
+	interviews repository.InterviewRepository,
+	vacancies repository.VacancyRepository,
+	calendar InterviewCalendarService,
+) InterviewSlotService {
+	return &interviewSlotService{slots: slots, interviews: interviews, vacancies: vacancies, calendar: calendar}
+}
+
+func (s *interviewSlotService) CreateSlot(ctx context.Context, slot *models.InterviewSlot) error {
+	if _, err := s.vacancies.GetByID(ctx, slot.VacancyID); err != nil {
+		return fmt.Errorf("vacancy not found: %w", err)
+	}
+	if !slot.EndsAt.After(slot.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSlot)
+	}
+	if !slot.StartsAt.After(time.Now()) {
+		return fmt.Errorf("%w: slot must start in the future", ErrInvalidSlot)
+	}
+	if slot.Capacity == 0 {
+		slot.Capacity = 1
+	}
+	if slot.Capacity < 0 {
+		return fmt.Errorf("%w: capacity must be positive", ErrInvalidSlot)
+	}
+	return s.slots.Create(ctx, slot)
+}
+
+func (s *interviewSlotService) ListSlots(ctx context.Context, vacancyID string) ([]*models.InterviewSlot, error) {
+	if _, err := s.vacancies.GetByID(ctx, vacancyID); err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+	return s.slots.ListByVacancy(ctx, vacancyID, nil)
+}
+
+func (s *interviewSlotService) DeleteSlot(ctx context.Context, vacancyID, slotID string) error {
+	slot, err := s.slots.GetByID(ctx, slotID)
+	if err != nil {
+		return err
+	}
+	if slot.VacancyID != vacancyID {
+		return fmt.Errorf("%w: slot belongs to another vacancy", ErrInvalidSlot)
+	}
+
+	ok, err := s.slots.Delete(ctx, slot.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete slot: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: cancel or move the interviews first", ErrSlotBooked)
+	}
+	return nil
+}
+
+func (s *interviewSlotService) AvailableSlots(ctx context.Context, token string) ([]*models.InterviewSlot, error) {
+	interview, err := s.bookableInterview(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots, err := s.slots.ListByVacancy(ctx, interview.VacancyID, &now)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]*models.InterviewSlot, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Booked < slot.Capacity && (interview.SlotID == nil || slot.ID != *interview.SlotID) {
+			available = append(available, slot)
+		}
+	}
+	return available, nil
+}
+
+func (s *interviewSlotService) BookSlot(ctx context.Context, token, slotID string) (*models.Interview, error) {
+	interview, err := s.bookableInterview(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if interview.SlotID != nil && *interview.SlotID == slotID {
+		return interview, nil
+	}
+
+	slot, err := s.slots.GetByID(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if slot.VacancyID != interview.VacancyID || !slot.StartsAt.After(time.Now()) {
+		return nil, ErrSlotUnavailable
+	}
+
+	if interview.SlotID != nil {
+		if interview.RescheduleCount >= InterviewMaxReschedules {
+			return nil, fmt.Errorf("%w: reschedule limit of %d reached", ErrRescheduleNotAllowed, InterviewMaxReschedules)
+		}
+		if interview.ScheduledAt != nil && time.Until(*interview.ScheduledAt) < InterviewRescheduleCutoff {
+			return nil, fmt.Errorf("%w: less than %s left before the interview", ErrRescheduleNotAllowed, InterviewRescheduleCutoff)
+		}
+	}
+
+	ok, err := s.slots.Book(ctx, interview.ID, slot.ID, interview.SlotID)
+	if errors.Is(err, repository.ErrSlotFull) {
+		return nil, fmt.Errorf("%w: slot is full", ErrSlotUnavailable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to book slot: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: interview was changed, reload and try again", ErrInterviewStateChanged)
+	}
+
+	if interview.SlotID != nil {
+		interview.RescheduleCount++
+		log.Printf("📅 Interview %s rescheduled to slot %s (%d of %d)", interview.ID, slot.ID, interview.RescheduleCount, InterviewMaxReschedules)
+	} else {
+		log.Printf("📅 Interview %s booked for slot %s", interview.ID, slot.ID)
+	}
+	interview.SlotID = &slot.ID
+	interview.ScheduledAt = &slot.StartsAt
+	interview.ExpiresAt = &slot.EndsAt
+
+	// Запись уже сохранена: письмо с событием и напоминания не должны ее откатывать
+	if err := s.calendar.Scheduled(ctx, interview, token); err != nil {
+		log.Printf("⚠️ Failed to queue schedule emails for interview %s: %v", interview.ID, err)
+	}
+	return interview, nil
+}
+
+// bookableInterview - собеседование по ссылке кандидата, еще не начатое и с действующей ссылкой
+func (s *interviewSlotService) bookableInterview(ctx context.Context, token string) (*models.Interview, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	interview, err := s.interviews.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status != models.InterviewStatusPending {
+		return nil, fmt.Errorf("%w: interview is %s", ErrInterviewStateChanged, interview.Status)
+	}
+	if interview.ExpiresAt != nil && time.Now().After(*interview.ExpiresAt) {
+		return nil, fmt.Errorf("%w: interview link has expired", ErrInterviewStateChanged)
+	}
+	// Время уже назначено при создании - вакансия не использовала окна
+	if interview.SlotID == nil && interview.ScheduledAt != nil {
+		return nil, fmt.Errorf("%w: interview does not use time slots", ErrSlotUnavailable)
+	}
+	return interview, nil
+}
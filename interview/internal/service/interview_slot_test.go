@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeSlotRepository struct {
+	repository.InterviewSlotRepository
+	slots  map[string]*models.InterviewSlot
+	booked []string
+}
+
+func (r *fakeSlotRepository) GetByID(ctx context.Context, id string) (*models.InterviewSlot, error) {
+	return r.slots[id], nil
+}
+
+func (r *fakeSlotRepository) ListByVacancy(ctx context.Context, vacancyID string, from *time.Time) ([]*models.InterviewSlot, error) {
+	var slots []*models.InterviewSlot
+	for _, id := range []string{"morning", "evening", "full"} {
+		if slot := r.slots[id]; slot != nil {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+func (r *fakeSlotRepository) Book(ctx context.Context, interviewID string, slotID string, previousSlotID *string) (bool, error) {
+	slot := r.slots[slotID]
+	if slot.Booked >= slot.Capacity {
+		return false, repository.ErrSlotFull
+	}
+	r.booked = append(r.booked, slotID)
+	return true, nil
+}
+
+func TestInterviewSlotService_BookSlot(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	morning := "morning"
+
+	slots := func() map[string]*models.InterviewSlot {
+		return map[string]*models.InterviewSlot{
+			"morning": {ID: "morning", VacancyID: "vacancy", StartsAt: soon, EndsAt: soon.Add(time.Hour), Capacity: 1},
+			"evening": {ID: "evening", VacancyID: "vacancy", StartsAt: now.Add(10 * time.Hour), EndsAt: now.Add(11 * time.Hour), Capacity: 2},
+			"full":    {ID: "full", VacancyID: "vacancy", StartsAt: now.Add(10 * time.Hour), EndsAt: now.Add(11 * time.Hour), Capacity: 1, Booked: 1},
+			"past":    {ID: "past", VacancyID: "vacancy", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Capacity: 1},
+			"other":   {ID: "other", VacancyID: "other", StartsAt: now.Add(10 * time.Hour), EndsAt: now.Add(11 * time.Hour), Capacity: 1},
+		}
+	}
+	later := now.Add(20 * time.Hour)
+	evening := "evening"
+
+	tests := []struct {
+		name      string
+		interview models.Interview
+		slotID    string
+		wantErr   error
+	}{
+		{name: "первая запись", interview: models.Interview{Status: models.InterviewStatusPending}, slotID: "evening"},
+		{name: "окно заполнено", interview: models.Interview{Status: models.InterviewStatusPending}, slotID: "full", wantErr: ErrSlotUnavailable},
+		{name: "окно уже началось", interview: models.Interview{Status: models.InterviewStatusPending}, slotID: "past", wantErr: ErrSlotUnavailable},
+		{name: "окно другой вакансии", interview: models.Interview{Status: models.InterviewStatusPending}, slotID: "other", wantErr: ErrSlotUnavailable},
+		{name: "собеседование уже начато", interview: models.Interview{Status: models.InterviewStatusStarted}, slotID: "evening", wantErr: ErrInterviewStateChanged},
+		{name: "время назначено без окон", interview: models.Interview{Status: models.InterviewStatusPending, ScheduledAt: &now}, slotID: "evening", wantErr: ErrSlotUnavailable},
+		{
+			name:      "перенос",
+			interview: models.Interview{Status: models.InterviewStatusPending, SlotID: &evening, ScheduledAt: &later, RescheduleCount: 1},
+			slotID:    "morning",
+		},
+		{
+			name:      "переносы закончились",
+			interview: models.Interview{Status: models.InterviewStatusPending, SlotID: &evening, ScheduledAt: &later, RescheduleCount: InterviewMaxReschedules},
+			slotID:    "morning",
+			wantErr:   ErrRescheduleNotAllowed,
+		},
+		{
+			name:      "перенос незадолго до начала",
+			interview: models.Interview{Status: models.InterviewStatusPending, SlotID: &morning, ScheduledAt: &soon},
+			slotID:    "evening",
+			wantErr:   ErrRescheduleNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interview := tt.interview
+			interview.ID, interview.VacancyID = "interview", "vacancy"
+			slotRepo := &fakeSlotRepository{slots: slots()}
+			svc := NewInterviewSlotService(slotRepo, &fakeInterviewRepository{interview: &interview}, nil, calendarWithoutMail())
+
+			got, err := svc.BookSlot(context.Background(), "token", tt.slotID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, slotRepo.booked)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.slotID}, slotRepo.booked)
+			assert.Equal(t, tt.slotID, *got.SlotID)
+			assert.Equal(t, slotRepo.slots[tt.slotID].StartsAt, *got.ScheduledAt)
+			assert.Equal(t, slotRepo.slots[tt.slotID].EndsAt, *got.ExpiresAt, "ссылка действует до конца окна")
+
+			wantReschedules := tt.interview.RescheduleCount
+			if tt.interview.SlotID != nil {
+				wantReschedules++
+			}
+			assert.Equal(t, wantReschedules, got.RescheduleCount)
+		})
+	}
+}
+
+func TestInterviewSlotService_AvailableSlots(t *testing.T) {
+	now := time.Now()
+	evening := "evening"
+	slotRepo := &fakeSlotRepository{slots: map[string]*models.InterviewSlot{
+		"morning": {ID: "morning", StartsAt: now.Add(time.Hour), Capacity: 2, Booked: 1},
+		"evening": {ID: "evening", StartsAt: now.Add(10 * time.Hour), Capacity: 2},
+		"full":    {ID: "full", StartsAt: now.Add(10 * time.Hour), Capacity: 1, Booked: 1},
+	}}
+	interview := &models.Interview{ID: "interview", VacancyID: "vacancy", Status: models.InterviewStatusPending, SlotID: &evening}
+	svc := NewInterviewSlotService(slotRepo, &fakeInterviewRepository{interview: interview}, nil, nil)
+
+	slots, err := svc.AvailableSlots(context.Background(), "token")
+	require.NoError(t, err)
+	require.Len(t, slots, 1, "без заполненных окон и текущей записи")
+	assert.Equal(t, "morning", slots[0].ID)
+}
+
+func TestIsInterviewAccessible_SlotWindow(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	windowEnd := now.Add(2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		interview models.Interview
+		want      bool
+	}{
+		{name: "окно не выбрано", interview: models.Interview{Status: models.InterviewStatusPending, ExpiresAt: &windowEnd}},
+		{name: "окно еще не началось", interview: models.Interview{Status: models.InterviewStatusPending, ScheduledAt: &future, ExpiresAt: &windowEnd}},
+		{name: "идет выбранное окно", interview: models.Interview{Status: models.InterviewStatusPending, ScheduledAt: &past, ExpiresAt: &windowEnd}, want: true},
+		{name: "окно закончилось", interview: models.Interview{Status: models.InterviewStatusPending, ScheduledAt: &past, ExpiresAt: &past}},
+		{name: "начатое собеседование после конца окна", interview: models.Interview{Status: models.InterviewStatusStarted, ScheduledAt: &past, ExpiresAt: &past}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interview := tt.interview
+			svc := NewInterviewService(&fakeInterviewRepository{interview: &interview}, nil, nil, nil, nil, "", nil)
+
+			accessible, message, err := svc.IsInterviewAccessible(context.Background(), "token")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accessible, message)
+		})
+	}
+}
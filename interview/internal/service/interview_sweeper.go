@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// InterviewSweeperLockKey - ключ advisory-блокировки, которой выбирается реплика для обхода собеседований
+const InterviewSweeperLockKey int64 = 72_010_021
+
+// InterviewSweeperConfig - настройки фонового обхода собеседований
+// Ограничения вакансии важнее значений по умолчанию из конфигурации.
+type InterviewSweeperConfig struct {
+	Interval    time.Duration // как часто проверять собеседования
+	IdleTimeout time.Duration // начатое собеседование без сообщений дольше завершается
+	MaxDuration time.Duration // начатое собеседование дольше завершается
+}
+
+var DefaultInterviewSweeperConfig = InterviewSweeperConfig{
+	Interval:    time.Minute,
+	IdleTimeout: DefaultInterviewPolicy.IdleTimeout,
+	MaxDuration: DefaultInterviewPolicy.MaxDuration,
+}
+
+// LeaderLock - выбор одной реплики для фоновой задачи
+type LeaderLock interface {
+	// IsLeader проверяет лидерство и пытается стать лидером
+	IsLeader(ctx context.Context) (bool, error)
+	Resign(ctx context.Context) error
+}
+
+// InterviewSweeper закрывает собеседования, которые сами не завершатся:
+// переводит в expired ожидающие с истекшей ссылкой и завершает брошенные кандидатом или
+// вышедшие за ограничение времени. Завершая начатое собеседование, просит AI подвести итог.
+// Работает только на реплике, удерживающей LeaderLock.
+type InterviewSweeper struct {
+	interviews repository.InterviewRepository
+	resumes    ResumeService
+	chat       ChatService
+	leader     LeaderLock
+	cfg        InterviewSweeperConfig
+}
+
+func NewInterviewSweeper(
+	interviews repository.InterviewRepository,
+	resumes ResumeService,
+	chat ChatService,
+	leader LeaderLock,
+	cfg InterviewSweeperConfig,
+) *InterviewSweeper {
+	return &InterviewSweeper{interviews: interviews, resumes: resumes, chat: chat, leader: leader, cfg: cfg}
+}
+
+// Run обходит собеседования до отмены ctx
+func (s *InterviewSweeper) Run(ctx context.Context) {
+	log.Printf("⏰ Interview sweeper started: every %s, idle timeout %s, max duration %s",
+		s.cfg.Interval, s.cfg.IdleTimeout, s.cfg.MaxDuration)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	leading := false
+	for {
+		isLeader, err := s.leader.IsLeader(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Interview sweeper: leader election failed: %v", err)
+		}
+		if isLeader != leading {
+			if isLeader {
+				log.Println("👑 Interview sweeper: this replica is the leader")
+			} else {
+				log.Println("⏸️ Interview sweeper: leadership lost")
+			}
+			leading = isLeader
+		}
+		if isLeader {
+			s.Sweep(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			if leading {
+				if err := s.leader.Resign(context.Background()); err != nil {
+					log.Printf("⚠️ Interview sweeper: failed to resign: %v", err)
+				}
+			}
+			log.Println("✅ Interview sweeper stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep выполняет один обход
+func (s *InterviewSweeper) Sweep(ctx context.Context) {
+	expired, err := s.expirePending(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("❌ Failed to expire interviews: %v", err)
+	}
+	idle, err := s.finishStarted(ctx, s.interviews.GetIdleStarted, s.cfg.IdleTimeout, FinishReasonIdle)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("❌ Failed to finish idle interviews: %v", err)
+	}
+	overtime, err := s.finishStarted(ctx, s.interviews.GetOvertimeStarted, s.cfg.MaxDuration, FinishReasonTimeLimit)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("❌ Failed to finish overtime interviews: %v", err)
+	}
+	if expired > 0 || idle > 0 || overtime > 0 {
+		log.Printf("⏰ Interview sweeper: %d expired, %d finished by inactivity, %d by time limit", expired, idle, overtime)
+	}
+}
+
+func (s *InterviewSweeper) expirePending(ctx context.Context) (int, error) {
+	interviews, err := s.interviews.GetExpiredInterviews(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, interview := range interviews {
+		// Кандидат мог начать собеседование между выборкой и обновлением
+		ok, err := s.interviews.TransitionStatus(ctx, interview.ID, models.InterviewStatusPending, models.InterviewStatusExpired)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			continue
+		}
+		count++
+
+		// Кандидат не пришел - резюме возвращается к HR
+		s.changeResumeStatus(ctx, interview, StatusChange{
+			To:     models.ResumeStatusAnalyzed,
+			Actor:  models.ResumeActorInterview,
+			Reason: "interview " + interview.ID + " link expired",
+		})
+	}
+	return count, nil
+}
+
+// finishStarted завершает начатые собеседования, выбранные list по ограничению limit
+func (s *InterviewSweeper) finishStarted(
+	ctx context.Context,
+	list func(ctx context.Context, limit time.Duration) ([]*models.Interview, error),
+	limit time.Duration,
+	reason string,
+) (int, error) {
+	interviews, err := list(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, interview := range interviews {
+		ok, err := s.finish(ctx, interview, reason)
+		if err != nil {
+			return count, err
+		}
+		if ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// finish завершает начатое собеседование. Если переписка идет, итог подводит AI,
+// иначе собеседование просто переводится в finished.
+func (s *InterviewSweeper) finish(ctx context.Context, interview *models.Interview, reason string) (bool, error) {
+	err := s.chat.FinishByLimit(interview.ID, reason)
+	switch {
+	case err == nil:
+		// Чат сам перевел собеседование и резюме дальше
+		return true, nil
+	case errors.Is(err, ErrChatBusy):
+		// AI отвечает кандидату - проверим на следующем обходе
+		return false, nil
+	case !errors.Is(err, ErrChatSessionNotFound) && !errors.Is(err, ErrChatSessionClosed):
+		log.Printf("⚠️ Failed to get final result of interview %s: %v", interview.ID, err)
+	}
+
+	ok, err := s.interviews.TransitionStatus(ctx, interview.ID, models.InterviewStatusStarted, models.InterviewStatusFinished)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// Сохраняем переписку и сообщаем открытым вкладкам кандидата о завершении
+	if err := s.chat.CloseSession(interview.ID); err != nil && !errors.Is(err, ErrChatSessionNotFound) {
+		log.Printf("⚠️ Failed to save transcript of interview %s: %v", interview.ID, err)
+	}
+
+	s.changeResumeStatus(ctx, interview, StatusChange{
+		To:     models.ResumeStatusInterviewed,
+		Actor:  models.ResumeActorInterview,
+		Reason: fmt.Sprintf("interview %s finished: %s", interview.ID, reason),
+	})
+	return true, nil
+}
+
+// changeResumeStatus обновляет статус резюме собеседования. Если HR уже перевел резюме дальше, статус не меняется.
+func (s *InterviewSweeper) changeResumeStatus(ctx context.Context, interview *models.Interview, change StatusChange) {
+	if interview.ResumeID == nil {
+		return
+	}
+	err := s.resumes.ChangeStatus(ctx, *interview.ResumeID, change)
+	if err != nil && !errors.Is(err, ErrInvalidStatusTransition) {
+		log.Printf("⚠️ Failed to update resume %s after interview %s: %v", *interview.ResumeID, interview.ID, err)
+	}
+}
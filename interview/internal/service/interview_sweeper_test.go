@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeSweeperInterviews struct {
+	repository.InterviewRepository
+	expired  []*models.Interview
+	idle     []*models.Interview
+	overtime []*models.Interview
+	status   map[string]string
+}
+
+func (r *fakeSweeperInterviews) GetExpiredInterviews(ctx context.Context) ([]*models.Interview, error) {
+	return r.expired, nil
+}
+
+func (r *fakeSweeperInterviews) GetIdleStarted(ctx context.Context, defaultTimeout time.Duration) ([]*models.Interview, error) {
+	return r.idle, nil
+}
+
+func (r *fakeSweeperInterviews) GetOvertimeStarted(ctx context.Context, defaultDuration time.Duration) ([]*models.Interview, error) {
+	return r.overtime, nil
+}
+
+func (r *fakeSweeperInterviews) TransitionStatus(ctx context.Context, id string, from, to string) (bool, error) {
+	if r.status[id] != from {
+		return false, nil
+	}
+	r.status[id] = to
+	return true, nil
+}
+
+type fakeSweeperResumes struct {
+	ResumeService
+	changes map[string]models.ResumeStatus
+}
+
+func (s *fakeSweeperResumes) ChangeStatus(ctx context.Context, id string, change StatusChange) error {
+	s.changes[id] = change.To
+	return nil
+}
+
+type fakeSweeperChat struct {
+	ChatService
+	closed   []string
+	active   map[string]bool   // собеседования с идущей перепиской
+	busy     map[string]bool   // AI отвечает кандидату
+	finished map[string]string // причины завершения с итогом AI
+}
+
+func (c *fakeSweeperChat) FinishByLimit(interviewID, reason string) error {
+	switch {
+	case c.busy[interviewID]:
+		return ErrChatBusy
+	case !c.active[interviewID]:
+		return ErrChatSessionNotFound
+	}
+	if c.finished == nil {
+		c.finished = map[string]string{}
+	}
+	c.finished[interviewID] = reason
+	return nil
+}
+
+func (c *fakeSweeperChat) CloseSession(interviewID string) error {
+	c.closed = append(c.closed, interviewID)
+	return nil
+}
+
+func TestInterviewSweeper_Sweep(t *testing.T) {
+	resumeExpired, resumeIdle, resumeRaced := "resume-expired", "resume-idle", "resume-raced"
+	interviews := &fakeSweeperInterviews{
+		expired: []*models.Interview{
+			{ID: "expired", ResumeID: &resumeExpired},
+			{ID: "raced", ResumeID: &resumeRaced}, // кандидат успел начать собеседование
+		},
+		idle: []*models.Interview{
+			{ID: "idle", ResumeID: &resumeIdle},
+			{ID: "busy"},
+		},
+		overtime: []*models.Interview{{ID: "overtime"}},
+		status: map[string]string{
+			"expired":  models.InterviewStatusPending,
+			"raced":    models.InterviewStatusStarted,
+			"idle":     models.InterviewStatusStarted,
+			"busy":     models.InterviewStatusStarted,
+			"overtime": models.InterviewStatusStarted,
+		},
+	}
+	resumes := &fakeSweeperResumes{changes: map[string]models.ResumeStatus{}}
+	chat := &fakeSweeperChat{
+		active: map[string]bool{"overtime": true, "busy": true},
+		busy:   map[string]bool{"busy": true},
+	}
+
+	NewInterviewSweeper(interviews, resumes, chat, nil, DefaultInterviewSweeperConfig).Sweep(context.Background())
+
+	t.Run("истекшая ссылка", func(t *testing.T) {
+		assert.Equal(t, models.InterviewStatusExpired, interviews.status["expired"])
+		assert.Equal(t, models.ResumeStatusAnalyzed, resumes.changes[resumeExpired])
+	})
+
+	t.Run("кандидат начал собеседование до обновления", func(t *testing.T) {
+		assert.Equal(t, models.InterviewStatusStarted, interviews.status["raced"])
+		assert.NotContains(t, resumes.changes, resumeRaced)
+	})
+
+	t.Run("брошенное собеседование", func(t *testing.T) {
+		assert.Equal(t, models.InterviewStatusFinished, interviews.status["idle"])
+		assert.Equal(t, []string{"idle"}, chat.closed)
+		assert.Equal(t, models.ResumeStatusInterviewed, resumes.changes[resumeIdle])
+	})
+
+	t.Run("время вышло - итог подводит AI", func(t *testing.T) {
+		assert.Equal(t, FinishReasonTimeLimit, chat.finished["overtime"])
+		assert.NotContains(t, chat.closed, "overtime", "сессию закрывает чат")
+	})
+
+	t.Run("AI отвечает кандидату", func(t *testing.T) {
+		assert.Equal(t, models.InterviewStatusStarted, interviews.status["busy"])
+		assert.NotContains(t, chat.finished, "busy")
+	})
+}
+
+type fakeLeader struct {
+	leader bool
+}
+
+func (l *fakeLeader) IsLeader(ctx context.Context) (bool, error) {
+	return l.leader, nil
+}
+
+func (l *fakeLeader) Resign(ctx context.Context) error {
+	return nil
+}
+
+func TestInterviewSweeper_RunOnlyOnLeader(t *testing.T) {
+	for _, leader := range []bool{true, false} {
+		interviews := &fakeSweeperInterviews{
+			expired: []*models.Interview{{ID: "expired"}},
+			status:  map[string]string{"expired": models.InterviewStatusPending},
+		}
+		cfg := DefaultInterviewSweeperConfig
+		cfg.Interval = time.Hour
+		sweeper := NewInterviewSweeper(interviews, &fakeSweeperResumes{}, &fakeSweeperChat{}, &fakeLeader{leader: leader}, cfg)
+
+		// Первый обход выполняется при запуске, до проверки ctx
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sweeper.Run(ctx)
+
+		if leader {
+			assert.Equal(t, models.InterviewStatusExpired, interviews.status["expired"], "лидер")
+		} else {
+			assert.Equal(t, models.InterviewStatusPending, interviews.status["expired"], "не лидер")
+		}
+	}
+}
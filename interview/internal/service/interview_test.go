@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeInterviewRepository struct {
+	repository.InterviewRepository
+	interview *models.Interview
+	created   *models.Interview
+	// Для CreateInvited: ошибка смены статуса резюме, сохраненные переход и письмо
+	statusErr  error
+	change     *models.ResumeStatusHistory
+	invitation *models.EmailMessage
+}
+
+func (r *fakeInterviewRepository) Create(ctx context.Context, interview *models.Interview) error {
+	r.created = interview
+	return nil
+}
+
+func (r *fakeInterviewRepository) CreateInvited(ctx context.Context, interview *models.Interview, change *models.ResumeStatusHistory, invitation *models.EmailMessage) error {
+	if r.statusErr != nil {
+		return r.statusErr
+	}
+	r.created, r.change, r.invitation = interview, change, invitation
+	invitation.Status = models.EmailStatusQueued
+	return nil
+}
+
+func (r *fakeInterviewRepository) GetByID(ctx context.Context, id string) (*models.Interview, error) {
+	return r.interview, nil
+}
+
+func (r *fakeInterviewRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Interview, error) {
+	return r.interview, nil
+}
+
+func (r *fakeInterviewRepository) ReissueToken(ctx context.Context, id, tokenHash string, expiresAt time.Time) (bool, error) {
+	if r.interview.Status != models.InterviewStatusPending {
+		return false, nil
+	}
+	r.interview.TokenHash, r.interview.ExpiresAt = &tokenHash, &expiresAt
+	return true, nil
+}
+
+func (r *fakeInterviewRepository) ReissueWithInvitation(ctx context.Context, id, tokenHash string, expiresAt time.Time, invitation *models.EmailMessage) (bool, error) {
+	ok, _ := r.ReissueToken(ctx, id, tokenHash, expiresAt)
+	if !ok {
+		return false, nil
+	}
+	r.invitation = invitation
+	invitation.Status = models.EmailStatusQueued
+	return true, nil
+}
+
+func (r *fakeInterviewRepository) RevokeToken(ctx context.Context, id string) (bool, error) {
+	if r.interview.Status != models.InterviewStatusPending && r.interview.Status != models.InterviewStatusStarted {
+		return false, nil
+	}
+	r.interview.TokenHash = nil
+	return true, nil
+}
+
+func (r *fakeInterviewRepository) Update(ctx context.Context, interview *models.Interview) error {
+	return nil
+}
+
+func (r *fakeInterviewRepository) TransitionStatus(ctx context.Context, id string, from, to string) (bool, error) {
+	if r.interview.Status != from {
+		return false, nil
+	}
+	r.interview.Status = to
+	return true, nil
+}
+
+func (r *fakeInterviewRepository) StartIfPending(ctx context.Context, id string, startedAt time.Time) (bool, error) {
+	if r.interview.Status != models.InterviewStatusPending {
+		return false, nil
+	}
+	r.interview.Status, r.interview.StartedAt = models.InterviewStatusStarted, &startedAt
+	return true, nil
+}
+
+type fakeInterviewResumes struct {
+	repository.ResumeRepository
+	resume *models.Resume
+}
+
+func (r *fakeInterviewResumes) GetByID(ctx context.Context, id string) (*models.Resume, error) {
+	return r.resume, nil
+}
+
+func TestCreateInterview(t *testing.T) {
+	repo := &fakeInterviewRepository{}
+	svc := NewInterviewService(
+		repo,
+		&fakeRankingVacancies{vacancy: &models.Vacancy{ID: "vacancy"}},
+		&fakeInterviewResumes{resume: &models.Resume{ID: "resume", VacancyID: "vacancy"}},
+		nil,
+		nil,
+		"https://hr.example.com/",
+		nil,
+	)
+	ctx := context.Background()
+
+	t.Run("ссылка кандидата", func(t *testing.T) {
+		resumeID := "resume"
+		interview := &models.Interview{VacancyID: "vacancy", ResumeID: &resumeID}
+		require.NoError(t, svc.CreateInterview(ctx, interview))
+		assert.Equal(t, models.InterviewStatusPending, repo.created.Status)
+
+		require.True(t, strings.HasPrefix(interview.URL, "https://hr.example.com/api/interview/"), interview.URL)
+		token := strings.TrimPrefix(interview.URL, "https://hr.example.com/api/interview/")
+		require.NotNil(t, repo.created.TokenHash)
+		assert.Equal(t, hashToken(token), *repo.created.TokenHash, "в базе только хэш токена")
+		assert.NotContains(t, *repo.created.TokenHash, token)
+	})
+
+	t.Run("резюме другой вакансии", func(t *testing.T) {
+		resumeID := "resume"
+		err := svc.CreateInterview(ctx, &models.Interview{VacancyID: "other", ResumeID: &resumeID})
+		assert.ErrorIs(t, err, ErrInvalidInterview)
+	})
+
+	t.Run("без вакансии", func(t *testing.T) {
+		assert.ErrorIs(t, svc.CreateInterview(ctx, &models.Interview{}), ErrInvalidInterview)
+	})
+}
+
+func TestCancelInterview(t *testing.T) {
+	tests := []struct {
+		status     string
+		wantErr    error
+		wantClosed bool
+	}{
+		{models.InterviewStatusPending, nil, false},
+		{models.InterviewStatusStarted, nil, true},
+		{models.InterviewStatusFinished, ErrInterviewStateChanged, false},
+		{models.InterviewStatusCancelled, ErrInterviewStateChanged, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			resumeID := "resume"
+			repo := &fakeInterviewRepository{interview: &models.Interview{ID: "interview", ResumeID: &resumeID, Status: tt.status}}
+			resumes := &fakeSweeperResumes{changes: map[string]models.ResumeStatus{}}
+			chat := &fakeSweeperChat{}
+			svc := NewInterviewService(repo, nil, nil, resumes, chat, "", calendarWithoutMail())
+
+			interview, err := svc.CancelInterview(context.Background(), "interview")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, resumes.changes)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.InterviewStatusCancelled, interview.Status)
+			assert.Equal(t, models.ResumeStatusAnalyzed, resumes.changes[resumeID], "резюме возвращается к HR")
+			assert.Equal(t, tt.wantClosed, len(chat.closed) == 1, "идущий чат закрывается")
+
+			accessible, _, err := svc.IsInterviewAccessible(context.Background(), "token")
+			require.NoError(t, err)
+			assert.False(t, accessible, "отмененная ссылка недоступна")
+		})
+	}
+}
+
+func TestStartInterview(t *testing.T) {
+	opened := time.Now().Add(-time.Minute)
+	repo := &fakeInterviewRepository{interview: &models.Interview{ID: "interview", Status: models.InterviewStatusPending, ScheduledAt: &opened}}
+	svc := NewInterviewService(repo, nil, nil, nil, nil, "", nil)
+
+	require.NoError(t, svc.StartInterview(context.Background(), "token"))
+	assert.Equal(t, models.InterviewStatusStarted, repo.interview.Status)
+	require.NotNil(t, repo.interview.StartedAt)
+
+	// Повторный запуск (вторая вкладка) не проходит
+	err := svc.StartInterview(context.Background(), "token")
+	assert.ErrorIs(t, err, ErrInterviewStateChanged)
+}
+
+func TestFinishInterview(t *testing.T) {
+	resumeID := "resume"
+	repo := &fakeInterviewRepository{interview: &models.Interview{ID: "interview", ResumeID: &resumeID, Status: models.InterviewStatusStarted}}
+	resumes := &fakeSweeperResumes{changes: map[string]models.ResumeStatus{}}
+	chat := &fakeSweeperChat{}
+	svc := NewInterviewService(repo, nil, nil, resumes, chat, "", nil)
+
+	require.NoError(t, svc.FinishInterview(context.Background(), "token"))
+	assert.Equal(t, models.InterviewStatusFinished, repo.interview.Status)
+	assert.Equal(t, models.ResumeStatusInterviewed, resumes.changes[resumeID])
+	assert.Equal(t, []string{"interview"}, chat.closed)
+
+	// Повторное завершение (вторая вкладка) не проходит
+	err := svc.FinishInterview(context.Background(), "token")
+	assert.ErrorIs(t, err, ErrInterviewStateChanged)
+	assert.Len(t, chat.closed, 1)
+}
+
+func TestInterviewLink(t *testing.T) {
+	oldHash := hashToken("old")
+	tests := []struct {
+		status      string
+		wantReissue error
+		wantRevoke  error
+	}{
+		{models.InterviewStatusPending, nil, nil},
+		{models.InterviewStatusStarted, ErrInterviewStateChanged, nil},
+		{models.InterviewStatusFinished, ErrInterviewStateChanged, ErrInterviewStateChanged},
+		{models.InterviewStatusExpired, ErrInterviewStateChanged, ErrInterviewStateChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			repo := &fakeInterviewRepository{interview: &models.Interview{ID: "interview", Status: tt.status, TokenHash: &oldHash}}
+			svc := NewInterviewService(repo, nil, nil, nil, nil, "https://hr.example.com", nil)
+
+			interview, err := svc.ReissueLink(context.Background(), "interview")
+			if tt.wantReissue != nil {
+				assert.ErrorIs(t, err, tt.wantReissue)
+			} else {
+				require.NoError(t, err)
+				assert.NotEqual(t, oldHash, *repo.interview.TokenHash, "старая ссылка перестает работать")
+				assert.Equal(t, "https://hr.example.com/api/interview/", strings.TrimRight(interview.URL, "0123456789abcdef"))
+			}
+
+			_, err = svc.RevokeLink(context.Background(), "interview")
+			if tt.wantRevoke != nil {
+				assert.ErrorIs(t, err, tt.wantRevoke)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, repo.interview.TokenHash)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+var ErrInvitationNotAllowed = errors.New("invitation cannot be sent")
+
+// InvitationService приглашает на собеседование кандидатов, прошедших отбор по резюме
+type InvitationService interface {
+	// InviteIfPassed создает собеседование и ставит приглашение в очередь отправки, если оценка
+	// резюме не ниже порога вакансии. Если кандидат не приглашен, возвращает nil.
+	InviteIfPassed(ctx context.Context, resumeID string) (*models.Interview, error)
+	// Resend выпускает новую ссылку еще не начатого собеседования и ставит приглашение в очередь.
+	// Токен ссылки не хранится, поэтому старая ссылка перестает работать.
+	Resend(ctx context.Context, interviewID string) (*models.EmailMessage, error)
+	// Deliveries - все письма собеседования, новые первыми
+	Deliveries(ctx context.Context, interviewID string) ([]*models.EmailMessage, error)
+	// VacancyDeliveries - последнее приглашение каждого собеседования вакансии
+	VacancyDeliveries(ctx context.Context, vacancyID string) ([]*models.EmailMessage, error)
+}
+
+type invitationService struct {
+	resumes    ResumeService
+	vacancies  repository.VacancyRepository
+	interviews InterviewService
+	outbox     repository.EmailOutboxRepository
+	calendar   InterviewCalendarService
+	policy     InterviewPolicy // ограничения по умолчанию, длительность указывается в письме
+}
+
+func NewInvitationService(
+	resumes ResumeService,
+	vacancies repository.VacancyRepository,
+	interviews InterviewService,
+	outbox repository.EmailOutboxRepository,
+	calendar InterviewCalendarService,
+	policy InterviewPolicy,
+) InvitationService {
+	return &invitationService{resumes: resumes, vacancies: vacancies, interviews: interviews, outbox: outbox, calendar: calendar, policy: policy}
+}
+
+func (s *invitationService) InviteIfPassed(ctx context.Context, resumeID string) (*models.Interview, error) {
+	resume, err := s.resumes.GetResume(ctx, resumeID)
+	if err != nil {
+		return nil, fmt.Errorf("resume not found: %w", err)
+	}
+	if resume.Status != models.ResumeStatusAnalyzed || resume.Mail == "" {
+		return nil, nil
+	}
+
+	vacancy, err := s.vacancies.GetByID(ctx, resume.VacancyID)
+	if err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+	if vacancy.InviteThreshold == nil {
+		return nil, nil
+	}
+
+	score := resumeFinalScore(resume.ResumeAnalysisJSONB)
+	if score == nil || *score < *vacancy.InviteThreshold {
+		return nil, nil
+	}
+
+	// Смена статуса защищает от повторного приглашения при повторной доставке результата.
+	// Статус, собеседование и письмо сохраняются вместе: сбой посередине не оставит кандидата без ссылки.
+	change := &models.ResumeStatusHistory{
+		ResumeID:   resume.ID,
+		FromStatus: resume.Status,
+		ToStatus:   models.ResumeStatusInterviewing,
+		Actor:      models.ResumeActorSystem,
+		Reason:     fmt.Sprintf("screening score %.1f passed threshold %.1f", *score, *vacancy.InviteThreshold),
+	}
+	interview := &models.Interview{VacancyID: vacancy.ID, ResumeID: &resume.ID}
+	err = s.interviews.CreateInvitedInterview(ctx, interview, change, func(interview *models.Interview) (*models.EmailMessage, error) {
+		return s.buildInvitation(interview, vacancy, resume.Mail)
+	})
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create interview: %w", err)
+	}
+
+	log.Printf("🔀 Resume %s: %q -> %q by %s", resume.ID, change.FromStatus, change.ToStatus, change.Actor)
+	log.Printf("✉️ Resume %s invited to interview %s (score %.1f)", resume.ID, interview.ID, *score)
+	return interview, nil
+}
+
+func (s *invitationService) Resend(ctx context.Context, interviewID string) (*models.EmailMessage, error) {
+	interview, err := s.interviews.GetInterview(ctx, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status != models.InterviewStatusPending {
+		return nil, fmt.Errorf("%w: interview is %s", ErrInvitationNotAllowed, interview.Status)
+	}
+	if interview.ResumeID == nil {
+		return nil, fmt.Errorf("%w: interview has no candidate", ErrInvitationNotAllowed)
+	}
+
+	resume, err := s.resumes.GetResume(ctx, *interview.ResumeID)
+	if err != nil {
+		return nil, fmt.Errorf("resume not found: %w", err)
+	}
+	if resume.Mail == "" {
+		return nil, fmt.Errorf("%w: candidate has no email", ErrInvitationNotAllowed)
+	}
+	vacancy, err := s.vacancies.GetByID(ctx, interview.VacancyID)
+	if err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+
+	// Новая ссылка и письмо с ней сохраняются вместе, неотправленное письмо со старой ссылкой отменяется
+	msg, err := s.interviews.ReissueLinkWithInvitation(ctx, interview.ID, func(interview *models.Interview) (*models.EmailMessage, error) {
+		return s.buildInvitation(interview, vacancy, resume.Mail)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🔁 Invitation for interview %s queued again with a new link", interview.ID)
+	return msg, nil
+}
+
+func (s *invitationService) Deliveries(ctx context.Context, interviewID string) ([]*models.EmailMessage, error) {
+	if _, err := s.interviews.GetInterview(ctx, interviewID); err != nil {
+		return nil, err
+	}
+	return s.outbox.ListByInterview(ctx, interviewID)
+}
+
+func (s *invitationService) VacancyDeliveries(ctx context.Context, vacancyID string) ([]*models.EmailMessage, error) {
+	if _, err := s.vacancies.GetByID(ctx, vacancyID); err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+	return s.outbox.LatestByVacancy(ctx, vacancyID, models.EmailKindInvitation)
+}
+
+// buildInvitation собирает письмо-приглашение со ссылкой interview.URL
+func (s *invitationService) buildInvitation(interview *models.Interview, vacancy *models.Vacancy, to string) (*models.EmailMessage, error) {
+	data := InvitationMail{
+		VacancyTitle: vacancy.Title,
+		InterviewURL: interview.URL,
+		MaxMinutes:   int(s.policy.ForVacancy(vacancy).MaxDuration / time.Minute),
+	}
+	if interview.ExpiresAt != nil {
+		data.ExpiresAt = *interview.ExpiresAt
+	}
+	mail, err := RenderInvitation(to, data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &models.EmailMessage{
+		Kind:        models.EmailKindInvitation,
+		InterviewID: &interview.ID,
+		VacancyID:   &interview.VacancyID,
+		ResumeID:    interview.ResumeID,
+		Recipient:   mail.To,
+		Subject:     mail.Subject,
+		HTMLBody:    mail.HTMLBody,
+	}
+	// Если кандидат уже выбрал время, приглашение несет и событие календаря
+	if event, ok := s.calendar.Event(interview, vacancy.Title, to, interview.URL); ok {
+		msg.CalendarICS, msg.CalendarMethod = string(event.ICS()), event.Method()
+	}
+	return msg, nil
+}
+
+// resumeFinalScore - итоговая оценка из анализа резюме
+func resumeFinalScore(analysis []byte) *float64 {
+	if len(analysis) == 0 {
+		return nil
+	}
+	var cv cvAnalysis
+	if err := json.Unmarshal(analysis, &cv); err != nil {
+		return nil
+	}
+	return clampScore(cv.OverallAssessment.FinalScore)
+}
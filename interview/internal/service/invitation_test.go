@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeInvitationResumes struct {
+	ResumeService
+	resume *models.Resume
+}
+
+func (s *fakeInvitationResumes) GetResume(ctx context.Context, id string) (*models.Resume, error) {
+	return s.resume, nil
+}
+
+type fakeEmailOutbox struct {
+	repository.EmailOutboxRepository
+	queued []*models.EmailMessage
+}
+
+func (r *fakeEmailOutbox) Enqueue(ctx context.Context, msg *models.EmailMessage) error {
+	msg.Status = models.EmailStatusQueued
+	r.queued = append(r.queued, msg)
+	return nil
+}
+
+func (r *fakeEmailOutbox) CancelQueued(ctx context.Context, interviewID string, kind string) (int64, error) {
+	var cancelled int64
+	for _, msg := range r.queued {
+		if msg.Kind == kind && msg.Status == models.EmailStatusQueued {
+			msg.Status = models.EmailStatusCancelled
+			cancelled++
+		}
+	}
+	return cancelled, nil
+}
+
+func TestInvitationService_InviteIfPassed(t *testing.T) {
+	threshold := 70.0
+	analysis := datatypes.JSON(`{"overall_assessment": {"final_score": 82}}`)
+
+	tests := []struct {
+		name       string
+		resume     models.Resume
+		threshold  *float64
+		statusErr  error
+		wantInvite bool
+	}{
+		{
+			name:       "оценка выше порога",
+			resume:     models.Resume{Status: models.ResumeStatusAnalyzed, Mail: "candidate@example.com", ResumeAnalysisJSONB: analysis},
+			threshold:  &threshold,
+			wantInvite: true,
+		},
+		{
+			name:      "оценка ниже порога",
+			resume:    models.Resume{Status: models.ResumeStatusAnalyzed, Mail: "candidate@example.com", ResumeAnalysisJSONB: datatypes.JSON(`{"overall_assessment": {"final_score": 55}}`)},
+			threshold: &threshold,
+		},
+		{
+			name:   "порог вакансии не задан",
+			resume: models.Resume{Status: models.ResumeStatusAnalyzed, Mail: "candidate@example.com", ResumeAnalysisJSONB: analysis},
+		},
+		{
+			name:      "нет почты",
+			resume:    models.Resume{Status: models.ResumeStatusAnalyzed, ResumeAnalysisJSONB: analysis},
+			threshold: &threshold,
+		},
+		{
+			name:      "кандидат уже приглашен",
+			resume:    models.Resume{Status: models.ResumeStatusInterviewing, Mail: "candidate@example.com", ResumeAnalysisJSONB: analysis},
+			threshold: &threshold,
+		},
+		{
+			name:      "статус сменился параллельно",
+			resume:    models.Resume{Status: models.ResumeStatusAnalyzed, Mail: "candidate@example.com", ResumeAnalysisJSONB: analysis},
+			threshold: &threshold,
+			statusErr: repository.ErrStatusChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resume := tt.resume
+			resume.ID, resume.VacancyID = "resume", "vacancy"
+			vacancy := &models.Vacancy{ID: "vacancy", Title: "Go-разработчик", InviteThreshold: tt.threshold}
+
+			resumes := &fakeInvitationResumes{resume: &resume}
+			interviewRepo := &fakeInterviewRepository{statusErr: tt.statusErr}
+			interviews := NewInterviewService(interviewRepo, &fakeRankingVacancies{vacancy: vacancy}, &fakeInterviewResumes{resume: &resume}, nil, nil, "https://hr.example.com", nil)
+			outbox := &fakeEmailOutbox{}
+			svc := NewInvitationService(resumes, &fakeRankingVacancies{vacancy: vacancy}, interviews, outbox, calendarWithoutMail(), DefaultInterviewPolicy)
+
+			interview, err := svc.InviteIfPassed(context.Background(), "resume")
+			require.NoError(t, err)
+
+			if !tt.wantInvite {
+				assert.Nil(t, interview)
+				assert.Nil(t, interviewRepo.created)
+				assert.Empty(t, outbox.queued)
+				return
+			}
+
+			require.NotNil(t, interview)
+			assert.Same(t, interview, interviewRepo.created)
+			require.NotNil(t, interviewRepo.change, "статус меняется в одной транзакции с собеседованием")
+			assert.Equal(t, models.ResumeStatusAnalyzed, interviewRepo.change.FromStatus)
+			assert.Equal(t, models.ResumeStatusInterviewing, interviewRepo.change.ToStatus)
+
+			assert.Empty(t, outbox.queued, "письмо ставится в очередь той же транзакцией")
+			msg := interviewRepo.invitation
+			require.NotNil(t, msg)
+			assert.Equal(t, models.EmailKindInvitation, msg.Kind)
+			assert.Equal(t, "candidate@example.com", msg.Recipient)
+			assert.Equal(t, &interview.ID, msg.InterviewID)
+			assert.Contains(t, msg.Subject, "Go-разработчик")
+			assert.Contains(t, msg.HTMLBody, interview.URL)
+			assert.Contains(t, msg.HTMLBody, interview.ExpiresAt.Format("02.01.2006"))
+		})
+	}
+}
+
+func TestInvitationService_Resend(t *testing.T) {
+	resumeID := "resume"
+	past := time.Now().Add(-time.Hour)
+	oldHash := hashToken("old")
+
+	tests := []struct {
+		name      string
+		interview models.Interview
+		mail      string
+		wantErr   error
+	}{
+		{name: "ожидающее собеседование", interview: models.Interview{Status: models.InterviewStatusPending, ResumeID: &resumeID}, mail: "candidate@example.com"},
+		{name: "собеседование уже начато", interview: models.Interview{Status: models.InterviewStatusStarted, ResumeID: &resumeID}, mail: "candidate@example.com", wantErr: ErrInvitationNotAllowed},
+		{name: "ссылка истекла", interview: models.Interview{Status: models.InterviewStatusPending, ResumeID: &resumeID, ExpiresAt: &past}, mail: "candidate@example.com"},
+		{name: "без кандидата", interview: models.Interview{Status: models.InterviewStatusPending}, wantErr: ErrInvitationNotAllowed},
+		{name: "у кандидата нет почты", interview: models.Interview{Status: models.InterviewStatusPending, ResumeID: &resumeID}, wantErr: ErrInvitationNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interview := tt.interview
+			interview.ID, interview.VacancyID, interview.TokenHash = "interview", "vacancy", &oldHash
+			vacancy := &models.Vacancy{ID: "vacancy", Title: "Go-разработчик"}
+			resume := &models.Resume{ID: resumeID, VacancyID: "vacancy", Mail: tt.mail}
+
+			repo := &fakeInterviewRepository{interview: &interview}
+			interviews := NewInterviewService(repo, &fakeRankingVacancies{vacancy: vacancy}, &fakeInterviewResumes{resume: resume}, nil, nil, "https://hr.example.com", nil)
+			svc := NewInvitationService(&fakeInvitationResumes{resume: resume}, &fakeRankingVacancies{vacancy: vacancy}, interviews, &fakeEmailOutbox{}, calendarWithoutMail(), DefaultInterviewPolicy)
+
+			msg, err := svc.Resend(context.Background(), "interview")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, repo.invitation)
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, msg, repo.invitation, "письмо сохраняется вместе с новой ссылкой")
+			assert.Equal(t, models.EmailStatusQueued, msg.Status)
+			assert.Contains(t, msg.HTMLBody, "https://hr.example.com/api/interview/")
+			assert.NotEqual(t, oldHash, *interview.TokenHash, "письмо уходит с новой ссылкой")
+			assert.True(t, interview.ExpiresAt.After(time.Now()))
+		})
+	}
+}
+
+func TestRenderInvitation_EscapesVacancyTitle(t *testing.T) {
+	msg, err := RenderInvitation("candidate@example.com", InvitationMail{
+		VacancyTitle: `<script>alert(1)</script>`,
+		InterviewURL: "https://hr.example.com/api/interview/token",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, msg.HTMLBody, "<script>")
+}
@@ -1,124 +1,189 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"embed"
+	"errors"
 	"fmt"
-	"gopkg.in/mail.v2"
+	"html/template"
 	"log"
-	"os"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"gopkg.in/mail.v2"
 )
 
-func SendMail(to, url string) error {
-	m := mail.NewMessage()
-
-	mailName := os.Getenv("MAIL_NAME")
-	mailPassword := os.Getenv("MAIL_PASSWORD")
-
-	m.SetHeader("From", mailName)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", "Приглашение на собеседование")
-
-	body := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background: #2c3e50; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
-        .content { background: #f8f9fa; padding: 30px; border: 1px solid #dee2e6; }
-        .button { 
-            display: inline-block; 
-            background: #007bff; 
-            color: white; 
-            padding: 12px 30px; 
-            text-decoration: none; 
-            border-radius: 5px; 
-            margin: 20px 0;
-            font-weight: bold;
-        }
-        .important { background: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
-        .footer { background: #6c757d; color: white; padding: 15px; text-align: center; font-size: 12px; border-radius: 0 0 5px 5px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>🤖 Приглашение на AI-собеседование</h1>
-        </div>
-        
-        <div class="content">
-            <h2>Здравствуйте!</h2>
-            
-            <p>Мы рады сообщить, что ваше резюме прошло первичный отбор! </p>
-            
-            <p>Приглашаем вас пройти <strong>AI-скрининг интервью</strong> на позицию <strong>%s</strong>.</p>
-            
-            <h3>Что вас ждет:</h3>
-            <ul>
-                <li>📝 Интерактивное собеседование с AI-ассистентом</li>
-                <li>⏱️ Длительность: 20-30 минут</li>
-                <li>💬 Вопросы по вашему опыту и техническим навыкам</li>
-                <li>🔄 Возможность уточнить детали в режиме реального времени</li>
-            </ul>
-            
-            <div style="text-align: center;">
-                <a href="%s" class="button">НАЧАТЬ СОБЕСЕДОВАНИЕ</a>
-            </div>
-            
-            <div class="important">
-                <strong>⚠️ Важно:</strong>
-                <br>• Ссылка действительна в течение <strong>7 дней</strong> с момента получения
-                <br>• Рекомендуем проходить собеседование в тихой обстановке
-                <br>• Подготовьте информацию о своем опыте работы
-                <br>• В случае технических проблем свяжитесь с нами
-            </div>
-            
-            <h3>Как это работает:</h3>
-            <ol>
-                <li>Перейдите по ссылке выше</li>
-                <li>AI-ассистент поприветствует вас и объяснит процесс</li>
-                <li>Отвечайте на вопросы честно и подробно</li>
-                <li>По завершении получите обратную связь</li>
-            </ol>
-            
-            <p>Мы ценим ваше время и интерес к нашей компании. Желаем удачи! </p>
-            
-            <hr style="margin: 30px 0; border: none; border-top: 1px solid #dee2e6;">
-            
-            <p style="font-size: 14px; color: #6c757d;">
-                Если у вас возникли вопросы, ответьте на это письмо или свяжитесь с нами по телефону: 
-                <strong>+7 (xxx) xxx-xx-xx</strong>
-            </p>
-        </div>
-        
-        <div class="footer">
-            <p>© 2024 Название компании. Все права защищены.</p>
-            <p>Это автоматическое сообщение, пожалуйста, не отвечайте на него.</p>
-        </div>
-    </div>
-</body>
-</html>
-`, "vacancy_title", "interview_url")
-
-	m.SetBody("text/html", body)
-
-	d := mail.NewDialer("smtp.mail.ru", 465, mailName, mailPassword)
-
-	d.TLSConfig = &tls.Config{
-		ServerName:         "smtp.mail.ru",
-		InsecureSkipVerify: false,
+//go:embed templates/*.html
+var mailTemplates embed.FS
+
+var mailTemplateSet = template.Must(template.ParseFS(mailTemplates, "templates/*.html"))
+
+// ErrMailRejected - почтовый сервер отклонил адрес получателя, повторная отправка не поможет
+var ErrMailRejected = errors.New("mail rejected by server")
+
+// MailMessage - письмо кандидату
+type MailMessage struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	// Calendar - приглашение в календарь, прикладывается как invite.ics
+	Calendar *MailCalendar
+}
+
+// MailCalendar - файл .ics и его METHOD, который почтовые клиенты ждут и в Content-Type
+type MailCalendar struct {
+	Method string
+	ICS    []byte
+}
+
+// Mailer отправляет письма
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// SMTPConfig - параметры SMTP сервера
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	dialer *mail.Dialer
+	from   string
+}
+
+func NewSMTPMailer(cfg SMTPConfig) Mailer {
+	d := mail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	d.TLSConfig = &tls.Config{ServerName: cfg.Host}
+	d.Timeout = 30 * time.Second
+
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+	return &smtpMailer{dialer: d, from: from}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	message := mail.NewMessage()
+	message.SetHeader("From", m.from)
+	message.SetHeader("To", msg.To)
+	message.SetHeader("Subject", msg.Subject)
+	message.SetBody("text/html", msg.HTMLBody)
+	if msg.Calendar != nil {
+		message.AttachReader("invite.ics", bytes.NewReader(msg.Calendar.ICS), mail.SetHeader(map[string][]string{
+			"Content-Type": {"text/calendar; charset=utf-8; method=" + msg.Calendar.Method},
+		}))
+	}
+
+	if err := m.dialer.DialAndSend(message); err != nil {
+		if isRecipientRejected(err) {
+			return fmt.Errorf("%w: %s: %v", ErrMailRejected, msg.To, err)
+		}
+		return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// isRecipientRejected - ответы SMTP 550, 551, 553: ящика нет или адрес недопустим
+func isRecipientRejected(err error) bool {
+	var sendErr *mail.SendError
+	if errors.As(err, &sendErr) {
+		err = sendErr.Cause
 	}
+	var smtpErr *textproto.Error
+	if !errors.As(err, &smtpErr) {
+		return false
+	}
+	return smtpErr.Code == 550 || smtpErr.Code == 551 || smtpErr.Code == 553
+}
+
+// CaptureMailer не отправляет письма, а сохраняет их в памяти.
+// Используется в тестах и локально, когда SMTP не настроен.
+type CaptureMailer struct {
+	mu       sync.Mutex
+	messages []MailMessage
+}
+
+func NewCaptureMailer() *CaptureMailer {
+	return &CaptureMailer{}
+}
+
+func (m *CaptureMailer) Send(ctx context.Context, msg MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	log.Printf("📭 Mail to %s captured: %s", msg.To, msg.Subject)
+	return nil
+}
+
+// Messages возвращает копию перехваченных писем
+func (m *CaptureMailer) Messages() []MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]MailMessage(nil), m.messages...)
+}
+
+// InvitationMail - данные шаблона приглашения на собеседование
+type InvitationMail struct {
+	VacancyTitle string
+	InterviewURL string
+	ExpiresAt    time.Time
+	MaxMinutes   int // ограничение длительности собеседования, 0 - без ограничения
+}
+
+// RenderInvitation собирает письмо с приглашением на собеседование
+func RenderInvitation(to string, data InvitationMail) (MailMessage, error) {
+	return renderMail("interview_invitation.html", to, fmt.Sprintf("Приглашение на собеседование: %s", data.VacancyTitle), data)
+}
+
+// ScheduleMail - данные писем о назначенном времени собеседования
+type ScheduleMail struct {
+	VacancyTitle string
+	// InterviewURL известна только при записи по ссылке кандидата: токен не хранится
+	InterviewURL          string
+	StartsAt              time.Time
+	EndsAt                time.Time
+	Rescheduled           bool
+	ReschedulesLeft       int
+	RescheduleCutoffHours int
+}
+
+// RenderScheduled собирает подтверждение записи или переноса собеседования
+func RenderScheduled(to string, data ScheduleMail) (MailMessage, error) {
+	subject := "Вы записаны на собеседование: " + data.VacancyTitle
+	if data.Rescheduled {
+		subject = "Собеседование перенесено: " + data.VacancyTitle
+	}
+	return renderMail("interview_scheduled.html", to, subject, data)
+}
+
+// RenderReminder собирает напоминание перед началом собеседования
+func RenderReminder(to string, data ScheduleMail) (MailMessage, error) {
+	return renderMail("interview_reminder.html", to, "Напоминание о собеседовании: "+data.VacancyTitle, data)
+}
 
-	return d.DialAndSend(m)
+// RenderCancellation собирает письмо об отмене назначенного собеседования
+func RenderCancellation(to string, data ScheduleMail) (MailMessage, error) {
+	return renderMail("interview_cancelled.html", to, "Собеседование отменено: "+data.VacancyTitle, data)
 }
 
-func main() {
-	err := SendMail("qwerty.mart8@gmail.com", "http...")
-	if err != nil {
-		log.Printf("Ошибка: %v", err)
-	} else {
-		log.Println("Email успешно отправлен!")
+func renderMail(name, to, subject string, data interface{}) (MailMessage, error) {
+	var body bytes.Buffer
+	if err := mailTemplateSet.ExecuteTemplate(&body, name, data); err != nil {
+		return MailMessage{}, fmt.Errorf("failed to render %s: %w", name, err)
 	}
+	return MailMessage{To: to, Subject: subject, HTMLBody: body.String()}, nil
 }
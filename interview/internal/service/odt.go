@@ -0,0 +1,136 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	odtMimeType = "application/vnd.oasis.opendocument.text"
+	odtTextNS   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+
+	// odtMaxSpaces ограничивает text:c у text:s: значение берется из файла как есть
+	odtMaxSpaces = 100
+
+	// odtMaxContentSize ограничивает распакованный content.xml: маленький архив может распаковаться в гигабайты
+	odtMaxContentSize = 4 * MaxResumeFileSize
+)
+
+// inspectZipDocument возвращает содержимое файла mimetype и признак наличия word/document.xml.
+// Используется для различения DOCX и ODT, которые оба являются ZIP архивами.
+func inspectZipDocument(fileData []byte) (mimeType string, hasWordDocument bool) {
+	zipReader, err := zip.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		return "", false
+	}
+
+	for _, file := range zipReader.File {
+		switch file.Name {
+		case "word/document.xml":
+			hasWordDocument = true
+		case "mimetype":
+			rc, err := file.Open()
+			if err != nil {
+				continue
+			}
+			content, _ := io.ReadAll(io.LimitReader(rc, 256))
+			rc.Close()
+			mimeType = strings.TrimSpace(string(content))
+		}
+	}
+
+	return mimeType, hasWordDocument
+}
+
+// extractFromODT извлекает текст из content.xml документа OpenDocument
+func extractFromODT(fileData []byte) (string, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		return "", fmt.Errorf("не является валидным ZIP архивом: %w", err)
+	}
+
+	for _, file := range zipReader.File {
+		if file.Name != "content.xml" {
+			continue
+		}
+		if file.UncompressedSize64 > odtMaxContentSize {
+			return "", fmt.Errorf("content.xml слишком большой: %d байт", file.UncompressedSize64)
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("не удалось открыть content.xml: %w", err)
+		}
+		defer rc.Close()
+
+		// Размер в заголовке архива можно подделать, поэтому распакованные данные ограничиваются и при чтении
+		text, err := parseODTContent(io.LimitReader(rc, odtMaxContentSize))
+		if err != nil {
+			return "", err
+		}
+		if text == "" {
+			return "", fmt.Errorf("документ не содержит текста")
+		}
+		return text, nil
+	}
+
+	return "", fmt.Errorf("не найден content.xml в архиве")
+}
+
+// parseODTContent обходит XML и собирает текст абзацев, заголовков и ячеек таблиц
+func parseODTContent(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var b strings.Builder
+	depth := 0 // вложенность абзацев: текст вне абзацев - это отступы разметки
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("не удалось разобрать content.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odtTextNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				depth++
+			case "s":
+				count := 1
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "c" {
+						if v, err := strconv.Atoi(attr.Value); err == nil && v > 0 {
+							count = v
+						}
+					}
+				}
+				b.WriteString(strings.Repeat(" ", min(count, odtMaxSpaces)))
+			case "tab":
+				b.WriteByte('\t')
+			case "line-break":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if t.Name.Space == odtTextNS && (t.Name.Local == "p" || t.Name.Local == "h") {
+				depth--
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if depth > 0 {
+				b.Write(t)
+			}
+		}
+	}
+
+	return normalizeExtractedText(b.String()), nil
+}
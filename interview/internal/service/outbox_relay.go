@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"interview/internal/broker"
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+const (
+	outboxBatchSize      = 50
+	outboxPollInterval   = time.Second
+	outboxPublishTimeout = 10 * time.Second
+	outboxRetention      = 7 * 24 * time.Hour // сколько хранить отправленные сообщения
+	outboxMaxAttempts    = 20                 // после стольких ошибок сообщение больше не отправляется
+	outboxRetryDelay     = 5 * time.Second    // задержка перед первым повтором, дальше удваивается
+	outboxMaxDelay       = 30 * time.Minute
+)
+
+// OutboxRelay переносит сообщения из таблицы outbox_messages в RabbitMQ.
+// Сообщение помечается отправленным только после подтверждения брокера, поэтому
+// доставка "хотя бы один раз": при сбое между подтверждением и коммитом сообщение уйдет повторно.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	publisher broker.Publisher
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, publisher broker.Publisher) *OutboxRelay {
+	return &OutboxRelay{repo: repo, publisher: publisher}
+}
+
+// Run отправляет сообщения до отмены ctx
+func (r *OutboxRelay) Run(ctx context.Context) {
+	log.Println("📮 Outbox relay started")
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("✅ Outbox relay stopped")
+			return
+		case <-cleanup.C:
+			if deleted, err := r.repo.DeleteSentBefore(ctx, time.Now().Add(-outboxRetention)); err != nil {
+				log.Printf("⚠️ Failed to clean up outbox: %v", err)
+			} else if deleted > 0 {
+				log.Printf("🧹 Removed %d sent outbox messages", deleted)
+			}
+		case <-ticker.C:
+			err := r.relayPending(ctx)
+			// Пишем в лог только смену состояния, чтобы не засорять его при недоступном брокере
+			if err != nil && !failing {
+				log.Printf("❌ Outbox relay: failed to publish: %v", err)
+			} else if err == nil && failing {
+				log.Println("✅ Outbox relay: publishing resumed")
+			}
+			failing = err != nil
+		}
+	}
+}
+
+// relayPending отправляет накопившиеся сообщения пачками, пока они не закончатся.
+// Ошибка одного сообщения не останавливает пачку: оно откладывается с растущей задержкой.
+func (r *OutboxRelay) relayPending(ctx context.Context) error {
+	for {
+		messages, err := r.repo.ClaimPending(ctx, outboxBatchSize, time.Now().Add(-2*outboxPublishTimeout))
+		if err != nil {
+			return err
+		}
+
+		sent := 0
+		var publishErr error
+		for _, msg := range messages {
+			if err := r.publish(ctx, msg); err != nil {
+				publishErr = err
+				continue
+			}
+			sent++
+		}
+		if sent > 0 {
+			log.Printf("📤 Outbox relay: published %d messages", sent)
+		}
+		if len(messages) < outboxBatchSize {
+			return publishErr
+		}
+	}
+}
+
+// publish отправляет одно сообщение и записывает результат
+func (r *OutboxRelay) publish(ctx context.Context, msg *models.OutboxMessage) error {
+	publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	err := r.publisher.Publish(publishCtx, msg.ID, msg.Payload)
+	cancel()
+
+	if err == nil {
+		if ok, markErr := r.repo.MarkSent(ctx, msg); markErr != nil {
+			log.Printf("❌ Outbox relay: failed to mark message %s as sent: %v", msg.ID, markErr)
+		} else if !ok {
+			log.Printf("⚠️ Outbox relay: message %s was reclaimed before it was marked sent", msg.ID)
+		}
+		return nil
+	}
+
+	attempt := msg.Attempts + 1
+	if attempt >= outboxMaxAttempts {
+		log.Printf("💀 Outbox relay: message %s for %s dropped after %d attempts: %v", msg.ID, msg.AggregateID, attempt, err)
+		if markErr := r.repo.MarkDead(ctx, msg, err.Error()); markErr != nil {
+			log.Printf("❌ Outbox relay: failed to mark message %s as dead: %v", msg.ID, markErr)
+		}
+		return err
+	}
+
+	delay := retryDelay(attempt, outboxRetryDelay, outboxMaxDelay)
+	if markErr := r.repo.MarkRetry(ctx, msg, time.Now().Add(delay), err.Error()); markErr != nil {
+		log.Printf("❌ Outbox relay: failed to reschedule message %s: %v", msg.ID, markErr)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+
+	"interview/internal/models"
+)
+
+// fakeOutboxRepository ведет себя как таблица outbox: выдает только неотправленные сообщения,
+// срок повтора которых наступил
+type fakeOutboxRepository struct {
+	pending []*models.OutboxMessage
+	sent    []string
+	dead    []string
+}
+
+func (r *fakeOutboxRepository) ClaimPending(ctx context.Context, limit int, lockedBefore time.Time) ([]*models.OutboxMessage, error) {
+	var due []*models.OutboxMessage
+	for _, msg := range r.pending {
+		if len(due) < limit && !msg.NextAttemptAt.After(time.Now()) {
+			due = append(due, msg)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeOutboxRepository) remove(msg *models.OutboxMessage) {
+	var rest []*models.OutboxMessage
+	for _, pending := range r.pending {
+		if pending != msg {
+			rest = append(rest, pending)
+		}
+	}
+	r.pending = rest
+}
+
+func (r *fakeOutboxRepository) MarkSent(ctx context.Context, msg *models.OutboxMessage) (bool, error) {
+	r.sent = append(r.sent, msg.ID)
+	r.remove(msg)
+	return true, nil
+}
+
+func (r *fakeOutboxRepository) MarkRetry(ctx context.Context, msg *models.OutboxMessage, nextAttemptAt time.Time, lastError string) error {
+	msg.Attempts++
+	msg.NextAttemptAt, msg.LastError = nextAttemptAt, lastError
+	return nil
+}
+
+func (r *fakeOutboxRepository) MarkDead(ctx context.Context, msg *models.OutboxMessage, lastError string) error {
+	msg.Attempts++
+	msg.LastError = lastError
+	r.dead = append(r.dead, msg.ID)
+	r.remove(msg)
+	return nil
+}
+
+func (r *fakeOutboxRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakePublisher struct {
+	failOn    string
+	published []string
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, messageID string, body []byte) error {
+	if messageID == p.failOn {
+		return errors.New("broker is down")
+	}
+	p.published = append(p.published, messageID)
+	return nil
+}
+
+func (p *fakePublisher) Close() {}
+
+func outboxMessages(count int) []*models.OutboxMessage {
+	messages := make([]*models.OutboxMessage, 0, count)
+	for i := 0; i < count; i++ {
+		messages = append(messages, &models.OutboxMessage{
+			ID:      fmt.Sprintf("msg-%d", i),
+			Payload: datatypes.JSON(`{}`),
+		})
+	}
+	return messages
+}
+
+func TestOutboxRelay_PublishesAllBatches(t *testing.T) {
+	repo := &fakeOutboxRepository{pending: outboxMessages(outboxBatchSize + 3)}
+	publisher := &fakePublisher{}
+
+	err := NewOutboxRelay(repo, publisher).relayPending(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, repo.pending)
+	assert.Len(t, publisher.published, outboxBatchSize+3)
+	assert.Equal(t, publisher.published, repo.sent)
+}
+
+func TestOutboxRelay_SkipsFailedMessage(t *testing.T) {
+	messages := outboxMessages(3)
+	repo := &fakeOutboxRepository{pending: messages}
+	publisher := &fakePublisher{failOn: messages[1].ID}
+
+	err := NewOutboxRelay(repo, publisher).relayPending(context.Background())
+
+	assert.EqualError(t, err, "broker is down")
+	assert.Equal(t, []string{messages[0].ID, messages[2].ID}, repo.sent, "ошибка не останавливает пачку")
+	assert.Len(t, repo.pending, 1, "неотправленное сообщение остается в outbox")
+	assert.Equal(t, 1, messages[1].Attempts)
+	assert.WithinDuration(t, time.Now().Add(outboxRetryDelay), messages[1].NextAttemptAt, time.Second)
+
+	// До истечения задержки сообщение не отправляется повторно
+	publisher.failOn = ""
+	assert.NoError(t, NewOutboxRelay(repo, publisher).relayPending(context.Background()))
+	assert.Len(t, repo.sent, 2)
+
+	messages[1].NextAttemptAt = time.Now()
+	assert.NoError(t, NewOutboxRelay(repo, publisher).relayPending(context.Background()))
+	assert.Equal(t, []string{messages[0].ID, messages[2].ID, messages[1].ID}, repo.sent)
+}
+
+func TestOutboxRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	messages := outboxMessages(1)
+	messages[0].Attempts = outboxMaxAttempts - 1
+	repo := &fakeOutboxRepository{pending: messages}
+	publisher := &fakePublisher{failOn: messages[0].ID}
+
+	err := NewOutboxRelay(repo, publisher).relayPending(context.Background())
+
+	assert.EqualError(t, err, "broker is down")
+	assert.Equal(t, []string{messages[0].ID}, repo.dead)
+	assert.Empty(t, repo.pending)
+	assert.Equal(t, outboxMaxAttempts, messages[0].Attempts)
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ledongthuc/pdf" // BSD License
+)
+
+const (
+	// pdfLineTolerance - доля кегля, в пределах которой символы считаются одной строкой
+	pdfLineTolerance = 0.5
+	// pdfWordGap - разрыв (в кеглях), после которого между символами ставится пробел
+	pdfWordGap = 0.2
+	// pdfSegmentGap - разрыв (в кеглях), после которого строка делится на отдельные блоки
+	pdfSegmentGap = 1.8
+	// pdfGutterMinWidth - минимальная ширина межколоночного промежутка в пунктах
+	pdfGutterMinWidth = 8.0
+	// pdfBucketWidth - шаг гистограммы покрытия страницы текстом в пунктах
+	pdfBucketWidth = 2.0
+)
+
+// pdfChar - символ страницы с координатами в пространстве страницы
+type pdfChar struct {
+	x, y, w, size float64
+	s             string
+}
+
+// pdfSegment - непрерывный фрагмент строки без больших разрывов
+type pdfSegment struct {
+	x0, x1 float64
+	text   string
+}
+
+// pdfLine - строка страницы, разбитая на фрагменты
+type pdfLine struct {
+	y        float64
+	size     float64
+	segments []pdfSegment
+}
+
+// extractFromPDFFree извлекает текст из PDF без внешних утилит.
+// Поддерживаются шрифты с ToUnicode CMap, многоколоночная верстка
+// и файлы, зашифрованные с пустым паролем пользователя.
+func extractFromPDFFree(fileData []byte) (text string, err error) {
+	// Библиотека паникует на некоторых поврежденных файлах
+	defer func() {
+		if r := recover(); r != nil {
+			text = ""
+			err = fmt.Errorf("не удалось разобрать PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		if errors.Is(err, pdf.ErrInvalidPassword) {
+			return "", fmt.Errorf("PDF защищен паролем")
+		}
+		return "", fmt.Errorf("не удалось открыть PDF: %w", err)
+	}
+
+	var pages []string
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		pageText := layoutPDFPage(page.Content().Text)
+		if pageText != "" {
+			pages = append(pages, pageText)
+		}
+	}
+
+	result := strings.TrimSpace(strings.Join(pages, "\n\n"))
+	if result == "" {
+		return "", fmt.Errorf("PDF не содержит извлекаемого текста (возможно, это скан)")
+	}
+
+	return result, nil
+}
+
+// layoutPDFPage восстанавливает порядок чтения страницы:
+// символы собираются в строки, строки - в колонки, колонки читаются слева направо.
+func layoutPDFPage(texts []pdf.Text) string {
+	chars := collectPDFChars(texts)
+	if len(chars) == 0 {
+		return ""
+	}
+
+	lines := groupPDFLines(chars)
+	gutters := findPDFGutters(lines)
+
+	var out []string
+	columns := make([][]string, len(gutters)+1)
+
+	flush := func() {
+		for i, column := range columns {
+			if len(column) > 0 {
+				out = append(out, strings.Join(column, "\n"))
+			}
+			columns[i] = nil
+		}
+	}
+
+	for _, line := range lines {
+		lineColumns := make([][]string, len(gutters)+1)
+		spanning := false
+
+		for _, seg := range line.segments {
+			idx, ok := pdfColumnIndex(seg, gutters)
+			if !ok {
+				spanning = true
+				break
+			}
+			lineColumns[idx] = append(lineColumns[idx], seg.text)
+		}
+
+		// Заголовок во всю ширину разделяет страницу на независимые блоки колонок
+		if spanning {
+			flush()
+			out = append(out, joinPDFSegments(line.segments))
+			continue
+		}
+
+		for i, parts := range lineColumns {
+			if len(parts) > 0 {
+				columns[i] = append(columns[i], strings.Join(parts, " "))
+			}
+		}
+	}
+	flush()
+
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// collectPDFChars отбрасывает управляющие символы и восстанавливает координаты
+// для шрифтов без таблицы ширин (все символы одной строки приходят с одинаковым X)
+func collectPDFChars(texts []pdf.Text) []pdfChar {
+	chars := make([]pdfChar, 0, len(texts))
+
+	var prevX, prevY, cursor float64
+	for i, t := range texts {
+		size := t.FontSize
+		if size <= 0 {
+			size = 10
+		}
+
+		w := t.W
+		if w <= 0 {
+			w = size * 0.5
+		}
+
+		x := t.X
+		if i > 0 && t.W <= 0 && t.X == prevX && t.Y == prevY {
+			x = cursor
+		}
+		prevX, prevY = t.X, t.Y
+		cursor = x + w
+
+		r := []rune(t.S)
+		if len(r) == 0 || unicode.IsControl(r[0]) {
+			continue
+		}
+
+		chars = append(chars, pdfChar{x: x, y: t.Y, w: w, size: size, s: t.S})
+	}
+
+	return chars
+}
+
+// groupPDFLines группирует символы в строки сверху вниз и делит строки на фрагменты
+func groupPDFLines(chars []pdfChar) []pdfLine {
+	sort.SliceStable(chars, func(i, j int) bool {
+		return chars[i].y > chars[j].y
+	})
+
+	var rows [][]pdfChar
+	for _, ch := range chars {
+		n := len(rows)
+		if n > 0 {
+			last := rows[n-1]
+			if math.Abs(last[0].y-ch.y) <= last[0].size*pdfLineTolerance {
+				rows[n-1] = append(last, ch)
+				continue
+			}
+		}
+		rows = append(rows, []pdfChar{ch})
+	}
+
+	lines := make([]pdfLine, 0, len(rows))
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool {
+			return row[i].x < row[j].x
+		})
+
+		line := pdfLine{y: row[0].y, size: row[0].size}
+
+		var b strings.Builder
+		segStart := row[0].x
+		end := row[0].x
+		pendingSpace := false
+
+		for i, ch := range row {
+			isSpace := strings.TrimSpace(ch.s) == ""
+			if i > 0 {
+				gap := ch.x - end
+				if gap > ch.size*pdfSegmentGap {
+					if text := strings.TrimSpace(b.String()); text != "" {
+						line.segments = append(line.segments, pdfSegment{x0: segStart, x1: end, text: text})
+					}
+					b.Reset()
+					segStart = ch.x
+					pendingSpace = false
+				} else if gap > ch.size*pdfWordGap {
+					pendingSpace = true
+				}
+			}
+
+			if isSpace {
+				pendingSpace = true
+				end = math.Max(end, ch.x+ch.w)
+				continue
+			}
+
+			if b.Len() == 0 {
+				segStart = ch.x
+			} else if pendingSpace {
+				b.WriteByte(' ')
+			}
+			pendingSpace = false
+
+			b.WriteString(ch.s)
+			end = math.Max(end, ch.x+ch.w)
+			if ch.size > line.size {
+				line.size = ch.size
+			}
+		}
+
+		if text := strings.TrimSpace(b.String()); text != "" {
+			line.segments = append(line.segments, pdfSegment{x0: segStart, x1: end, text: text})
+		}
+
+		if len(line.segments) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// findPDFGutters ищет вертикальные промежутки, не занятые текстом на большей части страницы
+func findPDFGutters(lines []pdfLine) [][2]float64 {
+	if len(lines) < 3 {
+		return nil
+	}
+
+	minX, maxX := math.MaxFloat64, -math.MaxFloat64
+	var sizes []float64
+	for _, line := range lines {
+		sizes = append(sizes, line.size)
+		for _, seg := range line.segments {
+			minX = math.Min(minX, seg.x0)
+			maxX = math.Max(maxX, seg.x1)
+		}
+	}
+	sort.Float64s(sizes)
+	fontSize := sizes[len(sizes)/2]
+
+	buckets := int(math.Ceil((maxX-minX)/pdfBucketWidth)) + 1
+	if buckets < 3 {
+		return nil
+	}
+
+	coverage := make([]int, buckets)
+	for _, line := range lines {
+		for _, seg := range line.segments {
+			from := int((seg.x0 - minX) / pdfBucketWidth)
+			to := int((seg.x1 - minX) / pdfBucketWidth)
+			for b := from; b <= to && b < buckets; b++ {
+				coverage[b]++
+			}
+		}
+	}
+
+	// Допускаем небольшое количество заголовков, пересекающих промежуток
+	tolerance := len(lines) / 10
+	if tolerance < 1 {
+		tolerance = 1
+	}
+
+	var gutters [][2]float64
+	for b := 0; b < buckets; {
+		if coverage[b] > tolerance {
+			b++
+			continue
+		}
+
+		start := b
+		for b < buckets && coverage[b] <= tolerance {
+			b++
+		}
+
+		// Промежуток у края страницы - это поля, а не разделитель колонок
+		if start == 0 || b >= buckets {
+			continue
+		}
+
+		gutter := [2]float64{
+			minX + float64(start)*pdfBucketWidth,
+			minX + float64(b)*pdfBucketWidth,
+		}
+		if gutter[1]-gutter[0] < pdfGutterMinWidth {
+			continue
+		}
+
+		if isPDFColumnGutter(lines, gutter, minX, maxX, fontSize) {
+			gutters = append(gutters, gutter)
+		}
+	}
+
+	return gutters
+}
+
+// isPDFColumnGutter отличает настоящие колонки от таблиц "ключ - значение":
+// в таблице почти каждая строка занимает обе стороны промежутка
+func isPDFColumnGutter(lines []pdfLine, gutter [2]float64, minX, maxX, fontSize float64) bool {
+	var both, sided int
+	for _, line := range lines {
+		left, right := false, false
+		for _, seg := range line.segments {
+			if seg.x1 <= gutter[0] {
+				left = true
+			} else if seg.x0 >= gutter[1] {
+				right = true
+			}
+		}
+		if left || right {
+			sided++
+		}
+		if left && right {
+			both++
+		}
+	}
+
+	if sided == 0 {
+		return false
+	}
+	if float64(both)/float64(sided) <= 0.6 {
+		return true
+	}
+
+	width := maxX - minX
+	wide := gutter[1]-gutter[0] >= fontSize*3
+	balanced := gutter[0]-minX >= width*0.15 && maxX-gutter[1] >= width*0.15
+	return wide && balanced
+}
+
+// pdfColumnIndex возвращает номер колонки фрагмента или false, если фрагмент пересекает промежуток
+func pdfColumnIndex(seg pdfSegment, gutters [][2]float64) (int, bool) {
+	idx := 0
+	for _, g := range gutters {
+		if seg.x1 <= g[0]+pdfBucketWidth {
+			return idx, true
+		}
+		if seg.x0 < g[1]-pdfBucketWidth {
+			return 0, false
+		}
+		idx++
+	}
+	return idx, true
+}
+
+func joinPDFSegments(segments []pdfSegment) string {
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		parts = append(parts, seg.text)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestExtractFromPDF_TwoColumns(t *testing.T) {
+	data := readFixture(t, "two_columns.pdf")
+
+	text, err := ExtractTextFromFile(data, "two_columns.pdf")
+	require.NoError(t, err)
+
+	lines := strings.Split(text, "\n")
+	assert.Equal(t, "John Smith - Senior Go Developer, Moscow, john.smith@example.com", lines[0])
+
+	// Левая колонка должна быть прочитана целиком до правой
+	skills := strings.Index(text, "SKILLS")
+	languages := strings.Index(text, "English B2")
+	experience := strings.Index(text, "EXPERIENCE")
+	require.True(t, skills >= 0 && languages >= 0 && experience >= 0, text)
+	assert.Less(t, skills, languages)
+	assert.Less(t, languages, experience)
+	assert.Contains(t, text, "2019 - 2024 Acme Corp\nBackend developer")
+}
+
+func TestExtractFromPDF_ToUnicodeCyrillic(t *testing.T) {
+	data := readFixture(t, "cyrillic_tounicode.pdf")
+
+	text, err := ExtractTextFromFile(data, "cyrillic_tounicode.pdf")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Иванов Иван Иванович\nОпыт работы\nРазработчик Go", text)
+}
+
+func TestExtractFromPDF_EncryptedWithEmptyPassword(t *testing.T) {
+	data := readFixture(t, "encrypted.pdf")
+
+	text, err := ExtractTextFromFile(data, "encrypted.pdf")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Confidential resume\nPython developer, 5 years", text)
+}
+
+func TestExtractFromPDF_Broken(t *testing.T) {
+	_, err := ExtractTextFromFile([]byte("%PDF-1.4\nnot really a pdf"), "broken.pdf")
+	assert.Error(t, err)
+}
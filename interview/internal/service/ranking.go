@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// interviewShare - доля оценки собеседования в компоненте, если собеседование пройдено.
+// Собеседование проверяет навыки вживую, поэтому весит больше анализа резюме.
+const interviewShare = 0.6
+
+var ErrInvalidShortlist = errors.New("invalid shortlist options")
+
+// ShortlistOptions - правила автоматического отбора. Если заданы оба, кандидат должен пройти оба.
+type ShortlistOptions struct {
+	Top      int      // первые N кандидатов рейтинга, 0 - без ограничения
+	MinScore *float64 // минимальный итоговый балл
+}
+
+// ComponentScore - оценка одного компонента (soft, hard, case)
+type ComponentScore struct {
+	Weight    int      `json:"weight"`
+	CV        *float64 `json:"cv,omitempty"`
+	Interview *float64 `json:"interview,omitempty"`
+	Combined  float64  `json:"combined"`
+}
+
+// CandidateRanking - позиция кандидата в рейтинге вакансии
+type CandidateRanking struct {
+	Rank           int                 `json:"rank"`
+	ResumeID       string              `json:"resume_id"`
+	FullName       string              `json:"full_name,omitempty"`
+	Mail           string              `json:"mail,omitempty"`
+	Status         models.ResumeStatus `json:"status"`
+	Total          float64             `json:"total"`
+	CVScore        *float64            `json:"cv_score,omitempty"`
+	InterviewScore *float64            `json:"interview_score,omitempty"`
+	Interviewed    bool                `json:"interviewed"`
+	Soft           ComponentScore      `json:"soft"`
+	Hard           ComponentScore      `json:"hard"`
+	Case           ComponentScore      `json:"case"`
+	Shortlisted    bool                `json:"shortlisted"`
+}
+
+// VacancyRanking - рейтинг кандидатов вакансии
+type VacancyRanking struct {
+	VacancyID      string              `json:"vacancy_id"`
+	Candidates     []*CandidateRanking `json:"candidates"`
+	ShortlistCount int                 `json:"shortlist_count"`
+}
+
+type RankingService interface {
+	// Rank ранжирует кандидатов вакансии и отмечает попавших в шорт-лист
+	Rank(ctx context.Context, vacancyID string, opts ShortlistOptions) (*VacancyRanking, error)
+	// RankWithWeights ранжирует кандидатов с заданными весами вместо текущих весов вакансии
+	RankWithWeights(ctx context.Context, vacancyID string, weights models.VacancyWeights) (*VacancyRanking, error)
+}
+
+type rankingService struct {
+	resumes   repository.ResumeRepository
+	vacancies repository.VacancyRepository
+}
+
+func NewRankingService(resumes repository.ResumeRepository, vacancies repository.VacancyRepository) RankingService {
+	return &rankingService{resumes: resumes, vacancies: vacancies}
+}
+
+func (s *rankingService) Rank(ctx context.Context, vacancyID string, opts ShortlistOptions) (*VacancyRanking, error) {
+	if opts.Top < 0 {
+		return nil, fmt.Errorf("%w: top must not be negative", ErrInvalidShortlist)
+	}
+
+	vacancy, err := s.vacancies.GetByID(ctx, vacancyID)
+	if err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+	return s.rank(ctx, vacancy, opts)
+}
+
+func (s *rankingService) RankWithWeights(ctx context.Context, vacancyID string, weights models.VacancyWeights) (*VacancyRanking, error) {
+	vacancy, err := s.vacancies.GetByID(ctx, vacancyID)
+	if err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+	vacancy.WeightSoft, vacancy.WeightHard, vacancy.WeightCase = weights.Soft, weights.Hard, weights.Case
+	return s.rank(ctx, vacancy, ShortlistOptions{})
+}
+
+func (s *rankingService) rank(ctx context.Context, vacancy *models.Vacancy, opts ShortlistOptions) (*VacancyRanking, error) {
+	scores, err := s.resumes.GetScoresByVacancy(ctx, vacancy.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load resume scores: %w", err)
+	}
+
+	ranking := &VacancyRanking{VacancyID: vacancy.ID, Candidates: []*CandidateRanking{}}
+	for _, resume := range scores {
+		if candidate := rankCandidate(resume, vacancy); candidate != nil {
+			ranking.Candidates = append(ranking.Candidates, candidate)
+		}
+	}
+
+	sortCandidates(ranking.Candidates)
+	for i, candidate := range ranking.Candidates {
+		candidate.Rank = i + 1
+		candidate.Shortlisted = opts.matches(candidate)
+		if candidate.Shortlisted {
+			ranking.ShortlistCount++
+		}
+	}
+	return ranking, nil
+}
+
+func (o ShortlistOptions) matches(candidate *CandidateRanking) bool {
+	if o.Top == 0 && o.MinScore == nil {
+		return false
+	}
+	if o.Top > 0 && candidate.Rank > o.Top {
+		return false
+	}
+	return o.MinScore == nil || candidate.Total >= *o.MinScore
+}
+
+// sortCandidates - по итоговому баллу, при равенстве выше прошедшие собеседование, затем более ранние отклики
+func sortCandidates(candidates []*CandidateRanking) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Interviewed && !b.Interviewed
+	})
+}
+
+// cvAnalysis - нужные для рейтинга поля ответа CV-review сервиса
+type cvAnalysis struct {
+	DetailedEvaluation struct {
+		CommunicationSkills scoreField `json:"communication_skills"`
+		PrimarySkills       scoreField `json:"primary_skills"`
+		WorkExperience      scoreField `json:"work_experience"`
+	} `json:"detailed_evaluation"`
+	OverallAssessment struct {
+		FinalScore *float64 `json:"final_score"`
+	} `json:"overall_assessment"`
+}
+
+// interviewResult - нужные для рейтинга поля итогового анализа собеседования
+type interviewResult struct {
+	OverallAssessment struct {
+		Soft  scoreField `json:"soft"`
+		Hard  scoreField `json:"hard"`
+		Case  scoreField `json:"case"`
+		Final scoreField `json:"final"`
+	} `json:"overall_assessment"`
+}
+
+type scoreField struct {
+	Score *float64 `json:"score"`
+}
+
+// rankCandidate считает оценки кандидата. Возвращает nil, если оценок нет.
+func rankCandidate(resume *models.ResumeScores, vacancy *models.Vacancy) *CandidateRanking {
+	var cv cvAnalysis
+	var interview interviewResult
+	if len(resume.ResumeAnalysisJSONB) > 0 {
+		_ = json.Unmarshal(resume.ResumeAnalysisJSONB, &cv)
+	}
+	if len(resume.ResultJSONB) > 0 {
+		_ = json.Unmarshal(resume.ResultJSONB, &interview)
+	}
+
+	candidate := &CandidateRanking{
+		ResumeID:       resume.ID,
+		FullName:       resume.FullName,
+		Mail:           resume.Mail,
+		Status:         resume.Status,
+		CVScore:        clampScore(cv.OverallAssessment.FinalScore),
+		InterviewScore: clampScore(interview.OverallAssessment.Final.Score),
+	}
+	candidate.Soft = combineScores(vacancy.WeightSoft, cv.DetailedEvaluation.CommunicationSkills.Score, interview.OverallAssessment.Soft.Score, candidate)
+	candidate.Hard = combineScores(vacancy.WeightHard, cv.DetailedEvaluation.PrimarySkills.Score, interview.OverallAssessment.Hard.Score, candidate)
+	candidate.Case = combineScores(vacancy.WeightCase, cv.DetailedEvaluation.WorkExperience.Score, interview.OverallAssessment.Case.Score, candidate)
+
+	components := []ComponentScore{candidate.Soft, candidate.Hard, candidate.Case}
+	rated := false
+	for _, c := range components {
+		if c.CV != nil || c.Interview != nil {
+			rated = true
+		}
+	}
+	if !rated {
+		return nil
+	}
+	for _, c := range components {
+		if c.Interview != nil {
+			candidate.Interviewed = true
+		}
+	}
+
+	// Если все веса нулевые, компоненты равнозначны
+	totalWeight := vacancy.WeightSoft + vacancy.WeightHard + vacancy.WeightCase
+	var total float64
+	for _, c := range components {
+		if totalWeight == 0 {
+			total += c.Combined / float64(len(components))
+		} else {
+			total += c.Combined * float64(c.Weight) / float64(totalWeight)
+		}
+	}
+	candidate.Total = roundScore(total)
+	return candidate
+}
+
+// combineScores объединяет оценку компонента из резюме и собеседования.
+// Если компонент не оценен, берется общий балл соответствующего источника.
+func combineScores(weight int, cv, interview *float64, candidate *CandidateRanking) ComponentScore {
+	c := ComponentScore{Weight: weight, CV: clampScore(cv), Interview: clampScore(interview)}
+	if c.CV == nil {
+		c.CV = candidate.CVScore
+	}
+	if c.Interview == nil {
+		c.Interview = candidate.InterviewScore
+	}
+
+	switch {
+	case c.CV != nil && c.Interview != nil:
+		c.Combined = (1-interviewShare)*(*c.CV) + interviewShare*(*c.Interview)
+	case c.Interview != nil:
+		c.Combined = *c.Interview
+	case c.CV != nil:
+		c.Combined = *c.CV
+	}
+	c.Combined = roundScore(c.Combined)
+	return c
+}
+
+// clampScore ограничивает балл диапазоном 0..100: модели иногда выходят за шкалу
+func clampScore(score *float64) *float64 {
+	if score == nil {
+		return nil
+	}
+	v := math.Max(0, math.Min(100, *score))
+	return &v
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10) / 10
+}
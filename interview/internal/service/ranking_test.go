@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeRankingResumes struct {
+	repository.ResumeRepository
+	scores []*models.ResumeScores
+}
+
+func (r *fakeRankingResumes) GetScoresByVacancy(ctx context.Context, vacancyID string) ([]*models.ResumeScores, error) {
+	return r.scores, nil
+}
+
+type fakeRankingVacancies struct {
+	repository.VacancyRepository
+	vacancy *models.Vacancy
+}
+
+func (r *fakeRankingVacancies) GetByID(ctx context.Context, id string) (*models.Vacancy, error) {
+	return r.vacancy, nil
+}
+
+const cvReview = `{
+	"detailed_evaluation": {
+		"communication_skills": {"score": 80},
+		"primary_skills": {"score": 90},
+		"work_experience": {"score": 70}
+	},
+	"overall_assessment": {"final_score": 82}
+}`
+
+const interviewReview = `{
+	"overall_assessment": {
+		"soft": {"score": 60},
+		"hard": {"score": 85},
+		"case": {"score": 70},
+		"final": {"score": 75}
+	}
+}`
+
+func TestRankCandidate(t *testing.T) {
+	vacancy := &models.Vacancy{WeightSoft: 20, WeightHard: 50, WeightCase: 30}
+
+	t.Run("только анализ резюме", func(t *testing.T) {
+		c := rankCandidate(&models.ResumeScores{ID: "r", ResumeAnalysisJSONB: datatypes.JSON(cvReview)}, vacancy)
+		require.NotNil(t, c)
+
+		assert.False(t, c.Interviewed)
+		assert.Equal(t, 90.0, c.Hard.Combined)
+		// 0.2*80 + 0.5*90 + 0.3*70
+		assert.Equal(t, 82.0, c.Total)
+	})
+
+	t.Run("резюме и собеседование", func(t *testing.T) {
+		c := rankCandidate(&models.ResumeScores{
+			ID:                  "r",
+			ResumeAnalysisJSONB: datatypes.JSON(cvReview),
+			ResultJSONB:         datatypes.JSON(interviewReview),
+		}, vacancy)
+		require.NotNil(t, c)
+
+		assert.True(t, c.Interviewed)
+		assert.Equal(t, 68.0, c.Soft.Combined) // 0.4*80 + 0.6*60
+		assert.Equal(t, 87.0, c.Hard.Combined) // 0.4*90 + 0.6*85
+		assert.Equal(t, 70.0, c.Case.Combined)
+		assert.Equal(t, 78.1, c.Total) // 0.2*68 + 0.5*87 + 0.3*70
+		assert.Equal(t, 75.0, *c.InterviewScore)
+	})
+
+	t.Run("нет разбивки - берется итоговый балл", func(t *testing.T) {
+		c := rankCandidate(&models.ResumeScores{ID: "r", ResumeAnalysisJSONB: datatypes.JSON(`{"overall_assessment":{"final_score":64}}`)}, vacancy)
+		require.NotNil(t, c)
+
+		assert.Equal(t, 64.0, c.Soft.Combined)
+		assert.Equal(t, 64.0, c.Total)
+	})
+
+	t.Run("нулевые веса - компоненты равнозначны", func(t *testing.T) {
+		c := rankCandidate(&models.ResumeScores{ID: "r", ResumeAnalysisJSONB: datatypes.JSON(cvReview)}, &models.Vacancy{})
+		require.NotNil(t, c)
+
+		assert.Equal(t, 80.0, c.Total)
+	})
+
+	t.Run("без оценок не ранжируется", func(t *testing.T) {
+		assert.Nil(t, rankCandidate(&models.ResumeScores{ID: "r", ResumeAnalysisJSONB: datatypes.JSON(`{"error":"timeout"}`)}, vacancy))
+	})
+}
+
+func TestRank_Shortlist(t *testing.T) {
+	score := func(id string, final int) *models.ResumeScores {
+		return &models.ResumeScores{
+			ID:                  id,
+			CreatedAt:           time.Now(),
+			ResumeAnalysisJSONB: datatypes.JSON(fmt.Sprintf(`{"overall_assessment":{"final_score":%d}}`, final)),
+		}
+	}
+	svc := NewRankingService(
+		&fakeRankingResumes{scores: []*models.ResumeScores{score("a", 60), score("b", 90), score("c", 75), score("d", 75)}},
+		&fakeRankingVacancies{vacancy: &models.Vacancy{WeightSoft: 33, WeightHard: 33, WeightCase: 34}},
+	)
+	min := 70.0
+
+	tests := []struct {
+		name string
+		opts ShortlistOptions
+		want []string
+	}{
+		{"без правил", ShortlistOptions{}, nil},
+		{"первые N", ShortlistOptions{Top: 2}, []string{"b", "c"}},
+		{"порог", ShortlistOptions{MinScore: &min}, []string{"b", "c", "d"}},
+		{"первые N и порог", ShortlistOptions{Top: 10, MinScore: &min}, []string{"b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranking, err := svc.Rank(context.Background(), "vacancy", tt.opts)
+			require.NoError(t, err)
+
+			var order, shortlisted []string
+			for _, c := range ranking.Candidates {
+				order = append(order, c.ResumeID)
+				if c.Shortlisted {
+					shortlisted = append(shortlisted, c.ResumeID)
+				}
+			}
+			assert.Equal(t, []string{"b", "c", "d", "a"}, order, "при равном балле выше ранний отклик")
+			assert.Equal(t, tt.want, shortlisted)
+			assert.Equal(t, len(tt.want), ranking.ShortlistCount)
+		})
+	}
+}
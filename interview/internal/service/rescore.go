@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// RescoreLockKey - ключ advisory-блокировки, которой выбирается реплика для пересчета оценок
+const RescoreLockKey int64 = 72_010_014
+
+const (
+	rescorePollInterval     = 10 * time.Second
+	rescoreProgressInterval = 20 // сохранять прогресс каждые N резюме
+)
+
+var (
+	ErrRescoreJobNotFound    = errors.New("rescore job not found")
+	ErrRescoreJobNotFinished = errors.New("rescore job is not finished")
+)
+
+// rescoreOutcome - что было сделано с одним резюме
+type rescoreOutcome int
+
+const (
+	rescoreRecomputed rescoreOutcome = iota
+	rescoreRepublished
+	rescoreSkipped
+)
+
+// RankingChange - изменение позиции кандидата между двумя рейтингами
+type RankingChange struct {
+	ResumeID string   `json:"resume_id"`
+	FullName string   `json:"full_name,omitempty"`
+	OldRank  *int     `json:"old_rank,omitempty"`
+	NewRank  *int     `json:"new_rank,omitempty"`
+	OldTotal *float64 `json:"old_total,omitempty"`
+	NewTotal *float64 `json:"new_total,omitempty"`
+	// RankDelta > 0 - кандидат поднялся в рейтинге
+	RankDelta int `json:"rank_delta"`
+}
+
+// RankingDiff - сравнение рейтинга до пересчета с рейтингом после него
+type RankingDiff struct {
+	JobID     string          `json:"job_id"`
+	VacancyID string          `json:"vacancy_id"`
+	Live      bool            `json:"live"` // сравнение с текущим рейтингом, а не со снимком после пересчета
+	Moved     int             `json:"moved"`
+	Changes   []RankingChange `json:"changes"`
+}
+
+type RescoreService interface {
+	// Schedule ставит пересчет оценок вакансии после изменения весов
+	Schedule(ctx context.Context, vacancyID string, oldWeights, newWeights models.VacancyWeights) (*models.VacancyRescoreJob, error)
+	// Run выполняет задания пересчета по одному до отмены ctx. Работает только на реплике, удерживающей LeaderLock.
+	Run(ctx context.Context)
+	ListJobs(ctx context.Context, vacancyID string) ([]*models.VacancyRescoreJob, error)
+	GetJob(ctx context.Context, vacancyID, jobID string) (*models.VacancyRescoreJob, error)
+	// Diff сравнивает рейтинг до пересчета со снимком после него или, если live, с текущим рейтингом
+	Diff(ctx context.Context, vacancyID, jobID string, live bool) (*RankingDiff, error)
+}
+
+type rescoreService struct {
+	jobs      repository.RescoreJobRepository
+	resumes   repository.ResumeRepository
+	resumeSvc ResumeService
+	ranking   RankingService
+	leader    LeaderLock
+	wake      chan struct{}
+}
+
+func NewRescoreService(
+	jobs repository.RescoreJobRepository,
+	resumes repository.ResumeRepository,
+	resumeSvc ResumeService,
+	ranking RankingService,
+	leader LeaderLock,
+) RescoreService {
+	return &rescoreService{
+		jobs:      jobs,
+		resumes:   resumes,
+		resumeSvc: resumeSvc,
+		ranking:   ranking,
+		leader:    leader,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func (s *rescoreService) Schedule(ctx context.Context, vacancyID string, oldWeights, newWeights models.VacancyWeights) (*models.VacancyRescoreJob, error) {
+	job := &models.VacancyRescoreJob{
+		VacancyID:     vacancyID,
+		Status:        models.RescoreStatusPending,
+		OldWeightSoft: oldWeights.Soft,
+		OldWeightHard: oldWeights.Hard,
+		OldWeightCase: oldWeights.Case,
+		WeightSoft:    newWeights.Soft,
+		WeightHard:    newWeights.Hard,
+		WeightCase:    newWeights.Case,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create rescore job: %w", err)
+	}
+	log.Printf("⚖️ Rescore %s scheduled for vacancy %s: %+v -> %+v", job.ID, vacancyID, oldWeights, newWeights)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (s *rescoreService) Run(ctx context.Context) {
+	ticker := time.NewTicker(rescorePollInterval)
+	defer ticker.Stop()
+
+	leading := false
+	for {
+		isLeader, err := s.leader.IsLeader(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Rescore: leader election failed: %v", err)
+		}
+		if isLeader && !leading {
+			// Прежний лидер упал или потерял соединение - его задания больше никто не выполняет
+			log.Println("👑 Rescore: this replica is the leader")
+			s.resetRunning(ctx)
+		} else if !isLeader && leading {
+			log.Println("⏸️ Rescore: leadership lost")
+		}
+		leading = isLeader
+
+		if isLeader {
+			s.runPending(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			if leading {
+				if err := s.leader.Resign(context.Background()); err != nil {
+					log.Printf("⚠️ Rescore: failed to resign: %v", err)
+				}
+			}
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *rescoreService) resetRunning(ctx context.Context) {
+	if n, err := s.jobs.ResetRunning(ctx); err != nil {
+		log.Printf("❌ Failed to recover rescore jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("🔁 Recovered %d interrupted rescore jobs", n)
+	}
+}
+
+// runPending выполняет ожидающие задания, пока они не закончатся
+func (s *rescoreService) runPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.jobs.ClaimNext(ctx)
+		if err != nil {
+			log.Printf("❌ Failed to claim rescore job: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		s.runJob(ctx, job)
+	}
+}
+
+// runJob пересчитывает все резюме вакансии. Прерванное остановкой сервиса задание
+// остается в running и после перезапуска выполняется заново: пересчет идемпотентен.
+func (s *rescoreService) runJob(ctx context.Context, job *models.VacancyRescoreJob) {
+	log.Printf("⚖️ Rescore %s started for vacancy %s", job.ID, job.VacancyID)
+
+	if len(job.RankingBefore) == 0 {
+		before, err := s.ranking.RankWithWeights(ctx, job.VacancyID, job.OldWeights())
+		if err != nil {
+			s.failJob(job, fmt.Errorf("failed to rank with old weights: %w", err))
+			return
+		}
+		job.RankingBefore = rankingSnapshot(before)
+	}
+
+	resumes, err := s.resumes.GetByVacancy(ctx, job.VacancyID)
+	if err != nil {
+		s.failJob(job, fmt.Errorf("failed to load resumes: %w", err))
+		return
+	}
+
+	job.Total = len(resumes)
+	job.Processed, job.Recomputed, job.Republished, job.Skipped, job.Failed = 0, 0, 0, 0, 0
+	s.saveJob(job)
+
+	weights := job.NewWeights()
+	for i, resume := range resumes {
+		if ctx.Err() != nil {
+			log.Printf("⏸️ Rescore %s interrupted at %d/%d", job.ID, job.Processed, job.Total)
+			return
+		}
+
+		outcome, err := s.rescoreResume(ctx, resume, weights)
+		switch {
+		case err != nil:
+			job.Failed++
+			log.Printf("⚠️ Rescore %s: resume %s failed: %v", job.ID, resume.ID, err)
+		case outcome == rescoreRecomputed:
+			job.Recomputed++
+		case outcome == rescoreRepublished:
+			job.Republished++
+		default:
+			job.Skipped++
+		}
+		job.Processed++
+
+		if (i+1)%rescoreProgressInterval == 0 {
+			s.saveJob(job)
+		}
+	}
+
+	after, err := s.ranking.RankWithWeights(ctx, job.VacancyID, weights)
+	if err != nil {
+		s.failJob(job, fmt.Errorf("failed to rank with new weights: %w", err))
+		return
+	}
+	job.RankingAfter = rankingSnapshot(after)
+
+	finishedAt := time.Now()
+	job.Status = models.RescoreStatusCompleted
+	job.FinishedAt = &finishedAt
+	s.saveJob(job)
+
+	log.Printf("✅ Rescore %s finished: %d recomputed, %d republished, %d skipped, %d failed",
+		job.ID, job.Recomputed, job.Republished, job.Skipped, job.Failed)
+}
+
+// rescoreResume пересчитывает итоговый балл анализа по сохраненным оценкам компонентов.
+// Если оценок компонентов нет, отправляет резюме на повторный анализ.
+func (s *rescoreService) rescoreResume(ctx context.Context, resume *models.Resume, weights models.VacancyWeights) (rescoreOutcome, error) {
+	var analysis map[string]interface{}
+	if len(resume.ResumeAnalysisJSONB) > 0 {
+		_ = json.Unmarshal(resume.ResumeAnalysisJSONB, &analysis)
+	}
+
+	if recomputeFinalScore(analysis, weights) {
+		if err := s.resumes.UpdateAnalysis(ctx, resume.ID, analysis); err != nil {
+			return 0, err
+		}
+		return rescoreRecomputed, nil
+	}
+
+	// Резюме без текста еще обрабатывается и уйдет на анализ уже с новыми весами
+	if resume.Text == "" || resume.Status == models.ResumeStatusError {
+		return rescoreSkipped, nil
+	}
+	if err := s.resumeSvc.RepublishResume(ctx, resume.ID); err != nil {
+		return 0, err
+	}
+	return rescoreRepublished, nil
+}
+
+// recomputeFinalScore пересчитывает overall_assessment.final_score анализа резюме с новыми весами.
+// Возвращает false, если в анализе нет оценок всех трех компонентов.
+func recomputeFinalScore(analysis map[string]interface{}, weights models.VacancyWeights) bool {
+	detailed, _ := analysis["detailed_evaluation"].(map[string]interface{})
+	soft, okSoft := componentScore(detailed, "communication_skills")
+	hard, okHard := componentScore(detailed, "primary_skills")
+	experience, okCase := componentScore(detailed, "work_experience")
+	if !okSoft || !okHard || !okCase {
+		return false
+	}
+
+	var final float64
+	if total := weights.Soft + weights.Hard + weights.Case; total == 0 {
+		final = (soft + hard + experience) / 3
+	} else {
+		final = (soft*float64(weights.Soft) + hard*float64(weights.Hard) + experience*float64(weights.Case)) / float64(total)
+	}
+
+	overall, ok := analysis["overall_assessment"].(map[string]interface{})
+	if !ok {
+		overall = map[string]interface{}{}
+		analysis["overall_assessment"] = overall
+	}
+	// Исходная оценка модели сохраняется при первом пересчете
+	if _, saved := overall["original_final_score"]; !saved {
+		if original, ok := overall["final_score"]; ok {
+			overall["original_final_score"] = original
+		}
+	}
+	overall["final_score"] = math.Round(final*10) / 10
+	overall["weights"] = weights
+	overall["rescored_at"] = time.Now()
+	return true
+}
+
+func componentScore(detailed map[string]interface{}, name string) (float64, bool) {
+	component, _ := detailed[name].(map[string]interface{})
+	score, ok := component["score"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return math.Max(0, math.Min(100, score)), true
+}
+
+func (s *rescoreService) saveJob(job *models.VacancyRescoreJob) {
+	if err := s.jobs.Update(context.Background(), job); err != nil {
+		log.Printf("❌ Failed to update rescore job %s: %v", job.ID, err)
+	}
+}
+
+func (s *rescoreService) failJob(job *models.VacancyRescoreJob, err error) {
+	log.Printf("❌ Rescore %s failed: %v", job.ID, err)
+	finishedAt := time.Now()
+	job.Status = models.RescoreStatusFailed
+	job.Error = err.Error()
+	job.FinishedAt = &finishedAt
+	s.saveJob(job)
+}
+
+func (s *rescoreService) ListJobs(ctx context.Context, vacancyID string) ([]*models.VacancyRescoreJob, error) {
+	return s.jobs.ListByVacancy(ctx, vacancyID)
+}
+
+func (s *rescoreService) GetJob(ctx context.Context, vacancyID, jobID string) (*models.VacancyRescoreJob, error) {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRescoreJobNotFound, err)
+	}
+	if job.VacancyID != vacancyID {
+		return nil, ErrRescoreJobNotFound
+	}
+	return job, nil
+}
+
+func (s *rescoreService) Diff(ctx context.Context, vacancyID, jobID string, live bool) (*RankingDiff, error) {
+	job, err := s.GetJob(ctx, vacancyID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, after []models.RankingEntry
+	if err := unmarshalSnapshot(job.RankingBefore, &before); err != nil {
+		return nil, err
+	}
+
+	if live {
+		current, err := s.ranking.Rank(ctx, vacancyID, ShortlistOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if err := unmarshalSnapshot(rankingSnapshot(current), &after); err != nil {
+			return nil, err
+		}
+	} else {
+		if job.Status != models.RescoreStatusCompleted {
+			return nil, fmt.Errorf("%w: status %s, use live diff", ErrRescoreJobNotFinished, job.Status)
+		}
+		if err := unmarshalSnapshot(job.RankingAfter, &after); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := &RankingDiff{JobID: job.ID, VacancyID: vacancyID, Live: live, Changes: diffRankings(before, after)}
+	for _, change := range diff.Changes {
+		if change.RankDelta != 0 || change.OldRank == nil || change.NewRank == nil {
+			diff.Moved++
+		}
+	}
+	return diff, nil
+}
+
+func rankingSnapshot(ranking *VacancyRanking) datatypes.JSON {
+	entries := make([]models.RankingEntry, 0, len(ranking.Candidates))
+	for _, c := range ranking.Candidates {
+		entries = append(entries, models.RankingEntry{
+			ResumeID: c.ResumeID,
+			FullName: c.FullName,
+			Rank:     c.Rank,
+			Total:    c.Total,
+		})
+	}
+	data, _ := json.Marshal(entries)
+	return datatypes.JSON(data)
+}
+
+func unmarshalSnapshot(data datatypes.JSON, entries *[]models.RankingEntry) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, entries); err != nil {
+		return fmt.Errorf("invalid ranking snapshot: %w", err)
+	}
+	return nil
+}
+
+// diffRankings сопоставляет кандидатов двух рейтингов. Порядок - по новому рейтингу,
+// выбывшие из рейтинга в конце.
+func diffRankings(before, after []models.RankingEntry) []RankingChange {
+	changes := make(map[string]*RankingChange)
+	var order []string
+	get := func(entry models.RankingEntry) *RankingChange {
+		change, ok := changes[entry.ResumeID]
+		if !ok {
+			change = &RankingChange{ResumeID: entry.ResumeID, FullName: entry.FullName}
+			changes[entry.ResumeID] = change
+			order = append(order, entry.ResumeID)
+		}
+		return change
+	}
+
+	for _, entry := range before {
+		change := get(entry)
+		rank, total := entry.Rank, entry.Total
+		change.OldRank, change.OldTotal = &rank, &total
+	}
+	for _, entry := range after {
+		change := get(entry)
+		rank, total := entry.Rank, entry.Total
+		change.NewRank, change.NewTotal = &rank, &total
+		if entry.FullName != "" {
+			change.FullName = entry.FullName
+		}
+	}
+
+	result := make([]RankingChange, 0, len(order))
+	for _, id := range order {
+		change := changes[id]
+		if change.OldRank != nil && change.NewRank != nil {
+			change.RankDelta = *change.OldRank - *change.NewRank
+		}
+		result = append(result, *change)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].NewRank, result[j].NewRank
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return *a < *b
+	})
+	return result
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type fakeRescoreResumes struct {
+	repository.ResumeRepository
+	analyses map[string]map[string]interface{}
+}
+
+func (r *fakeRescoreResumes) UpdateAnalysis(ctx context.Context, id string, analysis map[string]interface{}) error {
+	r.analyses[id] = analysis
+	return nil
+}
+
+type fakeRepublisher struct {
+	ResumeService
+	republished []string
+}
+
+func (s *fakeRepublisher) RepublishResume(ctx context.Context, id string) error {
+	s.republished = append(s.republished, id)
+	return nil
+}
+
+func TestRecomputeFinalScore(t *testing.T) {
+	parse := func(raw string) map[string]interface{} {
+		var analysis map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &analysis))
+		return analysis
+	}
+
+	t.Run("пересчет с новыми весами", func(t *testing.T) {
+		analysis := parse(cvReview)
+		require.True(t, recomputeFinalScore(analysis, models.VacancyWeights{Soft: 50, Hard: 25, Case: 25}))
+
+		overall := analysis["overall_assessment"].(map[string]interface{})
+		assert.Equal(t, 80.0, overall["final_score"]) // 0.5*80 + 0.25*90 + 0.25*70
+		assert.Equal(t, 82.0, overall["original_final_score"])
+	})
+
+	t.Run("исходная оценка не перезаписывается", func(t *testing.T) {
+		analysis := parse(cvReview)
+		recomputeFinalScore(analysis, models.VacancyWeights{Soft: 50, Hard: 25, Case: 25})
+		recomputeFinalScore(analysis, models.VacancyWeights{Soft: 0, Hard: 100, Case: 0})
+
+		overall := analysis["overall_assessment"].(map[string]interface{})
+		assert.Equal(t, 90.0, overall["final_score"])
+		assert.Equal(t, 82.0, overall["original_final_score"])
+	})
+
+	t.Run("нет оценки компонента", func(t *testing.T) {
+		analysis := parse(`{"detailed_evaluation":{"communication_skills":{"score":80}},"overall_assessment":{"final_score":70}}`)
+		assert.False(t, recomputeFinalScore(analysis, models.VacancyWeights{Soft: 50, Hard: 25, Case: 25}))
+	})
+}
+
+func TestRescoreResume(t *testing.T) {
+	resumes := &fakeRescoreResumes{analyses: map[string]map[string]interface{}{}}
+	republisher := &fakeRepublisher{}
+	svc := &rescoreService{resumes: resumes, resumeSvc: republisher}
+	weights := models.VacancyWeights{Soft: 20, Hard: 50, Case: 30}
+
+	tests := []struct {
+		name   string
+		resume *models.Resume
+		want   rescoreOutcome
+	}{
+		{"есть оценки компонентов", &models.Resume{ID: "a", Text: "text", ResumeAnalysisJSONB: datatypes.JSON(cvReview)}, rescoreRecomputed},
+		{"только итоговый балл", &models.Resume{ID: "b", Text: "text", ResumeAnalysisJSONB: datatypes.JSON(`{"overall_assessment":{"final_score":70}}`)}, rescoreRepublished},
+		{"еще не проанализировано", &models.Resume{ID: "c", Text: "text"}, rescoreRepublished},
+		{"еще не распознано", &models.Resume{ID: "d"}, rescoreSkipped},
+		{"ошибка обработки", &models.Resume{ID: "e", Text: "text", Status: models.ResumeStatusError}, rescoreSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.rescoreResume(context.Background(), tt.resume, weights)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Contains(t, resumes.analyses, "a")
+	assert.Equal(t, []string{"b", "c"}, republisher.republished)
+}
+
+func TestDiffRankings(t *testing.T) {
+	before := []models.RankingEntry{
+		{ResumeID: "a", Rank: 1, Total: 90},
+		{ResumeID: "b", Rank: 2, Total: 80},
+		{ResumeID: "c", Rank: 3, Total: 70},
+	}
+	after := []models.RankingEntry{
+		{ResumeID: "b", Rank: 1, Total: 88},
+		{ResumeID: "a", Rank: 2, Total: 85},
+		{ResumeID: "d", Rank: 3, Total: 60},
+	}
+
+	changes := diffRankings(before, after)
+	require.Len(t, changes, 4)
+
+	var order []string
+	for _, c := range changes {
+		order = append(order, c.ResumeID)
+	}
+	assert.Equal(t, []string{"b", "a", "d", "c"}, order, "выбывшие из рейтинга в конце")
+
+	assert.Equal(t, 1, changes[0].RankDelta)
+	assert.Equal(t, -1, changes[1].RankDelta)
+	assert.Nil(t, changes[2].OldRank)
+	assert.Nil(t, changes[3].NewRank)
+	assert.Equal(t, 70.0, *changes[3].OldTotal)
+}
+
+type fakeRescoreJobs struct {
+	repository.RescoreJobRepository
+	resets int
+}
+
+func (r *fakeRescoreJobs) ResetRunning(ctx context.Context) (int64, error) {
+	r.resets++
+	return 0, nil
+}
+
+func TestRescoreRun_ResetsJobsOnlyOnLeader(t *testing.T) {
+	for _, leader := range []bool{true, false} {
+		jobs := &fakeRescoreJobs{}
+		svc := NewRescoreService(jobs, &fakeRescoreResumes{}, &fakeRepublisher{}, nil, &fakeLeader{leader: leader})
+
+		// Первая проверка лидерства выполняется при запуске, до проверки ctx
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		svc.Run(ctx)
+
+		if leader {
+			assert.Equal(t, 1, jobs.resets, "лидер подхватывает прерванные задания")
+		} else {
+			assert.Zero(t, jobs.resets, "остальные реплики не трогают задания лидера")
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gorm.io/datatypes"
 	"interview/internal/broker"
@@ -12,8 +13,6 @@ import (
 	"interview/internal/storage"
 	"io"
 	"log"
-	"path/filepath"
-	"strings"
 	"time"
 )
 
@@ -24,50 +23,42 @@ type ResumeService interface {
 	GetResume(ctx context.Context, id string) (*models.Resume, error)
 	GetResumeWithFileURL(ctx context.Context, id string) (*models.Resume, error)
 	GetResumesByVacancy(ctx context.Context, vacancyID string) ([]*models.Resume, error)
+	// ListResumes возвращает страницу резюме вакансии с фильтрами. cursor - next_cursor предыдущей страницы.
+	ListResumes(ctx context.Context, vacancyID string, filter models.ResumeFilter, cursor string) (*ResumePage, error)
 	DeleteResume(ctx context.Context, id string) error
-	UpdateStatus(ctx context.Context, id, status string) error
-	UpdateStatusAndResult(ctx context.Context, id, status string, result map[string]interface{}) error
+	// ChangeStatus переводит резюме в новый статус, если переход разрешен, и записывает его в историю
+	ChangeStatus(ctx context.Context, id string, change StatusChange) error
+	GetStatusHistory(ctx context.Context, id string) ([]*models.ResumeStatusHistory, error)
 	UpdateResult(ctx context.Context, id string, result map[string]interface{}) error
+	// ApplyAnalysisResult сохраняет результат анализа от CV-review сервиса
+	ApplyAnalysisResult(ctx context.Context, result *broker.ResumeResultMessage) error
+	// ProcessResume извлекает текст и профиль и отправляет резюме на анализ. Вызывается воркером очереди.
+	ProcessResume(ctx context.Context, id string) error
+	// RepublishResume повторно отправляет уже обработанное резюме на анализ с текущими весами вакансии
+	RepublishResume(ctx context.Context, id string) error
+	GetDuplicates(ctx context.Context, id string) (*ResumeDuplicates, error)
+	GetDuplicatesByVacancy(ctx context.Context, vacancyID string) ([]ResumeDuplicates, error)
 }
 
 type resumeService struct {
 	repo        repository.ResumeRepository
 	storage     *storage.S3Storage
 	vacancyRepo repository.VacancyRepository
-	publisher   broker.Publisher // будет  отпправлять в брокер
-
+	parser      VacancyParserService
 }
 
 func NewResumeService(
 	repo repository.ResumeRepository,
 	storage *storage.S3Storage,
 	vacancyRepo repository.VacancyRepository,
-	publisher broker.Publisher,
+	parser VacancyParserService,
 ) ResumeService {
 	return &resumeService{
 		repo:        repo,
 		storage:     storage,
 		vacancyRepo: vacancyRepo,
-		publisher:   publisher,
-	}
-}
-
-func (s *resumeService) validateFileType(filename string) error {
-	if filename == "" {
-		return fmt.Errorf("filename cannot be empty")
-	}
-
-	ext := strings.ToLower(filepath.Ext(filename))
-	allowedExts := []string{".docx", ".pdf", ".txt"}
-
-	for _, allowedExt := range allowedExts {
-		if ext == allowedExt {
-			return nil
-		}
+		parser:      parser,
 	}
-
-	return fmt.Errorf("unsupported file type: %s. Allowed types: %s",
-		ext, strings.Join(allowedExts, ", "))
 }
 
 func (s *resumeService) CreateResume(ctx context.Context, resume *models.Resume, file io.Reader, filename string) error {
@@ -75,13 +66,11 @@ func (s *resumeService) CreateResume(ctx context.Context, resume *models.Resume,
 		return fmt.Errorf("file is required to create a resume")
 	}
 
-	if err := s.validateFileType(filename); err != nil {
+	if err := validateFileType(filename); err != nil {
 		return err
 	}
 
-	//_ это vacancy
-	vacancy, err := s.vacancyRepo.GetByID(ctx, resume.VacancyID)
-	if err != nil {
+	if _, err := s.vacancyRepo.GetByID(ctx, resume.VacancyID); err != nil {
 		return fmt.Errorf("vacancy not found: %w", err)
 	}
 
@@ -98,11 +87,32 @@ func (s *resumeService) CreateResume(ctx context.Context, resume *models.Resume,
 
 	fileType := detectFileType(fileData)
 	if fileType == FileTypeUnknown {
-		return fmt.Errorf("unsupported file format. Please upload DOCX, PDF or TXT file")
+		return fmt.Errorf("unsupported file format. Please upload %s file", supportedFormatNames())
 	}
 
 	log.Printf("📄 Processing %s file: %s (%d bytes)", getFileTypeName(fileType), filename, len(fileData))
 
+	// Проверяем, не загружался ли этот файл раньше
+	resume.ContentHash = contentHash(fileData)
+	sameFile, err := s.repo.FindByFingerprints(ctx, "", resume.ContentHash, "", "")
+	if err != nil {
+		log.Printf("⚠️ Failed to check resume duplicates: %v", err)
+	}
+	for _, existing := range sameFile {
+		if existing.VacancyID == resume.VacancyID {
+			*resume = *existing
+			return fmt.Errorf("%w: %s", ErrDuplicateResume, existing.ID)
+		}
+	}
+	if len(sameFile) > 0 {
+		original := sameFile[0].ID
+		if sameFile[0].DuplicateOf != nil {
+			original = *sameFile[0].DuplicateOf
+		}
+		resume.DuplicateOf = &original
+		log.Printf("👥 Same file was already uploaded as resume %s", original)
+	}
+
 	// Загружаем файл в S3
 	storageKey, err := s.storage.UploadResume(ctx, bytes.NewReader(fileData), filename)
 	if err != nil {
@@ -110,53 +120,169 @@ func (s *resumeService) CreateResume(ctx context.Context, resume *models.Resume,
 	}
 
 	resume.StorageKey = storageKey
-	resume.Status = "Прошел парсер"
+	resume.Status = models.ResumeStatusParsed
 	resume.CreatedAt = time.Now()
 
-	if err := s.repo.Create(ctx, resume); err != nil {
+	history := &models.ResumeStatusHistory{
+		ToStatus: resume.Status,
+		Actor:    models.ResumeActorSystem,
+		Reason:   "resume uploaded",
+	}
+	if err := s.repo.Create(ctx, resume, history); err != nil {
 		// Если создание записи в БД не удалось, удаляем файл из S3
 		if deleteErr := s.storage.DeleteFile(ctx, storageKey); deleteErr != nil {
 			log.Printf("❌ Failed to cleanup uploaded file after DB error: %v", deleteErr)
 		}
+		if errors.Is(err, repository.ErrDuplicateContent) {
+			// Тот же файл параллельно загрузили на эту вакансию - отдаем уже созданное резюме
+			return s.duplicateOf(ctx, resume)
+		}
 		return fmt.Errorf("failed to create resume: %w", err)
 	}
 
+	// Резюме создано вместе с заданием обработки: воркер извлечет текст и отправит резюме в брокер
 	log.Printf("✅ Resume created successfully: %s", resume.ID)
-
-	// Асинхронно обрабатываем и отправляем в брокер
-	go s.processResumeAsync(resume, fileData, vacancy)
-
 	return nil
 }
 
-// ← Новый метод для асинхронной обработки
-func (s *resumeService) processResumeAsync(resume *models.Resume, fileData []byte, vacancy *models.Vacancy) {
-	ctx := context.Background()
+// duplicateOf подставляет в resume уже загруженное на вакансию резюме с тем же файлом
+func (s *resumeService) duplicateOf(ctx context.Context, resume *models.Resume) error {
+	sameFile, err := s.repo.FindByFingerprints(ctx, "", resume.ContentHash, "", "")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDuplicateResume, err)
+	}
+	for _, existing := range sameFile {
+		if existing.VacancyID == resume.VacancyID {
+			*resume = *existing
+			return fmt.Errorf("%w: %s", ErrDuplicateResume, existing.ID)
+		}
+	}
+	return ErrDuplicateResume
+}
+
+func (s *resumeService) ProcessResume(ctx context.Context, id string) error {
+	resume, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("resume not found: %w", err)
+	}
+
+	vacancy, err := s.vacancyRepo.GetByID(ctx, resume.VacancyID)
+	if err != nil {
+		return fmt.Errorf("vacancy not found: %w", err)
+	}
+
+	file, err := s.storage.DownloadFile(ctx, resume.StorageKey)
+	if err != nil {
+		return err
+	}
+	fileData, err := io.ReadAll(io.LimitReader(file, MaxResumeFileSize))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read resume file: %w", err)
+	}
 
 	// Извлекаем текст из резюме с использованием универсальной функции
 	resumeText, err := ExtractTextFromFile(fileData, resume.StorageKey)
 	if err != nil {
 		log.Printf("❌ Не удалось извлечь текст резюме для %s: %v", resume.ID, err)
+		// Файл не изменится, повторять бессмысленно
+		return permanentError(err)
+	}
 
-		// Обновляем статус резюме на error
-		if updateErr := s.repo.UpdateStatus(ctx, resume.ID, "error"); updateErr != nil {
-			log.Printf("Failed to update resume status to error: %v", updateErr)
+	log.Printf("✅ Успешно извлечен текст резюме %s: %d символов", resume.ID, len(resumeText))
+
+	// Разбираем текст в структурированный профиль кандидата
+	profile := ParseCandidateProfile(resumeText)
+
+	vacancyTextJSON, vacancyData := s.vacancyMessageText(ctx, vacancy)
+
+	// Подготавливаем текст резюме в JSON формате
+	resumeTextJSON := datatypes.JSON("{}")
+	if resumeText != "" {
+		resumeDataMap := map[string]interface{}{
+			"text":         resumeText,
+			"extracted_at": time.Now(),
+			"file_name":    resume.StorageKey,
+			"file_type":    string(getFileTypeName(detectFileType(fileData))),
+			"size_bytes":   len(fileData),
+			"profile":      profile,
+		}
+		if jsonData, err := json.Marshal(resumeDataMap); err == nil {
+			resumeTextJSON = datatypes.JSON(jsonData)
 		}
-		return
 	}
 
-	log.Printf("✅ Успешно извлечен текст резюме %s: %d символов", resume.ID, len(resumeText))
+	outbox, err := newResumeOutboxMessage(resume, vacancy, resumeTextJSON, vacancyTextJSON)
+	if err != nil {
+		return permanentError(err)
+	}
+
+	// Текст, профиль и сообщение в outbox сохраняются одной транзакцией,
+	// в брокер сообщение отправит OutboxRelay
+	if err := s.repo.SaveProcessed(ctx, resume.ID, resumeText, profile, outbox); err != nil {
+		return fmt.Errorf("failed to save processed resume: %w", err)
+	}
+	log.Printf("💾 Резюме %s обработано: %d мест работы, %d навыков, сообщение %s в outbox",
+		resume.ID, len(profile.Experience), len(profile.Skills), outbox.ID)
+
+	s.detectIdentityDuplicates(ctx, resume, profile)
+
+	// Логируем для отладки
+	if resumeText != "" {
+		log.Printf("📄 Resume text extracted: %d characters", len(resumeText))
+	}
+	if vacancyData != nil {
+		log.Printf("📋 Vacancy data: %s", vacancyData.Название)
+	}
+
+	return nil
+}
 
-	// --- НОВОЕ: сохраняем ПЛЕЙН-ТЕКСТ в БД (колонка text)
-	if err := s.repo.UpdateText(ctx, resume.ID, resumeText); err != nil {
-		log.Printf("❌ Не удалось сохранить текст резюме в БД для %s: %v", resume.ID, err)
-		// продолжаем процесс, чтобы не блокировать анализ
-	} else {
-		log.Printf("💾 Текст резюме %s сохранен в БД (колонка text)", resume.ID)
+func (s *resumeService) RepublishResume(ctx context.Context, id string) error {
+	resume, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("resume not found: %w", err)
+	}
+	if resume.Text == "" {
+		return fmt.Errorf("resume %s has not been processed yet", id)
+	}
+
+	vacancy, err := s.vacancyRepo.GetByID(ctx, resume.VacancyID)
+	if err != nil {
+		return fmt.Errorf("vacancy not found: %w", err)
+	}
+
+	profile := &models.CandidateProfile{}
+	if len(resume.ProfileJSONB) == 0 || json.Unmarshal(resume.ProfileJSONB, profile) != nil {
+		profile = ParseCandidateProfile(resume.Text)
+	}
+
+	resumeTextJSON, err := json.Marshal(map[string]interface{}{
+		"text":         resume.Text,
+		"extracted_at": time.Now(),
+		"file_name":    resume.StorageKey,
+		"profile":      profile,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal resume text: %w", err)
 	}
-	// --- КОНЕЦ НОВОГО БЛОКА ---
 
-	// Подготавливаем текст вакансии
+	vacancyTextJSON, _ := s.vacancyMessageText(ctx, vacancy)
+	outbox, err := newResumeOutboxMessage(resume, vacancy, datatypes.JSON(resumeTextJSON), vacancyTextJSON)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SaveProcessed(ctx, resume.ID, resume.Text, profile, outbox); err != nil {
+		return fmt.Errorf("failed to republish resume: %w", err)
+	}
+	log.Printf("🔁 Resume %s republished for analysis, message %s in outbox", resume.ID, outbox.ID)
+	return nil
+}
+
+// vacancyMessageText готовит JSON вакансии для сообщения в CV-review сервис.
+// Если вакансия еще не разобрана, разбирает ее файл и сохраняет результат.
+func (s *resumeService) vacancyMessageText(ctx context.Context, vacancy *models.Vacancy) (datatypes.JSON, *Job) {
 	var vacancyTextJSON datatypes.JSON
 	var vacancyData *Job
 
@@ -178,7 +304,8 @@ func (s *resumeService) processResumeAsync(resume *models.Resume, fileData []byt
 		// Извлекаем данные из файла вакансии
 		log.Printf("Extracting vacancy data from file for vacancy %s", vacancy.ID)
 
-		vacancyData, err = ExtractVacancyFromS3Key(ctx, s.storage, vacancy.StorageKey)
+		var err error
+		vacancyData, err = s.parser.ParseStoredFile(ctx, vacancy.StorageKey)
 		if err != nil {
 			log.Printf("Failed to extract vacancy data for vacancy %s: %v", vacancy.ID, err)
 		} else {
@@ -213,56 +340,29 @@ func (s *resumeService) processResumeAsync(resume *models.Resume, fileData []byt
 		}
 	}
 
-	// Подготавливаем текст резюме в JSON формате
-	resumeTextJSON := datatypes.JSON("{}")
-	if resumeText != "" {
-		resumeDataMap := map[string]interface{}{
-			"text":         resumeText,
-			"extracted_at": time.Now(),
-			"file_name":    resume.StorageKey,
-			"file_type":    string(getFileTypeName(detectFileType(fileData))),
-			"size_bytes":   len(fileData),
-		}
-		if jsonData, err := json.Marshal(resumeDataMap); err == nil {
-			resumeTextJSON = datatypes.JSON(jsonData)
-		}
-	}
+	return vacancyTextJSON, vacancyData
+}
 
-	// 🚀 Создаем и отправляем сообщение в брокер
+// newResumeOutboxMessage собирает ResumeMessage для отправки на анализ через outbox
+func newResumeOutboxMessage(resume *models.Resume, vacancy *models.Vacancy, resumeText, vacancyText datatypes.JSON) (*models.OutboxMessage, error) {
 	message := broker.ResumeMessage{
 		ID:          resume.ID,
 		VacancyID:   resume.VacancyID,
-		TextResume:  resumeTextJSON,     // JSON с текстом резюме
-		TextVacancy: vacancyTextJSON,    // JSON с текстом вакансии
+		TextResume:  resumeText,         // JSON с текстом резюме
+		TextVacancy: vacancyText,        // JSON с текстом вакансии
 		WeightSoft:  vacancy.WeightSoft, // Вес soft skills
 		WeightHard:  vacancy.WeightHard, // Вес hard skills
 		WeightCase:  vacancy.WeightCase, // Вес кейсов/опыта
 	}
 
-	// Отправляем сообщение
-	if err := s.publisher.PublishResumeMessage(ctx, message); err != nil {
-		log.Printf("Failed to publish resume message for %s: %v", resume.ID, err)
-
-		// Обновляем статус резюме на error
-		if updateErr := s.repo.UpdateStatus(ctx, resume.ID, "error"); updateErr != nil {
-			log.Printf("Failed to update resume status to error: %v", updateErr)
-		}
-	} else {
-		log.Printf("✅ Successfully published resume message: resume %s for vacancy %s", resume.ID, resume.VacancyID)
-
-		//// Обновляем статус резюме на processing
-		//if updateErr := s.repo.UpdateStatus(ctx, resume.ID, ResumeStatusProcessing); updateErr != nil {
-		//	log.Printf("Failed to update resume status to processing: %v", updateErr)
-		//}
-	}
-
-	// Логируем для отладки
-	if resumeText != "" {
-		log.Printf("📄 Resume text extracted: %d characters", len(resumeText))
-	}
-	if vacancyData != nil {
-		log.Printf("📋 Vacancy data: %s", vacancyData.Название)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resume message: %w", err)
 	}
+	return &models.OutboxMessage{
+		AggregateID: resume.ID,
+		Payload:     datatypes.JSON(payload),
+	}, nil
 }
 
 func (s *resumeService) GetResume(ctx context.Context, id string) (*models.Resume, error) {
@@ -316,15 +416,49 @@ func (s *resumeService) DeleteResume(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *resumeService) UpdateStatus(ctx context.Context, id, status string) error {
-	return s.repo.UpdateStatus(ctx, id, status)
-}
+func (s *resumeService) UpdateResult(ctx context.Context, id string, result map[string]interface{}) error {
 
-func (s *resumeService) UpdateStatusAndResult(ctx context.Context, id, status string, result map[string]interface{}) error {
-	return s.repo.UpdateStatusAndResult(ctx, id, status, result)
+	return s.repo.UpdateResult(ctx, id, result)
 }
 
-func (s *resumeService) UpdateResult(ctx context.Context, id string, result map[string]interface{}) error {
+func (s *resumeService) ApplyAnalysisResult(ctx context.Context, result *broker.ResumeResultMessage) error {
+	if _, err := s.repo.GetByID(ctx, result.ResumeID); err != nil {
+		return fmt.Errorf("resume not found: %w", err)
+	}
 
-	return s.repo.UpdateResult(ctx, id, result)
+	change := StatusChange{
+		To:     models.ResumeStatusAnalyzed,
+		Actor:  models.ResumeActorCVReview,
+		Reason: "analysis completed",
+		Result: result.Analysis,
+	}
+	if result.Status == broker.ResumeResultFailed {
+		change.To = models.ResumeStatusError
+		change.Reason = result.Error
+		change.Result = map[string]interface{}{"error": result.Error}
+	}
+
+	err := s.ChangeStatus(ctx, result.ResumeID, change)
+	if errors.Is(err, ErrInvalidStatusTransition) {
+		if result.Status == broker.ResumeResultFailed {
+			// Ошибка повторного анализа не должна стирать прежний анализ и итоговый балл
+			log.Printf("⚠️ Resume %s: %v, failed analysis ignored: %s", result.ResumeID, err, result.Error)
+			return nil
+		}
+		// Резюме уже ушло дальше (например, на собеседование) - сохраняем только анализ
+		log.Printf("⚠️ Resume %s: %v, saving analysis without status change", result.ResumeID, err)
+		err = s.repo.UpdateAnalysis(ctx, result.ResumeID, change.Result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save analysis result: %w", err)
+	}
+
+	if result.Email != "" {
+		if err := s.repo.SetMailIfEmpty(ctx, result.ResumeID, result.Email); err != nil {
+			return fmt.Errorf("failed to save email: %w", err)
+		}
+	}
+
+	log.Printf("🧠 Analysis result for resume %s saved: %s", result.ResumeID, result.Status)
+	return nil
 }
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"interview/internal/models"
+)
+
+// ErrDuplicateResume - тот же файл уже загружен на эту вакансию
+var ErrDuplicateResume = errors.New("resume already uploaded for this vacancy")
+
+// ResumeDuplicates - резюме и другие отклики того же кандидата
+type ResumeDuplicates struct {
+	ResumeID      string                        `json:"resume_id"`
+	Matches       []models.ResumeDuplicateMatch `json:"matches"`
+	AlsoAppliedTo []VacancyRef                  `json:"also_applied_to"`
+}
+
+// VacancyRef - краткая ссылка на вакансию
+type VacancyRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// contentHash возвращает SHA-256 содержимого файла
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// emailFingerprint нормализует email (регистр, пробелы, +метка, точки в Gmail) и хэширует его
+func emailFingerprint(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || domain == "" {
+		return ""
+	}
+
+	if i := strings.IndexByte(local, '+'); i > 0 {
+		local = local[:i]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+
+	return fingerprint(local + "@" + domain)
+}
+
+// phoneFingerprint приводит номер к виду 7XXXXXXXXXX (8 и +7 считаются одинаковыми) и хэширует его
+func phoneFingerprint(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+
+	switch {
+	case len(digits) == 11 && digits[0] == '8':
+		digits = "7" + digits[1:]
+	case len(digits) == 10 && digits[0] == '9':
+		digits = "7" + digits
+	}
+	if len(digits) < 10 {
+		return ""
+	}
+
+	return fingerprint(digits)
+}
+
+func fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// groupDuplicates собирает совпадения по резюме. ownVacancyID - вакансия самих резюме,
+// она не попадает в список "также откликался".
+func groupDuplicates(matches []models.ResumeDuplicateMatch, ownVacancyID string) []ResumeDuplicates {
+	var groups []ResumeDuplicates
+	index := make(map[string]int)
+
+	for _, match := range matches {
+		i, ok := index[match.ResumeID]
+		if !ok {
+			i = len(groups)
+			index[match.ResumeID] = i
+			groups = append(groups, ResumeDuplicates{ResumeID: match.ResumeID})
+		}
+		group := &groups[i]
+		group.Matches = append(group.Matches, match)
+
+		if match.VacancyID == ownVacancyID {
+			continue
+		}
+		seen := false
+		for _, ref := range group.AlsoAppliedTo {
+			if ref.ID == match.VacancyID {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			group.AlsoAppliedTo = append(group.AlsoAppliedTo, VacancyRef{ID: match.VacancyID, Title: match.VacancyTitle})
+		}
+	}
+
+	return groups
+}
+
+func (s *resumeService) GetDuplicates(ctx context.Context, id string) (*ResumeDuplicates, error) {
+	resume, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.repo.GetDuplicatesByResume(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := groupDuplicates(matches, resume.VacancyID)
+	if len(groups) == 0 {
+		return &ResumeDuplicates{ResumeID: id, Matches: []models.ResumeDuplicateMatch{}, AlsoAppliedTo: []VacancyRef{}}, nil
+	}
+	if groups[0].AlsoAppliedTo == nil {
+		groups[0].AlsoAppliedTo = []VacancyRef{}
+	}
+	return &groups[0], nil
+}
+
+func (s *resumeService) GetDuplicatesByVacancy(ctx context.Context, vacancyID string) ([]ResumeDuplicates, error) {
+	matches, err := s.repo.GetDuplicatesByVacancy(ctx, vacancyID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := groupDuplicates(matches, vacancyID)
+	for i := range groups {
+		if groups[i].AlsoAppliedTo == nil {
+			groups[i].AlsoAppliedTo = []VacancyRef{}
+		}
+	}
+	return groups, nil
+}
+
+// detectIdentityDuplicates сохраняет отпечатки email/телефона из профиля и помечает резюме
+// как дубликат самого раннего резюме того же кандидата
+func (s *resumeService) detectIdentityDuplicates(ctx context.Context, resume *models.Resume, profile *models.CandidateProfile) {
+	emailFP := emailFingerprint(profile.Email)
+	phoneFP := phoneFingerprint(profile.Phone)
+	if emailFP == "" && phoneFP == "" {
+		return
+	}
+
+	if err := s.repo.UpdateFingerprints(ctx, resume.ID, emailFP, phoneFP); err != nil {
+		log.Printf("❌ Failed to save fingerprints for resume %s: %v", resume.ID, err)
+		return
+	}
+
+	if resume.DuplicateOf != nil {
+		return
+	}
+
+	matches, err := s.repo.FindByFingerprints(ctx, resume.ID, "", emailFP, phoneFP)
+	if err != nil {
+		log.Printf("❌ Failed to search duplicates for resume %s: %v", resume.ID, err)
+		return
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	original := matches[0].ID
+	if matches[0].DuplicateOf != nil {
+		original = *matches[0].DuplicateOf
+	}
+	if err := s.repo.MarkDuplicate(ctx, resume.ID, original); err != nil {
+		log.Printf("❌ Failed to mark resume %s as duplicate: %v", resume.ID, err)
+		return
+	}
+	resume.DuplicateOf = &original
+
+	log.Printf("👥 Resume %s belongs to the same candidate as %s", resume.ID, original)
+}
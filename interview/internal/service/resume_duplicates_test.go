@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// fakeFingerprintRepository находит резюме по хэшу файла
+type fakeFingerprintRepository struct {
+	repository.ResumeRepository
+	resumes []*models.Resume
+}
+
+func (r *fakeFingerprintRepository) FindByFingerprints(ctx context.Context, excludeID, contentHash, emailFingerprint, phoneFingerprint string) ([]*models.Resume, error) {
+	var found []*models.Resume
+	for _, resume := range r.resumes {
+		if resume.ContentHash == contentHash {
+			found = append(found, resume)
+		}
+	}
+	return found, nil
+}
+
+func TestDuplicateOf(t *testing.T) {
+	repo := &fakeFingerprintRepository{resumes: []*models.Resume{
+		{ID: "other-vacancy", VacancyID: "vacancy-2", ContentHash: "hash"},
+		{ID: "same-vacancy", VacancyID: "vacancy-1", ContentHash: "hash"},
+	}}
+	svc := &resumeService{repo: repo}
+
+	t.Run("отдает резюме той же вакансии", func(t *testing.T) {
+		resume := &models.Resume{VacancyID: "vacancy-1", ContentHash: "hash"}
+		err := svc.duplicateOf(context.Background(), resume)
+		assert.ErrorIs(t, err, ErrDuplicateResume)
+		assert.Equal(t, "same-vacancy", resume.ID)
+	})
+
+	t.Run("резюме уже удалено", func(t *testing.T) {
+		resume := &models.Resume{VacancyID: "vacancy-3", ContentHash: "hash"}
+		err := svc.duplicateOf(context.Background(), resume)
+		assert.ErrorIs(t, err, ErrDuplicateResume)
+		assert.Empty(t, resume.ID)
+	})
+}
+
+func TestEmailFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"регистр и пробелы", " Ivan.Petrov@Mail.ru ", "ivan.petrov@mail.ru", true},
+		{"метка после плюса", "ivan+hh@yandex.ru", "ivan@yandex.ru", true},
+		{"точки в gmail", "ivan.petrov@googlemail.com", "ivanpetrov@gmail.com", true},
+		{"точки важны вне gmail", "ivan.petrov@mail.ru", "ivanpetrov@mail.ru", false},
+		{"разные адреса", "ivan@mail.ru", "petr@mail.ru", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := emailFingerprint(tt.a), emailFingerprint(tt.b)
+			assert.NotEmpty(t, a)
+			assert.Equal(t, tt.same, a == b)
+		})
+	}
+
+	assert.Empty(t, emailFingerprint(""))
+	assert.Empty(t, emailFingerprint("не email"))
+}
+
+func TestPhoneFingerprint(t *testing.T) {
+	want := phoneFingerprint("+7 (916) 123-45-67")
+	assert.NotEmpty(t, want)
+
+	for _, phone := range []string{"89161234567", "+7-916-123-45-67", "916 123 45 67"} {
+		assert.Equal(t, want, phoneFingerprint(phone), phone)
+	}
+
+	assert.NotEqual(t, want, phoneFingerprint("+7 916 123-45-68"))
+	assert.Empty(t, phoneFingerprint("123-45"))
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	matches := []models.ResumeDuplicateMatch{
+		{ResumeID: "r1", DuplicateID: "r2", VacancyID: "v1", VacancyTitle: "Go разработчик", SameEmail: true},
+		{ResumeID: "r1", DuplicateID: "r3", VacancyID: "v2", VacancyTitle: "Аналитик", SameContent: true},
+		{ResumeID: "r1", DuplicateID: "r4", VacancyID: "v2", VacancyTitle: "Аналитик", SamePhone: true},
+		{ResumeID: "r5", DuplicateID: "r6", VacancyID: "v3", VacancyTitle: "Тестировщик", SameEmail: true},
+	}
+
+	groups := groupDuplicates(matches, "v1")
+
+	if assert.Len(t, groups, 2) {
+		assert.Equal(t, "r1", groups[0].ResumeID)
+		assert.Len(t, groups[0].Matches, 3)
+		assert.Equal(t, []VacancyRef{{ID: "v2", Title: "Аналитик"}}, groups[0].AlsoAppliedTo)
+
+		assert.Equal(t, "r5", groups[1].ResumeID)
+		assert.Equal(t, []VacancyRef{{ID: "v3", Title: "Тестировщик"}}, groups[1].AlsoAppliedTo)
+	}
+}
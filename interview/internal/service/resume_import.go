@@ -0,0 +1,437 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+const (
+	MaxImportUploadSize       = 200 * 1024 * 1024 // размер загружаемого архива
+	MaxImportFiles            = 500
+	MaxImportUnpackedSize     = 500 * 1024 * 1024 // суммарный размер распакованных файлов
+	MaxImportCompressionRatio = 100               // больше - похоже на zip-бомбу
+
+	// importStaleAfter - задание без heartbeat дольше брошено упавшей репликой
+	importStaleAfter = 10 * time.Minute
+)
+
+var ErrImportNotRunning = errors.New("resume import is not running")
+
+// ImportUpload - загруженный файл: отдельное резюме или ZIP архив с резюме
+type ImportUpload struct {
+	Filename string
+	Reader   io.ReaderAt
+	Size     int64
+}
+
+type ResumeImportService interface {
+	// StartImport сохраняет загрузки во временные файлы, создает задание и обрабатывает его в фоне.
+	// Файлы читаются по одному при обработке.
+	StartImport(ctx context.Context, vacancyID string, uploads []ImportUpload) (*models.ResumeImportJob, error)
+	GetImport(ctx context.Context, id string) (*models.ResumeImportJob, error)
+	// Run принимает импорты до отмены ctx и ждет завершения начатых. Импорты, брошенные
+	// упавшей репликой, помечаются failed: их временные файлы остались на другом поде.
+	Run(ctx context.Context)
+}
+
+type resumeImportService struct {
+	repo        repository.ResumeImportRepository
+	vacancyRepo repository.VacancyRepository
+	resumes     ResumeService
+	limits      importLimits
+
+	mu      sync.Mutex
+	ctx     context.Context // контекст Run; nil, пока сервис не запущен
+	running sync.WaitGroup
+}
+
+func NewResumeImportService(
+	repo repository.ResumeImportRepository,
+	vacancyRepo repository.VacancyRepository,
+	resumes ResumeService,
+) ResumeImportService {
+	return &resumeImportService{
+		repo:        repo,
+		vacancyRepo: vacancyRepo,
+		resumes:     resumes,
+		limits:      defaultImportLimits,
+	}
+}
+
+// importLimits - ограничения распаковки
+type importLimits struct {
+	maxUploadSize    int64
+	maxFiles         int
+	maxFileSize      int64
+	maxUnpackedSize  int64
+	maxCompressRatio uint64
+}
+
+var defaultImportLimits = importLimits{
+	maxUploadSize:    MaxImportUploadSize,
+	maxFiles:         MaxImportFiles,
+	maxFileSize:      MaxResumeFileSize,
+	maxUnpackedSize:  MaxImportUnpackedSize,
+	maxCompressRatio: MaxImportCompressionRatio,
+}
+
+// importFile - файл, извлеченный из загрузки. Если rejectReason не пуст, файл не импортируется.
+// Содержимое читается через read только при обработке, чтобы не держать архив в памяти.
+type importFile struct {
+	name         string
+	size         int64
+	rejectReason string
+	read         func() ([]byte, string) // содержимое или причина отказа
+}
+
+func (s *resumeImportService) StartImport(ctx context.Context, vacancyID string, uploads []ImportUpload) (*models.ResumeImportJob, error) {
+	if len(uploads) == 0 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+	if _, err := s.vacancyRepo.GetByID(ctx, vacancyID); err != nil {
+		return nil, fmt.Errorf("vacancy not found: %w", err)
+	}
+
+	// Загрузка живет только до конца запроса, поэтому копируется на диск
+	spooled, cleanup, err := spoolImportUploads(uploads, s.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := collectImportFiles(spooled, s.limits)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	if len(files) == 0 {
+		cleanup()
+		return nil, fmt.Errorf("no files to import")
+	}
+
+	now := time.Now()
+	job := &models.ResumeImportJob{
+		VacancyID:   vacancyID,
+		Status:      models.ImportStatusPending,
+		TotalFiles:  len(files),
+		CreatedAt:   now,
+		HeartbeatAt: &now,
+	}
+	for i, file := range files {
+		item := models.ResumeImportItem{
+			Position:  i + 1,
+			Filename:  file.name,
+			SizeBytes: file.size,
+			Status:    models.ImportItemPending,
+			UpdatedAt: job.CreatedAt,
+		}
+		if file.rejectReason != "" {
+			item.Status = models.ImportItemFailed
+			item.Error = file.rejectReason
+			job.Failed++
+		}
+		job.Items = append(job.Items, item)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx == nil || s.ctx.Err() != nil {
+		cleanup()
+		return nil, ErrImportNotRunning
+	}
+
+	if err := s.repo.Create(ctx, job); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	log.Printf("📦 Resume import %s created: %d files for vacancy %s", job.ID, len(files), vacancyID)
+
+	s.running.Add(1)
+	go func(runCtx context.Context) {
+		defer s.running.Done()
+		defer cleanup()
+		s.processImport(runCtx, job, files)
+	}(s.ctx)
+
+	return job, nil
+}
+
+func (s *resumeImportService) GetImport(ctx context.Context, id string) (*models.ResumeImportJob, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *resumeImportService) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+	log.Println("📦 Resume import started")
+
+	s.failStale(ctx)
+
+	ticker := time.NewTicker(importStaleAfter / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Под блокировкой: после отмены ctx StartImport не запускает новых импортов
+			s.mu.Lock()
+			s.mu.Unlock()
+			s.running.Wait()
+			log.Println("✅ Resume import stopped")
+			return
+		case <-ticker.C:
+			s.failStale(ctx)
+		}
+	}
+}
+
+func (s *resumeImportService) failStale(ctx context.Context) {
+	failed, err := s.repo.FailStale(ctx, time.Now().Add(-importStaleAfter), "import interrupted: service restarted")
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("❌ Failed to fail abandoned resume imports: %v", err)
+		}
+		return
+	}
+	if failed > 0 {
+		log.Printf("⚠️ Marked %d abandoned resume imports as failed", failed)
+	}
+}
+
+// processImport создает резюме по одному через CreateResume и сохраняет результат каждого файла.
+// При отмене ctx необработанные файлы помечаются failed, начатый файл дообрабатывается.
+func (s *resumeImportService) processImport(ctx context.Context, job *models.ResumeImportJob, files []importFile) {
+	job.Status = models.ImportStatusProcessing
+	s.heartbeat(job)
+
+	for i := range job.Items {
+		item := &job.Items[i]
+		if item.Status != models.ImportItemPending {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			item.Status = models.ImportItemFailed
+			item.Error = "import interrupted: service is shutting down"
+			job.Failed++
+		} else if data, reason := files[i].read(); reason != "" {
+			item.Status = models.ImportItemFailed
+			item.Error = reason
+			job.Failed++
+		} else {
+			resume := &models.Resume{VacancyID: job.VacancyID}
+			err := s.resumes.CreateResume(context.Background(), resume, bytes.NewReader(data), files[i].name)
+			if err != nil {
+				item.Status = models.ImportItemFailed
+				item.Error = err.Error()
+				job.Failed++
+				log.Printf("⚠️ Import %s: %s failed: %v", job.ID, item.Filename, err)
+			} else {
+				item.Status = models.ImportItemCreated
+				item.ResumeID = &resume.ID
+				job.Succeeded++
+			}
+		}
+		item.UpdatedAt = time.Now()
+
+		if err := s.repo.UpdateItem(context.Background(), item); err != nil {
+			log.Printf("❌ Failed to update import item %s: %v", item.ID, err)
+		}
+		s.heartbeat(job)
+	}
+
+	finishedAt := time.Now()
+	job.Status = models.ImportStatusCompleted
+	if ctx.Err() != nil {
+		job.Status = models.ImportStatusFailed
+	}
+	job.FinishedAt = &finishedAt
+	if err := s.repo.UpdateJob(context.Background(), job); err != nil {
+		log.Printf("❌ Failed to finish import job %s: %v", job.ID, err)
+	}
+
+	log.Printf("✅ Resume import %s finished: %d created, %d failed", job.ID, job.Succeeded, job.Failed)
+}
+
+// heartbeat сохраняет прогресс задания и отмечает, что оно еще выполняется
+func (s *resumeImportService) heartbeat(job *models.ResumeImportJob) {
+	now := time.Now()
+	job.HeartbeatAt = &now
+	if err := s.repo.UpdateJob(context.Background(), job); err != nil {
+		log.Printf("❌ Failed to update import job %s: %v", job.ID, err)
+	}
+}
+
+// spoolImportUploads копирует загрузки во временные файлы. cleanup закрывает и удаляет их.
+func spoolImportUploads(uploads []ImportUpload, limits importLimits) ([]ImportUpload, func(), error) {
+	var temps []*os.File
+	cleanup := func() {
+		for _, f := range temps {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}
+
+	spooled := make([]ImportUpload, 0, len(uploads))
+	for _, upload := range uploads {
+		if upload.Size > limits.maxUploadSize {
+			cleanup()
+			return nil, nil, fmt.Errorf("file %s is too large: maximum is %d bytes", upload.Filename, limits.maxUploadSize)
+		}
+
+		temp, err := os.CreateTemp("", "resume-import-*")
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to store upload: %w", err)
+		}
+		temps = append(temps, temp)
+
+		if _, err := io.Copy(temp, io.NewSectionReader(upload.Reader, 0, upload.Size)); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to store upload %s: %w", upload.Filename, err)
+		}
+		spooled = append(spooled, ImportUpload{Filename: upload.Filename, Reader: temp, Size: upload.Size})
+	}
+	return spooled, cleanup, nil
+}
+
+// collectImportFiles раскрывает ZIP архивы и проверяет ограничения.
+// Ошибка возвращается, только если нарушено ограничение на всю загрузку целиком.
+func collectImportFiles(uploads []ImportUpload, limits importLimits) ([]importFile, error) {
+	var files []importFile
+	var unpacked int64
+
+	add := func(file importFile) error {
+		if len(files) >= limits.maxFiles {
+			return fmt.Errorf("too many files: maximum is %d", limits.maxFiles)
+		}
+		if file.rejectReason == "" {
+			unpacked += file.size
+		}
+		if unpacked > limits.maxUnpackedSize {
+			return fmt.Errorf("unpacked files exceed %d bytes", limits.maxUnpackedSize)
+		}
+		files = append(files, file)
+		return nil
+	}
+
+	for _, upload := range uploads {
+		if upload.Size > limits.maxUploadSize {
+			return nil, fmt.Errorf("file %s is too large: maximum is %d bytes", upload.Filename, limits.maxUploadSize)
+		}
+
+		if strings.EqualFold(path.Ext(upload.Filename), ".zip") {
+			if err := unpackImportArchive(upload, limits, add); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		file := importFile{name: path.Base(upload.Filename), size: upload.Size}
+		if err := validateFileType(file.name); err != nil {
+			file.rejectReason = err.Error()
+		} else if upload.Size > limits.maxFileSize {
+			file.rejectReason = fmt.Sprintf("file is too large: maximum is %d bytes", limits.maxFileSize)
+		} else {
+			upload := upload
+			file.read = func() ([]byte, string) {
+				data, err := io.ReadAll(io.NewSectionReader(upload.Reader, 0, upload.Size))
+				if err != nil {
+					return nil, fmt.Sprintf("failed to read file: %v", err)
+				}
+				return data, ""
+			}
+		}
+		if err := add(file); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// unpackImportArchive перечисляет резюме ZIP архива. Размер каждого файла проверяется
+// и по заголовку, и при фактическом чтении, так как заголовку zip-бомбы доверять нельзя.
+func unpackImportArchive(upload ImportUpload, limits importLimits, add func(importFile) error) error {
+	zipReader, err := zip.NewReader(upload.Reader, upload.Size)
+	if err != nil {
+		return fmt.Errorf("file %s is not a valid ZIP archive: %w", upload.Filename, err)
+	}
+
+	for _, entry := range zipReader.File {
+		name := strings.ReplaceAll(decodeZipName(entry), "\\", "/")
+		base := path.Base(name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		file := importFile{name: base, size: int64(entry.UncompressedSize64)}
+		switch {
+		case strings.EqualFold(path.Ext(base), ".zip"):
+			file.rejectReason = "nested archives are not supported"
+		case validateFileType(base) != nil:
+			file.rejectReason = validateFileType(base).Error()
+		case entry.UncompressedSize64 > uint64(limits.maxFileSize):
+			file.rejectReason = fmt.Sprintf("file is too large: maximum is %d bytes", limits.maxFileSize)
+		case entry.CompressedSize64 > 0 && entry.UncompressedSize64/entry.CompressedSize64 > limits.maxCompressRatio:
+			file.rejectReason = "suspicious compression ratio"
+		default:
+			// Заголовку доверять нельзя: фактический размер проверяется при чтении
+			entry := entry
+			file.read = func() ([]byte, string) {
+				return readZipEntry(entry, limits.maxFileSize)
+			}
+		}
+
+		if err := add(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readZipEntry(entry *zip.File, maxSize int64) ([]byte, string) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Sprintf("failed to open file: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxSize+1))
+	if err != nil {
+		return nil, fmt.Sprintf("failed to unpack file: %v", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Sprintf("file is too large: maximum is %d bytes", maxSize)
+	}
+	return data, ""
+}
+
+// decodeZipName возвращает имя файла в UTF-8. Архиваторы Windows без флага UTF-8
+// записывают кириллические имена в CP866.
+func decodeZipName(entry *zip.File) string {
+	if !entry.NonUTF8 || utf8.ValidString(entry.Name) {
+		return entry.Name
+	}
+	if decoded, err := charmap.CodePage866.NewDecoder().String(entry.Name); err == nil {
+		return decoded
+	}
+	return entry.Name
+}
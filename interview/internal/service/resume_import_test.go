@@ -0,0 +1,245 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+type zipEntry struct {
+	name    string
+	data    []byte
+	nonUTF8 bool
+}
+
+func buildZip(t *testing.T, entries ...zipEntry) ImportUpload {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, NonUTF8: entry.nonUTF8})
+		require.NoError(t, err)
+		_, err = w.Write(entry.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return ImportUpload{Filename: "resumes.zip", Reader: bytes.NewReader(buf.Bytes()), Size: int64(buf.Len())}
+}
+
+func plainUpload(name, content string) ImportUpload {
+	return ImportUpload{Filename: name, Reader: strings.NewReader(content), Size: int64(len(content))}
+}
+
+var testImportLimits = importLimits{
+	maxUploadSize:    1 << 20,
+	maxFiles:         5,
+	maxFileSize:      64 * 1024,
+	maxUnpackedSize:  100 * 1024,
+	maxCompressRatio: 50,
+}
+
+func TestCollectImportFiles_Archive(t *testing.T) {
+	cp866Name, err := charmap.CodePage866.NewEncoder().String("Иванов.txt")
+	require.NoError(t, err)
+
+	upload := buildZip(t,
+		zipEntry{name: "agency/petrov.txt", data: []byte("Петр Петров, Go разработчик")},
+		zipEntry{name: cp866Name, data: []byte("Иван Иванов"), nonUTF8: true},
+		zipEntry{name: "__MACOSX/agency/._petrov.txt", data: []byte("служебный файл")},
+		zipEntry{name: "agency/.DS_Store", data: []byte("служебный файл")},
+		zipEntry{name: "bomb.txt", data: make([]byte, 60*1024)},
+		zipEntry{name: "big.pdf", data: bytes.Repeat([]byte("%PDF-1.4 случайные данные 0123456789"), 3000)},
+		zipEntry{name: "inner.zip", data: []byte("PK")},
+		zipEntry{name: "photo.jpg", data: []byte("jpeg")},
+	)
+
+	files, err := collectImportFiles([]ImportUpload{upload, plainUpload("sidorov.rtf", "{\\rtf1 Сидоров}")}, importLimits{
+		maxUploadSize:    testImportLimits.maxUploadSize,
+		maxFiles:         10,
+		maxFileSize:      testImportLimits.maxFileSize,
+		maxUnpackedSize:  testImportLimits.maxUnpackedSize,
+		maxCompressRatio: testImportLimits.maxCompressRatio,
+	})
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	for _, file := range files {
+		got[file.name] = file.rejectReason
+	}
+
+	assert.Equal(t, map[string]string{
+		"petrov.txt":  "",
+		"Иванов.txt":  "",
+		"bomb.txt":    "suspicious compression ratio",
+		"big.pdf":     "file is too large: maximum is 65536 bytes",
+		"inner.zip":   "nested archives are not supported",
+		"photo.jpg":   "unsupported file type: .jpg. Allowed types: .docx, .doc, .pdf, .rtf, .odt, .txt",
+		"sidorov.rtf": "",
+	}, got)
+
+	data, reason := files[0].read()
+	assert.Empty(t, reason)
+	assert.Equal(t, "Петр Петров, Go разработчик", string(data))
+}
+
+func TestCollectImportFiles_Limits(t *testing.T) {
+	t.Run("слишком много файлов", func(t *testing.T) {
+		var uploads []ImportUpload
+		for i := 0; i < 6; i++ {
+			uploads = append(uploads, plainUpload("cv.txt", "резюме"))
+		}
+
+		_, err := collectImportFiles(uploads, testImportLimits)
+		assert.EqualError(t, err, "too many files: maximum is 5")
+	})
+
+	t.Run("превышен суммарный размер распакованных файлов", func(t *testing.T) {
+		chunk := strings.Repeat("резюме кандидата ", 1500) // ~46KB на файл
+		upload := buildZip(t,
+			zipEntry{name: "a.txt", data: []byte(chunk + "a")},
+			zipEntry{name: "b.txt", data: []byte(chunk + "b")},
+			zipEntry{name: "c.txt", data: []byte(chunk + "c")},
+		)
+
+		limits := testImportLimits
+		limits.maxCompressRatio = 1 << 20
+		_, err := collectImportFiles([]ImportUpload{upload}, limits)
+		assert.EqualError(t, err, "unpacked files exceed 102400 bytes")
+	})
+
+	t.Run("загрузка больше допустимого", func(t *testing.T) {
+		upload := plainUpload("huge.zip", "")
+		upload.Size = 2 << 20
+
+		_, err := collectImportFiles([]ImportUpload{upload}, testImportLimits)
+		assert.EqualError(t, err, "file huge.zip is too large: maximum is 1048576 bytes")
+	})
+
+	t.Run("поврежденный архив", func(t *testing.T) {
+		_, err := collectImportFiles([]ImportUpload{plainUpload("broken.zip", "not a zip")}, testImportLimits)
+		assert.ErrorContains(t, err, "file broken.zip is not a valid ZIP archive")
+	})
+}
+
+type fakeImportRepository struct {
+	repository.ResumeImportRepository
+	mu   sync.Mutex
+	jobs map[string]models.ResumeImportJob
+}
+
+func (r *fakeImportRepository) Create(ctx context.Context, job *models.ResumeImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job.ID = fmt.Sprintf("job-%d", len(r.jobs)+1)
+	for i := range job.Items {
+		job.Items[i].ID = fmt.Sprintf("%s-%d", job.ID, i+1)
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *fakeImportRepository) UpdateJob(ctx context.Context, job *models.ResumeImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *fakeImportRepository) UpdateItem(ctx context.Context, item *models.ResumeImportItem) error {
+	return nil
+}
+
+func (r *fakeImportRepository) FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeImportRepository) job(id string) models.ResumeImportJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[id]
+}
+
+type fakeImportResumes struct {
+	ResumeService
+	mu      sync.Mutex
+	created []string
+}
+
+func (s *fakeImportResumes) CreateResume(ctx context.Context, resume *models.Resume, file io.Reader, filename string) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resume.ID = "resume-" + filename
+	s.created = append(s.created, string(data))
+	return nil
+}
+
+func TestResumeImport_Lifecycle(t *testing.T) {
+	vacancies := &fakeRankingVacancies{vacancy: &models.Vacancy{ID: "vacancy"}}
+
+	t.Run("импорт не принимается, пока сервис не запущен", func(t *testing.T) {
+		svc := NewResumeImportService(&fakeImportRepository{jobs: map[string]models.ResumeImportJob{}}, vacancies, &fakeImportResumes{})
+		_, err := svc.StartImport(context.Background(), "vacancy", []ImportUpload{plainUpload("cv.txt", "резюме")})
+		assert.ErrorIs(t, err, ErrImportNotRunning)
+	})
+
+	t.Run("файлы читаются из временной копии после запроса", func(t *testing.T) {
+		repo := &fakeImportRepository{jobs: map[string]models.ResumeImportJob{}}
+		resumes := &fakeImportResumes{}
+		svc := NewResumeImportService(repo, vacancies, resumes)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			svc.Run(ctx)
+			close(done)
+		}()
+		// Загрузка перестает читаться сразу после запроса, как файл multipart формы
+		var archive bytes.Buffer
+		upload := buildZip(t,
+			zipEntry{name: "petrov.txt", data: []byte("Петр Петров")},
+			zipEntry{name: "ivanov.txt", data: []byte("Иван Иванов")},
+		)
+		_, err := io.Copy(&archive, io.NewSectionReader(upload.Reader, 0, upload.Size))
+		require.NoError(t, err)
+		reader := bytes.NewReader(archive.Bytes())
+		upload.Reader = reader
+
+		var job *models.ResumeImportJob
+		require.Eventually(t, func() bool {
+			job, err = svc.StartImport(context.Background(), "vacancy", []ImportUpload{upload})
+			return !errors.Is(err, ErrImportNotRunning)
+		}, time.Second, time.Millisecond, "Run запущен")
+		require.NoError(t, err)
+		reader.Reset(nil)
+
+		require.Eventually(t, func() bool {
+			return repo.job(job.ID).Status == models.ImportStatusCompleted
+		}, time.Second, time.Millisecond)
+		cancel()
+		<-done
+
+		saved := repo.job(job.ID)
+		assert.Equal(t, 2, saved.Succeeded)
+		assert.ElementsMatch(t, []string{"Петр Петров", "Иван Иванов"}, resumes.created)
+	})
+}
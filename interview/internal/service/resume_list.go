@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"interview/internal/models"
+)
+
+const (
+	DefaultResumePageSize = 50
+	MaxResumePageSize     = 200
+)
+
+var ErrInvalidResumeQuery = errors.New("invalid resume list query")
+
+// ResumePage - страница списка резюме. NextCursor пуст, если это последняя страница.
+type ResumePage struct {
+	Resumes    []*models.ResumeListItem `json:"resumes"`
+	Count      int                      `json:"count"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+}
+
+func (s *resumeService) ListResumes(ctx context.Context, vacancyID string, filter models.ResumeFilter, cursor string) (*ResumePage, error) {
+	if err := normalizeResumeFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	var after *models.ResumeCursor
+	if cursor != "" {
+		decoded, err := decodeResumeCursor(cursor)
+		if err != nil || decoded.Sort != filter.Sort || decoded.Desc != filter.Desc {
+			return nil, fmt.Errorf("%w: cursor does not match the query", ErrInvalidResumeQuery)
+		}
+		after = decoded
+	}
+
+	// Запрашиваем на один элемент больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+	items, err := s.repo.ListByVacancy(ctx, vacancyID, filter, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resumes: %w", err)
+	}
+
+	page := &ResumePage{Resumes: items}
+	if len(items) > limit {
+		page.Resumes = items[:limit]
+		page.NextCursor = encodeResumeCursor(filter, items[limit-1])
+	}
+	page.Count = len(page.Resumes)
+	return page, nil
+}
+
+func normalizeResumeFilter(filter *models.ResumeFilter) error {
+	switch filter.Sort {
+	case "":
+		filter.Sort = models.ResumeSortCreatedAt
+	case models.ResumeSortCreatedAt, models.ResumeSortScore:
+	default:
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidResumeQuery, filter.Sort)
+	}
+
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = DefaultResumePageSize
+	case filter.Limit < 0 || filter.Limit > MaxResumePageSize:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidResumeQuery, MaxResumePageSize)
+	}
+
+	for _, status := range filter.Statuses {
+		if !status.Valid() {
+			return fmt.Errorf("%w: %w: %q", ErrInvalidResumeQuery, ErrUnknownResumeStatus, status)
+		}
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		return fmt.Errorf("%w: min_score is greater than max_score", ErrInvalidResumeQuery)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return fmt.Errorf("%w: created_from must be before created_to", ErrInvalidResumeQuery)
+	}
+	return nil
+}
+
+// encodeResumeCursor кодирует позицию последнего элемента страницы в непрозрачную строку
+func encodeResumeCursor(filter models.ResumeFilter, last *models.ResumeListItem) string {
+	cursor := models.ResumeCursor{Sort: filter.Sort, Desc: filter.Desc, ID: last.ID}
+	if filter.Sort == models.ResumeSortScore {
+		cursor.Score = -1 // так сортируются резюме без оценки
+		if last.Score != nil {
+			cursor.Score = *last.Score
+		}
+	} else {
+		cursor.CreatedAt = last.CreatedAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeResumeCursor(value string) (*models.ResumeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor models.ResumeCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == "" {
+		return nil, errors.New("cursor without id")
+	}
+	return &cursor, nil
+}
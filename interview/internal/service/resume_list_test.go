@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// fakeListRepository отдает заранее отсортированные резюме, начиная после курсора
+type fakeListRepository struct {
+	repository.ResumeRepository
+	items  []*models.ResumeListItem
+	filter models.ResumeFilter
+}
+
+func (r *fakeListRepository) ListByVacancy(ctx context.Context, vacancyID string, filter models.ResumeFilter, after *models.ResumeCursor) ([]*models.ResumeListItem, error) {
+	r.filter = filter
+	start := 0
+	if after != nil {
+		for i, item := range r.items {
+			if item.ID == after.ID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + filter.Limit
+	if end > len(r.items) {
+		end = len(r.items)
+	}
+	return r.items[start:end], nil
+}
+
+func listItems(count int) []*models.ResumeListItem {
+	items := make([]*models.ResumeListItem, 0, count)
+	for i := 0; i < count; i++ {
+		score := float64(100 - i)
+		items = append(items, &models.ResumeListItem{
+			ID:        fmt.Sprintf("resume-%d", i),
+			CreatedAt: time.Date(2025, 9, 1, 10, i, 0, 0, time.UTC),
+			Score:     &score,
+		})
+	}
+	return items
+}
+
+func TestListResumes_Pages(t *testing.T) {
+	repo := &fakeListRepository{items: listItems(5)}
+	svc := &resumeService{repo: repo}
+	filter := models.ResumeFilter{Sort: models.ResumeSortScore, Desc: true, Limit: 2}
+
+	var ids []string
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := svc.ListResumes(context.Background(), "vacancy", filter, cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 3, repo.filter.Limit, "запрашивается на один элемент больше")
+		for _, item := range page.Resumes {
+			ids = append(ids, item.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []string{"resume-0", "resume-1", "resume-2", "resume-3", "resume-4"}, ids)
+}
+
+func TestListResumes_Cursor(t *testing.T) {
+	filter := models.ResumeFilter{Sort: models.ResumeSortScore, Desc: true}
+	last := &models.ResumeListItem{ID: "resume-1"}
+
+	cursor, err := decodeResumeCursor(encodeResumeCursor(filter, last))
+	require.NoError(t, err)
+	assert.Equal(t, float64(-1), cursor.Score, "резюме без оценки сортируются как -1")
+	assert.Equal(t, "resume-1", cursor.ID)
+
+	// Курсор от другой сортировки не принимается
+	svc := &resumeService{repo: &fakeListRepository{}}
+	_, err = svc.ListResumes(context.Background(), "vacancy", models.ResumeFilter{Sort: models.ResumeSortCreatedAt}, encodeResumeCursor(filter, last))
+	assert.ErrorIs(t, err, ErrInvalidResumeQuery)
+}
+
+func TestNormalizeResumeFilter(t *testing.T) {
+	low, high := 80.0, 20.0
+	from := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter models.ResumeFilter
+		want   string
+	}{
+		{"неизвестная сортировка", models.ResumeFilter{Sort: "mail"}, `unknown sort field "mail"`},
+		{"слишком большая страница", models.ResumeFilter{Limit: MaxResumePageSize + 1}, "limit must be between"},
+		{"неизвестный статус", models.ResumeFilter{Statuses: []models.ResumeStatus{"hired"}}, "unknown resume status"},
+		{"перепутан диапазон баллов", models.ResumeFilter{MinScore: &low, MaxScore: &high}, "min_score is greater than max_score"},
+		{"перепутан диапазон дат", models.ResumeFilter{CreatedFrom: &from, CreatedTo: &to}, "created_from must be before created_to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeResumeFilter(&tt.filter)
+			require.ErrorIs(t, err, ErrInvalidResumeQuery)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	filter := models.ResumeFilter{}
+	require.NoError(t, normalizeResumeFilter(&filter))
+	assert.Equal(t, models.ResumeSortCreatedAt, filter.Sort)
+	assert.Equal(t, DefaultResumePageSize, filter.Limit)
+}
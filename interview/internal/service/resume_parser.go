@@ -0,0 +1,717 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"interview/internal/models"
+)
+
+// resumeSection - смысловой раздел резюме
+type resumeSection int
+
+const (
+	sectionHeader resumeSection = iota // начало резюме до первого заголовка: ФИО и контакты
+	sectionContacts
+	sectionExperience
+	sectionEducation
+	sectionCourses
+	sectionSkills
+	sectionLanguages
+	sectionOther
+)
+
+// resumeSectionHeadings - заголовки разделов, которые встречаются в русскоязычных резюме
+// (hh.ru, SuperJob, самостоятельно сверстанные документы). Ключи нормализованы normalizeHeading.
+var resumeSectionHeadings = map[string]resumeSection{
+	"контакты":              sectionContacts,
+	"контактная информация": sectionContacts,
+	"контактные данные":     sectionContacts,
+	"личная информация":     sectionContacts,
+	"личные данные":         sectionContacts,
+	"contacts":              sectionContacts,
+
+	"опыт работы": sectionExperience,
+	"опыт":        sectionExperience,
+	"трудовая деятельность":         sectionExperience,
+	"места работы":                  sectionExperience,
+	"место работы":                  sectionExperience,
+	"профессиональный опыт":         sectionExperience,
+	"стаж работы":                   sectionExperience,
+	"трудовой стаж":                 sectionExperience,
+	"work experience":               sectionExperience,
+	"experience":                    sectionExperience,
+	"профессиональная деятельность": sectionExperience,
+
+	"образование":          sectionEducation,
+	"образование и вуз":    sectionEducation,
+	"основное образование": sectionEducation,
+	"высшее образование":   sectionEducation,
+	"учебные заведения":    sectionEducation,
+	"education":            sectionEducation,
+
+	"повышение квалификации":        sectionCourses,
+	"повышение квалификации, курсы": sectionCourses,
+	"курсы": sectionCourses,
+	"дополнительное образование": sectionCourses,
+	"сертификаты":                sectionCourses,
+	"тесты, экзамены":            sectionCourses,
+	"courses":                    sectionCourses,
+
+	"навыки":                  sectionSkills,
+	"ключевые навыки":         sectionSkills,
+	"профессиональные навыки": sectionSkills,
+	"технические навыки":      sectionSkills,
+	"компетенции":             sectionSkills,
+	"технологии":              sectionSkills,
+	"стек технологий":         sectionSkills,
+	"skills":                  sectionSkills,
+
+	"знание языков":     sectionLanguages,
+	"иностранные языки": sectionLanguages,
+	"языки":             sectionLanguages,
+	"владение языками":  sectionLanguages,
+	"languages":         sectionLanguages,
+
+	"о себе":  sectionOther,
+	"обо мне": sectionOther,
+	"дополнительная информация":     sectionOther,
+	"желаемая должность":            sectionOther,
+	"желаемая должность и зарплата": sectionOther,
+	"цель":         sectionOther,
+	"хобби":        sectionOther,
+	"рекомендации": sectionOther,
+	"портфолио":    sectionOther,
+	"about":        sectionOther,
+}
+
+// resumeHeadingsByLength - заголовки, отсортированные по убыванию длины,
+// чтобы "опыт работы" находился раньше, чем "опыт"
+var resumeHeadingsByLength = func() []string {
+	headings := make([]string, 0, len(resumeSectionHeadings))
+	for heading := range resumeSectionHeadings {
+		headings = append(headings, heading)
+	}
+	sort.Slice(headings, func(i, j int) bool {
+		if len(headings[i]) != len(headings[j]) {
+			return len(headings[i]) > len(headings[j])
+		}
+		return headings[i] < headings[j]
+	})
+	return headings
+}()
+
+var (
+	resumeEmailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	resumePhoneRe = regexp.MustCompile(`\+?\d[\d\s\-()]{8,17}\d`)
+	resumeYearRe  = regexp.MustCompile(`(?:19|20)\d{2}`)
+	resumeURLRe   = regexp.MustCompile(`(?i)https?://|www\.|\S+\.(?:ru|com|рф|net|org|io|su|by|kz)(?:/|\s|$)`)
+
+	// Дата вида "Январь 2019", "янв. 2019", "03.2019", "2019"
+	resumeDatePattern = `(?:([a-zа-яё]{3,9})\.?[ \t]+|(\d{1,2})[./])?((?:19|20)\d{2})`
+	// Открытая дата окончания: "по настоящее время", "н.в.", "present"
+	resumePresentPattern = `(настоящее\s+время|наст\.?\s*вр\.?|н\.\s*в\.?|текущее\s+время|сейчас|present|now)`
+	resumeDateRangeRe    = regexp.MustCompile(`(?i)(?:с\s+)?` + resumeDatePattern +
+		`\s*(?:—|–|-|по|до)\s*(?:по\s+)?(?:` + resumePresentPattern + `|` + resumeDatePattern + `)`)
+
+	// Стаж вида "6 лет 2 месяца", "1 год", "10 месяцев"
+	resumeDurationRe = regexp.MustCompile(`(?i)^(?:(\d{1,2})\s+(?:года|год|лет)\s*)?(?:(\d{1,2})\s+месяц(?:а|ев)?)?`)
+	resumeTotalRe    = regexp.MustCompile(`(?i)(\d{1,2})\s+(?:года|год|лет)(?:\s+(\d{1,2})\s+месяц(?:а|ев)?)?|(\d{1,2})\s+месяц(?:а|ев)?`)
+
+	resumeEducationYearsRe = regexp.MustCompile(`(?:(?:19|20)\d{2}\s*(?:—|–|-)\s*)?(?:19|20)\d{2}(?:\s*г(?:од)?\.?)?`)
+	resumeSkillSeparatorRe = regexp.MustCompile(`\s*[,;•·|\t]\s*|\s{2,}`)
+)
+
+// resumeMonths - месяц по первым трем буквам названия
+var resumeMonths = map[string]int{
+	"янв": 1, "фев": 2, "мар": 3, "апр": 4, "май": 5, "мая": 5, "июн": 6,
+	"июл": 7, "авг": 8, "сен": 9, "окт": 10, "ноя": 11, "дек": 12,
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// resumeCityLabels - подписи, после которых в резюме указывают город
+var resumeCityLabels = []string{
+	"город", "проживает", "место жительства", "место проживания",
+	"местоположение", "адрес", "city", "location",
+}
+
+// resumeEducationLevels - уровни образования; более длинные варианты идут раньше
+var resumeEducationLevels = []string{
+	"неоконченное высшее", "среднее специальное", "среднее профессиональное",
+	"высшее", "среднее", "бакалавр", "магистр", "специалист",
+	"аспирантура", "кандидат наук", "доктор наук", "mba",
+}
+
+// resumeLanguageStems - основы названий языков для отсечения произвольного текста
+var resumeLanguageStems = []string{
+	"русск", "англ", "немец", "франц", "испан", "итальян", "китай", "япон",
+	"корей", "турец", "арабск", "португал", "польск", "чешск", "украин",
+	"белорус", "казах", "татар", "иврит", "финск", "шведск",
+	"english", "german", "french", "spanish", "italian", "chinese",
+	"japanese", "russian",
+}
+
+// ParseCandidateProfile разбирает текст резюме в структурированный профиль кандидата
+func ParseCandidateProfile(text string) *models.CandidateProfile {
+	return parseCandidateProfile(text, time.Now())
+}
+
+func parseCandidateProfile(text string, now time.Time) *models.CandidateProfile {
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	sections := splitResumeSections(text)
+
+	var personalLines []string
+	for _, section := range []resumeSection{sectionHeader, sectionContacts, sectionOther} {
+		personalLines = append(personalLines, sections[section]...)
+	}
+
+	profile := &models.CandidateProfile{
+		FullName: findFullName(sections[sectionHeader]),
+		Email:    findEmail(text),
+		Phone:    findPhone(text),
+		City:     findCity(personalLines),
+	}
+
+	profile.Experience, profile.TotalExperienceMonths = parseWorkExperience(sections[sectionExperience], now)
+	profile.Education = append(
+		parseEducation(sections[sectionEducation], ""),
+		parseEducation(sections[sectionCourses], "Курсы")...,
+	)
+	profile.Skills = parseSkills(sections[sectionSkills])
+	profile.Languages = parseLanguages(sections[sectionLanguages])
+
+	return profile
+}
+
+// splitResumeSections раскладывает непустые строки резюме по разделам.
+// Текст после двоеточия в строке заголовка ("Навыки: Go, SQL") относится к разделу.
+func splitResumeSections(text string) map[resumeSection][]string {
+	sections := make(map[resumeSection][]string)
+	current := sectionHeader
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if section, rest, ok := detectResumeHeading(line); ok {
+			current = section
+			if rest != "" {
+				sections[current] = append(sections[current], rest)
+			}
+			continue
+		}
+		sections[current] = append(sections[current], line)
+	}
+
+	return sections
+}
+
+// detectResumeHeading распознает строку-заголовок раздела и возвращает остаток строки после него
+func detectResumeHeading(line string) (resumeSection, string, bool) {
+	if section, ok := resumeSectionHeadings[normalizeHeading(line)]; ok {
+		return section, "", true
+	}
+
+	if idx := strings.Index(line, ":"); idx > 0 {
+		if section, ok := resumeSectionHeadings[normalizeHeading(line[:idx])]; ok {
+			return section, strings.TrimSpace(line[idx+1:]), true
+		}
+	}
+
+	// Заголовок со стажем: "Опыт работы — 6 лет 2 месяца"
+	norm := normalizeHeading(line)
+	for _, heading := range resumeHeadingsByLength {
+		if !strings.HasPrefix(norm, heading+" ") {
+			continue
+		}
+		rest := strings.TrimLeft(norm[len(heading):], " —–-")
+		if rest != "" && isASCIIDigit(rest[0]) {
+			return resumeSectionHeadings[heading], rest, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// normalizeHeading приводит строку к нижнему регистру, заменяет ё на е,
+// схлопывает пробелы и убирает завершающие двоеточия и точки
+func normalizeHeading(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, "ё", "е"))
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimRight(s, ":.")
+}
+
+func findEmail(text string) string {
+	return strings.ToLower(resumeEmailRe.FindString(text))
+}
+
+// findPhone ищет телефон и приводит российские номера к виду +7XXXXXXXXXX
+func findPhone(text string) string {
+	for _, candidate := range resumePhoneRe.FindAllString(text, -1) {
+		var digits strings.Builder
+		for _, r := range candidate {
+			if r >= '0' && r <= '9' {
+				digits.WriteRune(r)
+			}
+		}
+		d := digits.String()
+
+		// Несколько годов подряд ("2015 2019 2020") - не телефон
+		if resumeYearRe.ReplaceAllString(strings.Join(strings.Fields(candidate), ""), "") == "" {
+			continue
+		}
+
+		switch {
+		case len(d) == 11 && (d[0] == '7' || d[0] == '8'):
+			return "+7" + d[1:]
+		case len(d) == 10 && !strings.HasPrefix(candidate, "+"):
+			return "+7" + d
+		case len(d) >= 10 && len(d) <= 13 && strings.HasPrefix(candidate, "+"):
+			return "+" + d
+		}
+	}
+	return ""
+}
+
+// findFullName ищет ФИО в первых строках резюме: 2-3 слова с заглавной буквы без цифр
+func findFullName(lines []string) string {
+	for i, line := range lines {
+		if i >= 6 {
+			break
+		}
+
+		if idx := strings.Index(line, ":"); idx > 0 {
+			label := normalizeHeading(line[:idx])
+			if label == "фио" || label == "имя" || label == "name" {
+				return strings.TrimSpace(line[idx+1:])
+			}
+			continue
+		}
+
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "резюме") || strings.Contains(lower, "resume") {
+			continue
+		}
+
+		words := strings.Fields(line)
+		if len(words) < 2 || len(words) > 3 {
+			continue
+		}
+		if allNameWords(words) {
+			return strings.Join(words, " ")
+		}
+	}
+	return ""
+}
+
+func allNameWords(words []string) bool {
+	for _, word := range words {
+		first, _ := utf8.DecodeRuneInString(word)
+		if !unicode.IsUpper(first) {
+			return false
+		}
+		for _, r := range word {
+			if !unicode.IsLetter(r) && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// findCity ищет город по подписям "Город:", "Проживает:" или по строке "г. Москва"
+func findCity(lines []string) string {
+	for _, line := range lines {
+		idx := strings.Index(line, ":")
+		if idx <= 0 {
+			continue
+		}
+		label := normalizeHeading(line[:idx])
+		for _, cityLabel := range resumeCityLabels {
+			if strings.HasPrefix(label, cityLabel) {
+				if city := cleanCity(line[idx+1:]); city != "" {
+					return city
+				}
+			}
+		}
+	}
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "г.") || strings.HasPrefix(line, "г ") {
+			if city := cleanCity(line); city != "" {
+				return city
+			}
+		}
+	}
+	return ""
+}
+
+// cleanCity оставляет название города без уточнений в скобках и после запятой
+func cleanCity(value string) string {
+	value = strings.TrimSpace(value)
+	if idx := strings.IndexAny(value, ",(;"); idx >= 0 {
+		value = value[:idx]
+	}
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "г.")
+	value = strings.TrimPrefix(value, "г ")
+	return strings.TrimSpace(value)
+}
+
+// parseWorkExperience делит раздел опыта на места работы по диапазонам дат.
+// Текст после диапазона: первая строка - компания, вторая - должность, остальное - описание.
+func parseWorkExperience(lines []string, now time.Time) ([]models.WorkExperience, int) {
+	if len(lines) == 0 {
+		return nil, 0
+	}
+	body := strings.Join(lines, "\n")
+	matches := resumeDateRangeRe.FindAllStringSubmatchIndex(body, -1)
+
+	totalMonths := 0
+	beforeFirst := body
+	if len(matches) > 0 {
+		beforeFirst = body[:matches[0][0]]
+	}
+	if m := resumeTotalRe.FindStringSubmatch(beforeFirst); m != nil {
+		totalMonths = atoiOrZero(m[1])*12 + atoiOrZero(m[2]) + atoiOrZero(m[3])
+	}
+
+	var jobs []models.WorkExperience
+	computedMonths := 0
+	prevEnd := 0
+	for i, m := range matches {
+		job := models.WorkExperience{}
+
+		startYear, startMonth := resumeDate(body, m[2:8])
+		job.StartDate = formatResumeDate(startYear, startMonth)
+
+		var endYear, endMonth int
+		if m[8] >= 0 {
+			job.Current = true
+			endYear, endMonth = now.Year(), int(now.Month())
+		} else {
+			endYear, endMonth = resumeDate(body, m[10:16])
+			job.EndDate = formatResumeDate(endYear, endMonth)
+		}
+		computedMonths += monthsBetween(startYear, startMonth, endYear, endMonth)
+
+		// Текст на той же строке перед диапазоном ("ООО Ромашка, 2019 — 2021") относится к этому месту работы
+		var segmentLines []string
+		lineStart := strings.LastIndex(body[:m[0]], "\n") + 1
+		if lineStart >= prevEnd {
+			prefix := strings.TrimSpace(body[lineStart:m[0]])
+			if prefix != "" && !resumeTotalRe.MatchString(prefix) {
+				segmentLines = append(segmentLines, prefix)
+			}
+		}
+
+		segmentEnd := len(body)
+		if i+1 < len(matches) {
+			segmentEnd = matches[i+1][0]
+			if nextLineStart := strings.LastIndex(body[:segmentEnd], "\n") + 1; nextLineStart > m[1] {
+				segmentEnd = nextLineStart
+			}
+		}
+		prevEnd = segmentEnd
+
+		segmentLines = append(segmentLines, experienceSegmentLines(body[m[1]:segmentEnd])...)
+		fillWorkExperience(&job, segmentLines)
+		jobs = append(jobs, job)
+	}
+
+	if totalMonths == 0 {
+		totalMonths = computedMonths
+	}
+	return jobs, totalMonths
+}
+
+// experienceSegmentLines убирает из текста места работы стаж и строки с адресом сайта
+func experienceSegmentLines(segment string) []string {
+	var lines []string
+	for _, line := range strings.Split(segment, "\n") {
+		line = strings.TrimSpace(line)
+		if len(lines) == 0 {
+			line = strings.TrimSpace(strings.TrimPrefix(line, resumeDurationRe.FindString(line)))
+		}
+		if line == "" || (utf8.RuneCountInString(line) < 60 && resumeURLRe.MatchString(line)) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func fillWorkExperience(job *models.WorkExperience, lines []string) {
+	switch {
+	case len(lines) == 0:
+		return
+	case len(lines) == 1:
+		// Однострочный формат: "ООО Ромашка, разработчик"
+		line := lines[0]
+		if utf8.RuneCountInString(line) > 100 {
+			job.Description = line
+			return
+		}
+		for _, sep := range []string{" — ", " – ", " - ", ", "} {
+			if idx := strings.Index(line, sep); idx > 0 {
+				job.Company = trimResumeValue(line[:idx])
+				job.Position = trimResumeValue(line[idx+len(sep):])
+				return
+			}
+		}
+		job.Company = trimResumeValue(line)
+	default:
+		job.Company = trimResumeValue(lines[0])
+		job.Position = trimResumeValue(lines[1])
+		job.Description = strings.Join(lines[2:], "\n")
+	}
+}
+
+// parseEducation разбирает раздел образования: уровень ("Высшее"), год выпуска,
+// учебное заведение и специальность
+func parseEducation(lines []string, defaultLevel string) []models.Education {
+	var result []models.Education
+	level := defaultLevel
+	var current *models.Education
+
+	for _, line := range lines {
+		if lvl, rest := splitEducationLevel(line); lvl != "" {
+			level = lvl
+			line = rest
+			if line == "" {
+				continue
+			}
+		}
+
+		years := resumeYearRe.FindAllString(line, -1)
+		if len(years) > 0 {
+			year, _ := strconv.Atoi(years[len(years)-1])
+			rest := trimResumeValue(resumeEducationYearsRe.ReplaceAllString(line, ""))
+
+			// Год после названия заведения: "МГУ\n2015"
+			if current != nil && current.Year == 0 && current.Institution != "" && rest == "" {
+				current.Year = year
+				continue
+			}
+
+			result = append(result, models.Education{Level: level, Year: year})
+			current = &result[len(result)-1]
+			if rest != "" {
+				// Однострочный формат: "2015 МГУ, факультет ВМК"
+				if idx := strings.Index(rest, ", "); idx > 0 {
+					current.Institution = trimResumeValue(rest[:idx])
+					current.Specialty = trimResumeValue(rest[idx+2:])
+				} else {
+					current.Institution = rest
+				}
+			}
+			continue
+		}
+
+		line = trimResumeValue(line)
+		switch {
+		case current == nil || (current.Institution != "" && current.Specialty != ""):
+			result = append(result, models.Education{Level: level, Institution: line})
+			current = &result[len(result)-1]
+		case current.Institution == "":
+			current.Institution = line
+		default:
+			current.Specialty = line
+		}
+	}
+
+	return result
+}
+
+// splitEducationLevel отделяет уровень образования в начале строки
+func splitEducationLevel(line string) (string, string) {
+	lower := strings.ToLower(line)
+	for _, level := range resumeEducationLevels {
+		if !strings.HasPrefix(lower, level) {
+			continue
+		}
+		rest := line[len(level):]
+		if rest != "" {
+			r, _ := utf8.DecodeRuneInString(rest)
+			if unicode.IsLetter(r) {
+				continue
+			}
+		}
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(strings.ToLower(rest), "образование") {
+			rest = strings.TrimSpace(rest[len("образование"):])
+		}
+		return line[:len(level)], trimResumeValue(rest)
+	}
+	return "", line
+}
+
+// parseSkills разбивает навыки по запятым, точкам с запятой, маркерам списка и табуляции
+func parseSkills(lines []string) []string {
+	var skills []string
+	seen := make(map[string]bool)
+
+	for _, line := range lines {
+		for _, item := range resumeSkillSeparatorRe.Split(line, -1) {
+			item = strings.TrimRight(strings.TrimLeft(item, "-*–— "), ". ")
+			if item == "" || utf8.RuneCountInString(item) > 50 {
+				continue
+			}
+			key := strings.ToLower(item)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			skills = append(skills, item)
+		}
+	}
+	return skills
+}
+
+// parseLanguages разбирает строки вида "Английский — B2 — Средне-продвинутый" или
+// "Русский (родной), английский (B2)"
+func parseLanguages(lines []string) []models.Language {
+	var languages []models.Language
+
+	for _, line := range lines {
+		for _, item := range splitOutsideParens(line) {
+			item = strings.TrimSpace(item)
+			end := strings.IndexFunc(item, func(r rune) bool {
+				return !unicode.IsLetter(r)
+			})
+			if end < 0 {
+				end = len(item)
+			}
+			name := item[:end]
+			if !isLanguageName(name) {
+				continue
+			}
+
+			level := strings.TrimSpace(item[end:])
+			if strings.HasPrefix(strings.ToLower(level), "язык") {
+				level = strings.TrimSpace(level[len("язык"):])
+			}
+			level = strings.Trim(level, " ()—–-:")
+
+			languages = append(languages, models.Language{Name: capitalizeFirst(name), Level: level})
+		}
+	}
+	return languages
+}
+
+func isLanguageName(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == "" {
+		return false
+	}
+	for _, stem := range resumeLanguageStems {
+		if strings.HasPrefix(lower, stem) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOutsideParens делит строку по запятым и точкам с запятой вне круглых скобок
+func splitOutsideParens(line string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range line {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',', ';':
+			if depth == 0 {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, line[start:])
+}
+
+// resumeDate возвращает год и месяц из групп (название месяца, номер месяца, год)
+func resumeDate(s string, groups []int) (year, month int) {
+	if groups[4] < 0 {
+		return 0, 0
+	}
+	year, _ = strconv.Atoi(s[groups[4]:groups[5]])
+
+	switch {
+	case groups[0] >= 0:
+		month = monthFromName(s[groups[0]:groups[1]])
+	case groups[2] >= 0:
+		month, _ = strconv.Atoi(s[groups[2]:groups[3]])
+		if month < 1 || month > 12 {
+			month = 0
+		}
+	}
+	return year, month
+}
+
+func monthFromName(name string) int {
+	runes := []rune(strings.ToLower(name))
+	if len(runes) < 3 {
+		return 0
+	}
+	return resumeMonths[string(runes[:3])]
+}
+
+func formatResumeDate(year, month int) string {
+	if year == 0 {
+		return ""
+	}
+	if month == 0 {
+		return strconv.Itoa(year)
+	}
+	return fmt.Sprintf("%04d-%02d", year, month)
+}
+
+// monthsBetween считает длительность работы в месяцах включительно.
+// Если месяц не указан, берется январь для начала и декабрь для окончания.
+func monthsBetween(startYear, startMonth, endYear, endMonth int) int {
+	if startYear == 0 || endYear == 0 {
+		return 0
+	}
+	if startMonth == 0 {
+		startMonth = 1
+	}
+	if endMonth == 0 {
+		endMonth = 12
+	}
+	months := (endYear-startYear)*12 + endMonth - startMonth + 1
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+func trimResumeValue(s string) string {
+	return strings.Trim(s, " ,;.—–-\t")
+}
+
+func capitalizeFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + strings.ToLower(s[size:])
+}
+
+func atoiOrZero(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
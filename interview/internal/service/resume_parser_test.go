@@ -0,0 +1,159 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+)
+
+var parserNow = time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+func TestParseCandidateProfile_HeadHunterLayout(t *testing.T) {
+	text := `Резюме
+Иванов Иван Сергеевич
+Мужчина, 32 года, родился 5 марта 1992
++7 (916) 123-45-67
+ivan.ivanov@Example.ru
+Проживает: Москва
+Гражданство: Россия
+
+Опыт работы — 6 лет 2 месяца
+Январь 2021 — настоящее время
+3 года 6 месяцев
+ООО «Ромашка»
+Москва, romashka.ru
+Ведущий Go-разработчик
+Разработка микросервисов
+Код-ревью
+
+Март 2018 — Декабрь 2020
+2 года 10 месяцев
+ПАО Банк
+Backend-разработчик
+
+Образование
+Высшее
+2014
+МГУ им. М.В. Ломоносова
+ВМК, Прикладная математика
+
+Повышение квалификации, курсы
+2020
+Яндекс Практикум
+Go-разработчик
+
+Ключевые навыки
+Go, PostgreSQL; Docker • Kubernetes
+go
+
+Знание языков
+Русский — Родной
+Английский — B2 — Средне-продвинутый`
+
+	profile := parseCandidateProfile(text, parserNow)
+
+	assert.Equal(t, "Иванов Иван Сергеевич", profile.FullName)
+	assert.Equal(t, "ivan.ivanov@example.ru", profile.Email)
+	assert.Equal(t, "+79161234567", profile.Phone)
+	assert.Equal(t, "Москва", profile.City)
+	assert.Equal(t, 74, profile.TotalExperienceMonths)
+
+	require.Len(t, profile.Experience, 2)
+	assert.Equal(t, models.WorkExperience{
+		Company:     "ООО «Ромашка»",
+		Position:    "Ведущий Go-разработчик",
+		StartDate:   "2021-01",
+		Current:     true,
+		Description: "Разработка микросервисов\nКод-ревью",
+	}, profile.Experience[0])
+	assert.Equal(t, models.WorkExperience{
+		Company:   "ПАО Банк",
+		Position:  "Backend-разработчик",
+		StartDate: "2018-03",
+		EndDate:   "2020-12",
+	}, profile.Experience[1])
+
+	assert.Equal(t, []models.Education{
+		{Level: "Высшее", Institution: "МГУ им. М.В. Ломоносова", Specialty: "ВМК, Прикладная математика", Year: 2014},
+		{Level: "Курсы", Institution: "Яндекс Практикум", Specialty: "Go-разработчик", Year: 2020},
+	}, profile.Education)
+
+	assert.Equal(t, []string{"Go", "PostgreSQL", "Docker", "Kubernetes"}, profile.Skills)
+	assert.Equal(t, []models.Language{
+		{Name: "Русский", Level: "Родной"},
+		{Name: "Английский", Level: "B2 — Средне-продвинутый"},
+	}, profile.Languages)
+}
+
+func TestParseCandidateProfile_InlineLayout(t *testing.T) {
+	text := `Саша Маркарян
+sasha@gmail.com
+Город, переезд, командировки: Советск (Калининградская область) , не готов к переезду
+Опыт работы: Опыт работы 16 лет 10 месяцев  Август 2010 — по настоящее время 8 лет 10 месяцев Школа Системный администратор  Август 2002 — Август  2010 8 лет 1 месяц ТС "ВЕСТЕР-ИНФО", Старший продавец
+Образование и ВУЗ: Неоконченное высшее образование 2000  Балтийская академия, Судовождение
+Навыки: 1С, Excel`
+
+	profile := parseCandidateProfile(text, parserNow)
+
+	assert.Equal(t, "Саша Маркарян", profile.FullName)
+	assert.Equal(t, "sasha@gmail.com", profile.Email)
+	assert.Empty(t, profile.Phone)
+	assert.Equal(t, "Советск", profile.City)
+	assert.Equal(t, 16*12+10, profile.TotalExperienceMonths)
+
+	require.Len(t, profile.Experience, 2)
+	assert.Equal(t, "2010-08", profile.Experience[0].StartDate)
+	assert.True(t, profile.Experience[0].Current)
+	assert.Equal(t, "2002-08", profile.Experience[1].StartDate)
+	assert.Equal(t, "2010-08", profile.Experience[1].EndDate)
+	assert.Equal(t, `ТС "ВЕСТЕР-ИНФО"`, profile.Experience[1].Company)
+	assert.Equal(t, "Старший продавец", profile.Experience[1].Position)
+
+	assert.Equal(t, []models.Education{
+		{Level: "Неоконченное высшее", Institution: "Балтийская академия", Specialty: "Судовождение", Year: 2000},
+	}, profile.Education)
+	assert.Equal(t, []string{"1С", "Excel"}, profile.Skills)
+}
+
+func TestParseCandidateProfile_ExperienceWithoutTotal(t *testing.T) {
+	text := `Петр Смирнов
+Опыт
+ООО Вектор, 03.2019 - 02.2021
+Аналитик
+2017 – 2018 ИП Сидоров — стажер`
+
+	profile := parseCandidateProfile(text, parserNow)
+
+	require.Len(t, profile.Experience, 2)
+	assert.Equal(t, models.WorkExperience{
+		Company: "ООО Вектор", Position: "Аналитик", StartDate: "2019-03", EndDate: "2021-02",
+	}, profile.Experience[0])
+	assert.Equal(t, models.WorkExperience{
+		Company: "ИП Сидоров", Position: "стажер", StartDate: "2017", EndDate: "2018",
+	}, profile.Experience[1])
+	assert.Equal(t, 24+24, profile.TotalExperienceMonths)
+}
+
+func TestFindPhone(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Тел.: 8 (912) 000-11-22", "+79120001122"},
+		{"+7 912 000 11 22", "+79120001122"},
+		{"9120001122", "+79120001122"},
+		{"+375 29 123-45-67", "+375291234567"},
+		{"Годы: 2015 2019 2020", ""},
+		{"Нет телефона", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findPhone(tt.text))
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+// ProcessingConfig - настройки очереди обработки резюме
+type ProcessingConfig struct {
+	Workers      int           // одновременно обрабатываемых резюме
+	MaxAttempts  int           // после стольких неудач задание помечается failed
+	PollInterval time.Duration // как часто проверять очередь
+	RetryDelay   time.Duration // задержка перед первым повтором, дальше удваивается
+	MaxDelay     time.Duration
+	JobTimeout   time.Duration // задание дольше считается зависшим
+}
+
+// processingMarkTimeout ограничивает запись результата задания: она идет на своем контексте,
+// потому что контекст обработки к этому моменту может уже истечь
+const processingMarkTimeout = 10 * time.Second
+
+var DefaultProcessingConfig = ProcessingConfig{
+	Workers:      4,
+	MaxAttempts:  5,
+	PollInterval: 2 * time.Second,
+	RetryDelay:   10 * time.Second,
+	MaxDelay:     30 * time.Minute,
+	JobTimeout:   5 * time.Minute,
+}
+
+// permanentFailure - ошибка, при которой повтор не поможет (например, битый файл)
+type permanentFailure struct {
+	err error
+}
+
+func (e *permanentFailure) Error() string { return e.err.Error() }
+func (e *permanentFailure) Unwrap() error { return e.err }
+
+func permanentError(err error) error {
+	return &permanentFailure{err: err}
+}
+
+type ResumeProcessingService interface {
+	// Run обрабатывает очередь до отмены ctx и ждет завершения начатых заданий
+	Run(ctx context.Context)
+	ListJobs(ctx context.Context, status string) ([]*models.ResumeProcessingJob, error)
+	// Requeue ставит резюме в очередь заново, сбрасывая счетчик попыток
+	Requeue(ctx context.Context, resumeID string) error
+	RequeueFailed(ctx context.Context) (int, error)
+}
+
+type resumeProcessingService struct {
+	jobs       repository.ProcessingJobRepository
+	resumeRepo repository.ResumeRepository
+	resumes    ResumeService
+	cfg        ProcessingConfig
+}
+
+func NewResumeProcessingService(
+	jobs repository.ProcessingJobRepository,
+	resumeRepo repository.ResumeRepository,
+	resumes ResumeService,
+	cfg ProcessingConfig,
+) ResumeProcessingService {
+	return &resumeProcessingService{
+		jobs:       jobs,
+		resumeRepo: resumeRepo,
+		resumes:    resumes,
+		cfg:        cfg,
+	}
+}
+
+func (s *resumeProcessingService) Run(ctx context.Context) {
+	log.Printf("⚙️ Resume processing started: %d workers", s.cfg.Workers)
+
+	// Задания, которые выполнялись при падении пода, возвращаются в очередь
+	s.recoverStale(ctx)
+
+	slots := make(chan struct{}, s.cfg.Workers)
+	var wg sync.WaitGroup
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		free := cap(slots) - len(slots)
+		if free > 0 {
+			jobs, err := s.jobs.ClaimDue(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("❌ Failed to claim processing jobs: %v", err)
+			}
+			for _, job := range jobs {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(job *models.ResumeProcessingJob) {
+					defer func() {
+						<-slots
+						wg.Done()
+					}()
+					s.runJob(job)
+				}(job)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Println("✅ Resume processing stopped")
+			return
+		case <-ticker.C:
+			s.recoverStale(ctx)
+		}
+	}
+}
+
+func (s *resumeProcessingService) recoverStale(ctx context.Context) {
+	recovered, failed, err := s.jobs.RecoverStale(ctx, time.Now().Add(-2*s.cfg.JobTimeout), s.cfg.MaxAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("❌ Failed to recover stale processing jobs: %v", err)
+		}
+		return
+	}
+	if recovered > 0 {
+		log.Printf("🔄 Recovered %d unfinished processing jobs", recovered)
+	}
+	for _, resumeID := range failed {
+		log.Printf("❌ Processing of resume %s hung after %d attempts", resumeID, s.cfg.MaxAttempts)
+		change := StatusChange{To: models.ResumeStatusError, Actor: models.ResumeActorProcessing, Reason: "processing did not finish within the job timeout"}
+		if err := s.resumes.ChangeStatus(ctx, resumeID, change); err != nil {
+			log.Printf("Failed to update resume status to error: %v", err)
+		}
+	}
+}
+
+// runJob выполняет задание. Контекст не наследуется от Run, чтобы при остановке
+// сервиса начатая обработка дошла до конца.
+func (s *resumeProcessingService) runJob(job *models.ResumeProcessingJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.JobTimeout)
+	defer cancel()
+
+	// Паника повторится на том же файле, поэтому задание сразу помечается failed
+	defer func() {
+		if r := recover(); r != nil {
+			s.failJob(job, fmt.Errorf("panic during processing: %v", r))
+		}
+	}()
+
+	err := s.resumes.ProcessResume(ctx, job.ResumeID)
+	if err == nil {
+		markCtx, markCancel := context.WithTimeout(context.Background(), processingMarkTimeout)
+		defer markCancel()
+		done, err := s.jobs.MarkDone(markCtx, job)
+		if err != nil {
+			log.Printf("❌ Failed to complete processing job %s: %v", job.ID, err)
+		} else if !done {
+			log.Printf("⚠️ Processing job %s was recovered by another worker, result not recorded", job.ID)
+		}
+		return
+	}
+
+	var permanent *permanentFailure
+	if errors.As(err, &permanent) || job.Attempts >= s.cfg.MaxAttempts {
+		s.failJob(job, err)
+		return
+	}
+
+	delay := retryDelay(job.Attempts, s.cfg.RetryDelay, s.cfg.MaxDelay)
+	log.Printf("⚠️ Processing of resume %s failed (attempt %d/%d), retry in %s: %v",
+		job.ResumeID, job.Attempts, s.cfg.MaxAttempts, delay, err)
+	markCtx, markCancel := context.WithTimeout(context.Background(), processingMarkTimeout)
+	defer markCancel()
+	if markErr := s.jobs.MarkRetry(markCtx, job, time.Now().Add(delay), err.Error()); markErr != nil {
+		log.Printf("❌ Failed to reschedule processing job %s: %v", job.ID, markErr)
+	}
+}
+
+// failJob помечает задание failed, а резюме - ошибкой обработки.
+// Если задание уже вернули в очередь, резюме не трогается: его обрабатывает другой воркер.
+func (s *resumeProcessingService) failJob(job *models.ResumeProcessingJob, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), processingMarkTimeout)
+	defer cancel()
+
+	log.Printf("❌ Processing of resume %s failed after %d attempts: %v", job.ResumeID, job.Attempts, err)
+	failed, markErr := s.jobs.MarkFailed(ctx, job, err.Error())
+	if markErr != nil {
+		log.Printf("❌ Failed to mark processing job %s as failed: %v", job.ID, markErr)
+	} else if !failed {
+		log.Printf("⚠️ Processing job %s was recovered by another worker, resume status left as is", job.ID)
+		return
+	}
+	change := StatusChange{To: models.ResumeStatusError, Actor: models.ResumeActorProcessing, Reason: err.Error()}
+	if updateErr := s.resumes.ChangeStatus(ctx, job.ResumeID, change); updateErr != nil {
+		log.Printf("Failed to update resume status to error: %v", updateErr)
+	}
+}
+
+// retryDelay - экспоненциальная задержка: base, 2*base, 4*base... но не больше max
+func retryDelay(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+func (s *resumeProcessingService) ListJobs(ctx context.Context, status string) ([]*models.ResumeProcessingJob, error) {
+	switch status {
+	case "", models.ProcessingJobPending, models.ProcessingJobRunning, models.ProcessingJobDone, models.ProcessingJobFailed:
+	default:
+		return nil, fmt.Errorf("unknown job status: %s", status)
+	}
+	return s.jobs.List(ctx, status)
+}
+
+func (s *resumeProcessingService) Requeue(ctx context.Context, resumeID string) error {
+	if _, err := s.resumeRepo.GetByID(ctx, resumeID); err != nil {
+		return fmt.Errorf("resume not found: %w", err)
+	}
+
+	if job, err := s.jobs.GetByResume(ctx, resumeID); err == nil && job.Status == models.ProcessingJobRunning {
+		return fmt.Errorf("resume is being processed right now")
+	}
+
+	change := StatusChange{To: models.ResumeStatusParsed, Actor: models.ResumeActorProcessing, Reason: "requeued for processing"}
+	if err := s.resumes.ChangeStatus(ctx, resumeID, change); err != nil {
+		return fmt.Errorf("failed to reset resume status: %w", err)
+	}
+	if err := s.jobs.Enqueue(ctx, resumeID); err != nil {
+		return fmt.Errorf("failed to enqueue resume: %w", err)
+	}
+
+	log.Printf("🔁 Resume %s re-enqueued for processing", resumeID)
+	return nil
+}
+
+func (s *resumeProcessingService) RequeueFailed(ctx context.Context) (int, error) {
+	jobs, err := s.jobs.List(ctx, models.ProcessingJobFailed)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, job := range jobs {
+		if err := s.Requeue(ctx, job.ResumeID); err != nil {
+			log.Printf("⚠️ Failed to re-enqueue resume %s: %v", job.ResumeID, err)
+			continue
+		}
+		requeued++
+	}
+	return requeued, nil
+}
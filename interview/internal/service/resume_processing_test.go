@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+func TestRetryDelay(t *testing.T) {
+	base, max := 10*time.Second, time.Minute
+
+	assert.Equal(t, 10*time.Second, retryDelay(1, base, max))
+	assert.Equal(t, 20*time.Second, retryDelay(2, base, max))
+	assert.Equal(t, 40*time.Second, retryDelay(3, base, max))
+	assert.Equal(t, time.Minute, retryDelay(4, base, max))
+	assert.Equal(t, time.Minute, retryDelay(30, base, max))
+}
+
+// fakeJobRepository запоминает, чем закончилось задание.
+// lost - задание уже вернули в очередь, и результат не записывается.
+type fakeJobRepository struct {
+	repository.ProcessingJobRepository
+	lost      bool
+	result    string
+	lastError string
+	nextRunAt time.Time
+	markErr   error // ошибка контекста в момент записи результата
+}
+
+func (r *fakeJobRepository) mark(ctx context.Context, result string) bool {
+	r.markErr = ctx.Err()
+	if r.lost {
+		return false
+	}
+	r.result = result
+	return true
+}
+
+func (r *fakeJobRepository) MarkDone(ctx context.Context, job *models.ResumeProcessingJob) (bool, error) {
+	return r.mark(ctx, models.ProcessingJobDone), nil
+}
+
+func (r *fakeJobRepository) MarkRetry(ctx context.Context, job *models.ResumeProcessingJob, nextRunAt time.Time, lastError string) error {
+	if r.mark(ctx, models.ProcessingJobPending) {
+		r.nextRunAt, r.lastError = nextRunAt, lastError
+	}
+	return nil
+}
+
+func (r *fakeJobRepository) MarkFailed(ctx context.Context, job *models.ResumeProcessingJob, lastError string) (bool, error) {
+	if !r.mark(ctx, models.ProcessingJobFailed) {
+		return false, nil
+	}
+	r.lastError = lastError
+	return true, nil
+}
+
+type fakeProcessor struct {
+	ResumeService
+	err    error
+	panic  bool
+	status models.ResumeStatus
+}
+
+func (p *fakeProcessor) ChangeStatus(ctx context.Context, id string, change StatusChange) error {
+	p.status = change.To
+	return nil
+}
+
+func (p *fakeProcessor) ProcessResume(ctx context.Context, id string) error {
+	if p.panic {
+		panic("nil map")
+	}
+	return p.err
+}
+
+func TestRunJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		panic      bool
+		attempts   int
+		wantResult string
+		wantStatus models.ResumeStatus
+	}{
+		{"успешная обработка", nil, false, 1, models.ProcessingJobDone, ""},
+		{"временная ошибка - повтор", errors.New("broker is down"), false, 2, models.ProcessingJobPending, ""},
+		{"попытки исчерпаны", errors.New("broker is down"), false, 3, models.ProcessingJobFailed, models.ResumeStatusError},
+		{"битый файл - без повтора", permanentError(errors.New("bad file")), false, 1, models.ProcessingJobFailed, models.ResumeStatusError},
+		{"паника - без повтора", nil, true, 1, models.ProcessingJobFailed, models.ResumeStatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := &fakeJobRepository{}
+			resumes := &fakeProcessor{err: tt.err, panic: tt.panic}
+			cfg := DefaultProcessingConfig
+			cfg.MaxAttempts = 3
+
+			svc := &resumeProcessingService{
+				jobs:    jobs,
+				resumes: resumes,
+				cfg:     cfg,
+			}
+			svc.runJob(&models.ResumeProcessingJob{ID: "job", ResumeID: "resume", Attempts: tt.attempts})
+
+			assert.Equal(t, tt.wantResult, jobs.result)
+			assert.Equal(t, tt.wantStatus, resumes.status)
+			if tt.wantResult == models.ProcessingJobPending {
+				assert.WithinDuration(t, time.Now().Add(2*cfg.RetryDelay), jobs.nextRunAt, time.Second)
+				assert.Equal(t, "broker is down", jobs.lastError)
+			}
+		})
+	}
+}
+
+func TestRunJob_RecordsResultAfterJobTimeout(t *testing.T) {
+	jobs := &fakeJobRepository{}
+	cfg := DefaultProcessingConfig
+	cfg.JobTimeout = time.Nanosecond
+	svc := &resumeProcessingService{
+		jobs:    jobs,
+		resumes: &fakeProcessor{err: permanentError(errors.New("context deadline exceeded"))},
+		cfg:     cfg,
+	}
+
+	svc.runJob(&models.ResumeProcessingJob{ID: "job", ResumeID: "resume", Attempts: 1})
+
+	assert.Equal(t, models.ProcessingJobFailed, jobs.result)
+	assert.NoError(t, jobs.markErr)
+}
+
+func TestRunJob_LostClaim(t *testing.T) {
+	resumes := &fakeProcessor{err: permanentError(errors.New("bad file"))}
+	svc := &resumeProcessingService{
+		jobs:    &fakeJobRepository{lost: true},
+		resumes: resumes,
+		cfg:     DefaultProcessingConfig,
+	}
+
+	svc.runJob(&models.ResumeProcessingJob{ID: "job", ResumeID: "resume", Attempts: 1})
+
+	assert.Empty(t, resumes.status, "резюме обрабатывает другой воркер")
+}
+
+func (r *fakeJobRepository) RecoverStale(ctx context.Context, lockedBefore time.Time, maxAttempts int) (int64, []string, error) {
+	return 1, []string{"hung"}, nil
+}
+
+func TestRecoverStaleFailsExhaustedJobs(t *testing.T) {
+	resumes := &fakeProcessor{}
+	svc := &resumeProcessingService{
+		jobs:    &fakeJobRepository{},
+		resumes: resumes,
+		cfg:     DefaultProcessingConfig,
+	}
+
+	svc.recoverStale(context.Background())
+
+	assert.Equal(t, models.ResumeStatusError, resumes.status)
+}
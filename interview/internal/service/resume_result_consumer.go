@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+	"gorm.io/gorm"
+
+	"interview/internal/broker"
+)
+
+const resultHandleTimeout = 30 * time.Second
+
+// ResumeResultConsumer читает результаты анализа резюме из очереди результатов
+// и сохраняет их через ResumeService. ML сервис больше не пишет в базу напрямую.
+// Если задан invitations, прошедших отбор кандидатов сразу приглашают на собеседование.
+type ResumeResultConsumer struct {
+	resumes     ResumeService
+	invitations InvitationService
+}
+
+func NewResumeResultConsumer(conn *broker.ConnectionManager, exchange, queue string, resumes ResumeService, invitations InvitationService) (*ResumeResultConsumer, error) {
+	c := &ResumeResultConsumer{resumes: resumes, invitations: invitations}
+
+	err := conn.DeclareTopology(func(ch *amqp.Channel) error {
+		if err := ch.ExchangeDeclare(exchange, "direct", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange: %w", err)
+		}
+		if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare results queue: %w", err)
+		}
+		if err := ch.QueueBind(queue, queue, exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind results queue: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conn.Consume(queue, func(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+		if err := ch.Qos(10, 0, false); err != nil {
+			return nil, err
+		}
+		return ch.Consume(queue, "", false, false, false, false, nil)
+	}, c.handle)
+
+	return c, nil
+}
+
+func (c *ResumeResultConsumer) handle(delivery amqp.Delivery) {
+	if err := c.apply(delivery.Body); err != nil {
+		var invalid *invalidResultError
+		if errors.As(err, &invalid) {
+			// Повторная доставка не исправит сообщение
+			log.Printf("❌ Dropping resume result: %v", err)
+			delivery.Nack(false, false)
+			return
+		}
+
+		log.Printf("⚠️ Failed to save resume result, will retry: %v", err)
+		time.Sleep(time.Second)
+		delivery.Nack(false, true)
+		return
+	}
+	delivery.Ack(false)
+}
+
+type invalidResultError struct {
+	err error
+}
+
+func (e *invalidResultError) Error() string { return e.err.Error() }
+func (e *invalidResultError) Unwrap() error { return e.err }
+
+// apply проверяет сообщение и сохраняет результат. Сообщения для удаленных резюме отбрасываются.
+func (c *ResumeResultConsumer) apply(body []byte) error {
+	result, err := broker.ParseResumeResult(body)
+	if err != nil {
+		return &invalidResultError{err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resultHandleTimeout)
+	defer cancel()
+
+	err = c.resumes.ApplyAnalysisResult(ctx, result)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &invalidResultError{err: fmt.Errorf("resume %s: %w", result.ResumeID, err)}
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.invitations != nil && result.Status == broker.ResumeResultAnalyzed {
+		// Результат уже сохранен: повторная доставка не поможет, HR пригласит кандидата вручную
+		if _, err := c.invitations.InviteIfPassed(ctx, result.ResumeID); err != nil {
+			log.Printf("❌ Failed to invite resume %s: %v", result.ResumeID, err)
+		}
+	}
+	return nil
+}
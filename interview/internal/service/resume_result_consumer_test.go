@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"interview/internal/broker"
+)
+
+type fakeResultSaver struct {
+	ResumeService
+	err   error
+	saved *broker.ResumeResultMessage
+}
+
+func (s *fakeResultSaver) ApplyAnalysisResult(ctx context.Context, result *broker.ResumeResultMessage) error {
+	s.saved = result
+	return s.err
+}
+
+const validResult = `{"schema_version":1,"resume_id":"5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f","status":"analyzed","analysis":{"score":87},"analyzed_at":"2025-09-01T10:00:00Z"}`
+
+func TestResumeResultConsumer_Apply(t *testing.T) {
+	t.Run("сохраняет корректный результат", func(t *testing.T) {
+		saver := &fakeResultSaver{}
+		err := (&ResumeResultConsumer{resumes: saver}).apply([]byte(validResult))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "5b0f7c3e-1d2a-4c5b-9e8f-0a1b2c3d4e5f", saver.saved.ResumeID)
+	})
+
+	t.Run("некорректное сообщение не сохраняется и не повторяется", func(t *testing.T) {
+		saver := &fakeResultSaver{}
+		err := (&ResumeResultConsumer{resumes: saver}).apply([]byte(`{"status":"analyzed"}`))
+
+		var invalid *invalidResultError
+		assert.True(t, errors.As(err, &invalid))
+		assert.Nil(t, saver.saved)
+	})
+
+	t.Run("резюме удалено - сообщение отбрасывается", func(t *testing.T) {
+		saver := &fakeResultSaver{err: fmt.Errorf("resume not found: %w", gorm.ErrRecordNotFound)}
+		err := (&ResumeResultConsumer{resumes: saver}).apply([]byte(validResult))
+
+		var invalid *invalidResultError
+		assert.True(t, errors.As(err, &invalid))
+	})
+
+	t.Run("ошибка базы - сообщение будет доставлено повторно", func(t *testing.T) {
+		saver := &fakeResultSaver{err: errors.New("connection refused")}
+		err := (&ResumeResultConsumer{resumes: saver}).apply([]byte(validResult))
+
+		var invalid *invalidResultError
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &invalid))
+	})
+}
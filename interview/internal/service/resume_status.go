@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+var (
+	ErrUnknownResumeStatus     = errors.New("unknown resume status")
+	ErrInvalidStatusTransition = errors.New("status transition is not allowed")
+)
+
+// resumeTransitions - разрешенные переходы между статусами резюме.
+// Из статусов, которых нет в таблице (старые произвольные значения), можно перейти в любой.
+var resumeTransitions = map[models.ResumeStatus][]models.ResumeStatus{
+	models.ResumeStatusPending: {
+		models.ResumeStatusParsed,
+		models.ResumeStatusError,
+	},
+	models.ResumeStatusParsed: {
+		models.ResumeStatusAnalyzed,
+		models.ResumeStatusError,
+		models.ResumeStatusInterviewing, // HR может пригласить, не дожидаясь анализа
+		models.ResumeStatusRejected,
+	},
+	models.ResumeStatusAnalyzed: {
+		models.ResumeStatusParsed, // повторная обработка
+		models.ResumeStatusInterviewing,
+		models.ResumeStatusAccepted,
+		models.ResumeStatusRejected,
+	},
+	models.ResumeStatusError: {
+		models.ResumeStatusParsed, // повторная обработка
+		models.ResumeStatusRejected,
+	},
+	models.ResumeStatusInterviewing: {
+		models.ResumeStatusInterviewed,
+		models.ResumeStatusAnalyzed, // собеседование отменено
+		models.ResumeStatusRejected,
+	},
+	models.ResumeStatusInterviewed: {
+		models.ResumeStatusInterviewing, // повторное собеседование
+		models.ResumeStatusAccepted,
+		models.ResumeStatusRejected,
+	},
+	models.ResumeStatusAccepted: {
+		models.ResumeStatusRejected, // кандидат отказался от предложения
+	},
+	models.ResumeStatusRejected: {
+		models.ResumeStatusAnalyzed, // HR вернул кандидата к рассмотрению
+	},
+}
+
+// actorTransitions сужает таблицу переходов для отдельных инициаторов.
+// Результат CV-review может прийти поздно, когда HR уже пригласил или отклонил кандидата,
+// поэтому он меняет статус только у разобранного резюме; в остальных случаях сохраняется лишь анализ.
+var actorTransitions = map[string]map[models.ResumeStatus][]models.ResumeStatus{
+	models.ResumeActorCVReview: {
+		models.ResumeStatusParsed: {models.ResumeStatusAnalyzed, models.ResumeStatusError},
+	},
+}
+
+// canTransition проверяет, разрешен ли переход from -> to для инициатора actor
+func canTransition(from, to models.ResumeStatus, actor string) bool {
+	if rules, restricted := actorTransitions[actor]; restricted {
+		return containsStatus(rules[from], to)
+	}
+
+	allowed, known := resumeTransitions[from]
+	if !known {
+		return true
+	}
+	return containsStatus(allowed, to)
+}
+
+func containsStatus(statuses []models.ResumeStatus, status models.ResumeStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange - запрос на смену статуса резюме
+type StatusChange struct {
+	To     models.ResumeStatus
+	Actor  string // models.ResumeActor* или "user:<id>"
+	Reason string
+	Result map[string]interface{} // результат анализа, сохраняется вместе со статусом
+}
+
+// UserActor - инициатор смены статуса для действий пользователя
+func UserActor(userID interface{}) string {
+	return fmt.Sprintf("user:%v", userID)
+}
+
+func (s *resumeService) ChangeStatus(ctx context.Context, id string, change StatusChange) error {
+	if !change.To.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownResumeStatus, change.To)
+	}
+
+	resume, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("resume not found: %w", err)
+	}
+
+	// Повторный перевод в тот же статус (например, повторная доставка результата) не пишется в историю
+	if resume.Status == change.To {
+		if change.Result != nil {
+			return s.repo.UpdateAnalysis(ctx, id, change.Result)
+		}
+		return nil
+	}
+
+	if !canTransition(resume.Status, change.To, change.Actor) {
+		return fmt.Errorf("%w: %q -> %q", ErrInvalidStatusTransition, resume.Status, change.To)
+	}
+
+	history := &models.ResumeStatusHistory{
+		ResumeID:   id,
+		FromStatus: resume.Status,
+		ToStatus:   change.To,
+		Actor:      change.Actor,
+		Reason:     change.Reason,
+	}
+	if err := s.repo.ChangeStatus(ctx, history, change.Result); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return fmt.Errorf("%w: %q -> %q", ErrInvalidStatusTransition, resume.Status, change.To)
+		}
+		return fmt.Errorf("failed to change resume status: %w", err)
+	}
+
+	log.Printf("🔀 Resume %s: %q -> %q by %s", id, resume.Status, change.To, change.Actor)
+	return nil
+}
+
+func (s *resumeService) GetStatusHistory(ctx context.Context, id string) ([]*models.ResumeStatusHistory, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("resume not found: %w", err)
+	}
+	return s.repo.GetStatusHistory(ctx, id)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/broker"
+	"interview/internal/models"
+	"interview/internal/repository"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  models.ResumeStatus
+		to    models.ResumeStatus
+		actor string
+		want  bool
+	}{
+		{"анализ после загрузки", models.ResumeStatusParsed, models.ResumeStatusAnalyzed, "", true},
+		{"ошибка обработки", models.ResumeStatusParsed, models.ResumeStatusError, "", true},
+		{"повторная обработка после ошибки", models.ResumeStatusError, models.ResumeStatusParsed, "", true},
+		{"приглашение на собеседование", models.ResumeStatusAnalyzed, models.ResumeStatusInterviewing, "", true},
+		{"собеседование завершено", models.ResumeStatusInterviewing, models.ResumeStatusInterviewed, "", true},
+		{"решение HR", models.ResumeStatusInterviewed, models.ResumeStatusAccepted, "", true},
+		{"нельзя принять без анализа", models.ResumeStatusError, models.ResumeStatusAccepted, "", false},
+		{"нельзя пропустить собеседование", models.ResumeStatusAnalyzed, models.ResumeStatusInterviewed, "", false},
+		{"нельзя вернуть собеседование в обработку", models.ResumeStatusInterviewing, models.ResumeStatusParsed, "", false},
+		{"старый произвольный статус", models.ResumeStatus("Отклонен HR"), models.ResumeStatusRejected, "", true},
+		{"результат анализа после разбора", models.ResumeStatusParsed, models.ResumeStatusAnalyzed, models.ResumeActorCVReview, true},
+		{"поздний анализ не отменяет собеседование", models.ResumeStatusInterviewing, models.ResumeStatusAnalyzed, models.ResumeActorCVReview, false},
+		{"поздний анализ не возвращает отклоненного", models.ResumeStatusRejected, models.ResumeStatusAnalyzed, models.ResumeActorCVReview, false},
+		{"HR возвращает отклоненного", models.ResumeStatusRejected, models.ResumeStatusAnalyzed, UserActor(uint(7)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, canTransition(tt.from, tt.to, tt.actor))
+		})
+	}
+}
+
+// fakeStatusRepository хранит статус одного резюме и записанные переходы
+type fakeStatusRepository struct {
+	repository.ResumeRepository
+	status  models.ResumeStatus
+	history []*models.ResumeStatusHistory
+	result  map[string]interface{}
+}
+
+func (r *fakeStatusRepository) GetByID(ctx context.Context, id string) (*models.Resume, error) {
+	return &models.Resume{ID: id, Status: r.status}, nil
+}
+
+func (r *fakeStatusRepository) ChangeStatus(ctx context.Context, change *models.ResumeStatusHistory, result map[string]interface{}) error {
+	if change.FromStatus != r.status {
+		return repository.ErrStatusChanged
+	}
+	r.status = change.ToStatus
+	r.history = append(r.history, change)
+	r.result = result
+	return nil
+}
+
+func (r *fakeStatusRepository) UpdateAnalysis(ctx context.Context, id string, result map[string]interface{}) error {
+	r.result = result
+	return nil
+}
+
+func TestChangeStatus(t *testing.T) {
+	t.Run("переход записывается в историю", func(t *testing.T) {
+		repo := &fakeStatusRepository{status: models.ResumeStatusAnalyzed}
+		svc := &resumeService{repo: repo}
+
+		err := svc.ChangeStatus(context.Background(), "resume", StatusChange{
+			To:     models.ResumeStatusRejected,
+			Actor:  UserActor(uint(7)),
+			Reason: "нет опыта с Go",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ResumeStatusRejected, repo.status)
+		require.Len(t, repo.history, 1)
+		assert.Equal(t, models.ResumeStatusAnalyzed, repo.history[0].FromStatus)
+		assert.Equal(t, "user:7", repo.history[0].Actor)
+		assert.Equal(t, "нет опыта с Go", repo.history[0].Reason)
+	})
+
+	t.Run("запрещенный переход", func(t *testing.T) {
+		repo := &fakeStatusRepository{status: models.ResumeStatusError}
+		svc := &resumeService{repo: repo}
+
+		err := svc.ChangeStatus(context.Background(), "resume", StatusChange{To: models.ResumeStatusAccepted})
+
+		assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+		assert.Equal(t, models.ResumeStatusError, repo.status)
+		assert.Empty(t, repo.history)
+	})
+
+	t.Run("неизвестный статус", func(t *testing.T) {
+		svc := &resumeService{repo: &fakeStatusRepository{status: models.ResumeStatusAnalyzed}}
+
+		err := svc.ChangeStatus(context.Background(), "resume", StatusChange{To: "hired"})
+
+		assert.ErrorIs(t, err, ErrUnknownResumeStatus)
+	})
+
+	t.Run("тот же статус - без записи в историю", func(t *testing.T) {
+		repo := &fakeStatusRepository{status: models.ResumeStatusAnalyzed}
+		svc := &resumeService{repo: repo}
+
+		err := svc.ChangeStatus(context.Background(), "resume", StatusChange{
+			To:     models.ResumeStatusAnalyzed,
+			Result: map[string]interface{}{"score": 80},
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, repo.history)
+		assert.Equal(t, 80, repo.result["score"])
+	})
+}
+
+func TestApplyAnalysisResult_LateResult(t *testing.T) {
+	repo := &fakeStatusRepository{status: models.ResumeStatusInterviewing}
+	svc := &resumeService{repo: repo}
+
+	err := svc.ApplyAnalysisResult(context.Background(), &broker.ResumeResultMessage{
+		ResumeID: "resume",
+		Status:   broker.ResumeResultAnalyzed,
+		Analysis: map[string]interface{}{"score": 80},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ResumeStatusInterviewing, repo.status, "кандидат остается на собеседовании")
+	assert.Empty(t, repo.history)
+	assert.Equal(t, 80, repo.result["score"])
+}
+
+func TestApplyAnalysisResult_LateFailureKeepsAnalysis(t *testing.T) {
+	analysis := map[string]interface{}{"score": 80}
+	repo := &fakeStatusRepository{status: models.ResumeStatusInterviewing, result: analysis}
+	svc := &resumeService{repo: repo}
+
+	err := svc.ApplyAnalysisResult(context.Background(), &broker.ResumeResultMessage{
+		ResumeID: "resume",
+		Status:   broker.ResumeResultFailed,
+		Error:    "model is unavailable",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ResumeStatusInterviewing, repo.status)
+	assert.Equal(t, analysis, repo.result, "прежний анализ и балл сохраняются")
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// rtfSkipDestinations - группы RTF, не содержащие текста документа
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true,
+	"pict": true, "object": true, "header": true, "footer": true,
+	"headerl": true, "headerr": true, "headerf": true, "footerl": true,
+	"footerr": true, "footerf": true, "listtable": true, "listoverridetable": true,
+	"revtbl": true, "rsidtbl": true, "xmlnstbl": true, "themedata": true,
+	"colorschememapping": true, "latentstyles": true, "datastore": true,
+	"generator": true, "fldinst": true, "filetbl": true, "pgdsctbl": true,
+}
+
+// rtfCodepages - кодовые страницы, используемые в \ansicpg
+var rtfCodepages = map[int]encoding.Encoding{
+	866:  charmap.CodePage866,
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1255: charmap.Windows1255,
+	1256: charmap.Windows1256,
+	1257: charmap.Windows1257,
+	1258: charmap.Windows1258,
+}
+
+// rtfState - состояние группы RTF
+type rtfState struct {
+	skip     bool
+	ucSkip   int
+	codepage encoding.Encoding
+}
+
+// extractFromRTF извлекает текст из RTF: раскрывает \'hh и \uN,
+// пропускает служебные группы (шрифты, стили, изображения, колонтитулы)
+func extractFromRTF(fileData []byte) (string, error) {
+	data := string(fileData)
+	if !strings.HasPrefix(data, "{\\rtf") {
+		return "", fmt.Errorf("не является RTF документом")
+	}
+
+	var out strings.Builder
+	var pending []byte // байты \'hh, ожидающие декодирования в кодовой странице
+
+	state := rtfState{ucSkip: 1, codepage: charmap.Windows1252}
+	var stack []rtfState
+	skipChars := 0
+
+	flushPending := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if decoded, err := state.codepage.NewDecoder().Bytes(pending); err == nil {
+			out.Write(decoded)
+		}
+		pending = pending[:0]
+	}
+
+	write := func(s string) {
+		flushPending()
+		if !state.skip {
+			out.WriteString(s)
+		}
+	}
+
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch c {
+		case '{':
+			flushPending()
+			stack = append(stack, state)
+			i++
+			// Группа вида {\*\dest ...} - необязательная служебная часть
+			if strings.HasPrefix(data[i:], "\\*") {
+				state.skip = true
+			}
+			continue
+		case '}':
+			flushPending()
+			if n := len(stack); n > 0 {
+				state = stack[n-1]
+				stack = stack[:n-1]
+			}
+			i++
+			continue
+		case '\r', '\n':
+			i++
+			continue
+		case '\\':
+		default:
+			if skipChars > 0 {
+				skipChars--
+			} else {
+				write(string(c))
+			}
+			i++
+			continue
+		}
+
+		// Управляющее слово или символ
+		i++
+		if i >= len(data) {
+			break
+		}
+		c = data[i]
+
+		if c == '\'' {
+			if i+2 < len(data) {
+				if v, err := strconv.ParseUint(data[i+1:i+3], 16, 8); err == nil {
+					if skipChars > 0 {
+						skipChars--
+					} else if !state.skip {
+						pending = append(pending, byte(v))
+					}
+				}
+			}
+			i += 3
+			continue
+		}
+
+		if !isASCIILetter(c) {
+			switch c {
+			case '\\', '{', '}':
+				write(string(c))
+			case '~':
+				write(" ")
+			case '-', '_':
+				// мягкий и неразрывный перенос
+			}
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(data) && isASCIILetter(data[i]) {
+			i++
+		}
+		word := data[start:i]
+
+		param, hasParam := 0, false
+		numStart := i
+		if i < len(data) && (data[i] == '-' || isASCIIDigit(data[i])) {
+			i++
+			for i < len(data) && isASCIIDigit(data[i]) {
+				i++
+			}
+			if v, err := strconv.Atoi(data[numStart:i]); err == nil {
+				param, hasParam = v, true
+			}
+		}
+		// Пробел после управляющего слова является его частью
+		if i < len(data) && data[i] == ' ' {
+			i++
+		}
+
+		switch {
+		case rtfSkipDestinations[word]:
+			state.skip = true
+		case word == "ansicpg" && hasParam:
+			if enc, ok := rtfCodepages[param]; ok {
+				flushPending()
+				state.codepage = enc
+			}
+		case word == "uc" && hasParam:
+			state.ucSkip = param
+		case word == "u" && hasParam:
+			if param < 0 {
+				param += 65536
+			}
+			write(string(rune(param)))
+			skipChars = state.ucSkip
+		case word == "par" || word == "line" || word == "row" || word == "sect" || word == "page":
+			write("\n")
+		case word == "tab" || word == "cell":
+			write("\t")
+		case word == "emdash":
+			write("—")
+		case word == "endash":
+			write("–")
+		case word == "bullet":
+			write("•")
+		case word == "lquote" || word == "rquote":
+			write("'")
+		case word == "ldblquote" || word == "rdblquote":
+			write("\"")
+		}
+	}
+	flushPending()
+
+	text := normalizeExtractedText(out.String())
+	if text == "" {
+		return "", fmt.Errorf("документ не содержит текста")
+	}
+	return text, nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
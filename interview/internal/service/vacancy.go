@@ -6,8 +6,7 @@ import (
 	"fmt"
 	"gorm.io/datatypes"
 	"io"
-	"path/filepath"
-	"strings"
+	"log"
 	"time"
 
 	"interview/internal/models"
@@ -30,10 +29,23 @@ type VacancyService interface {
 type vacancyService struct {
 	repo    repository.VacancyRepository
 	storage *storage.S3Storage
+	parser  VacancyParserService
+	rescore RescoreService
 }
 
-func NewVacancyService(repo repository.VacancyRepository, storage *storage.S3Storage) VacancyService {
-	return &vacancyService{repo: repo, storage: storage}
+func NewVacancyService(repo repository.VacancyRepository, storage *storage.S3Storage, parser VacancyParserService, rescore RescoreService) VacancyService {
+	return &vacancyService{repo: repo, storage: storage, parser: parser, rescore: rescore}
+}
+
+// scheduleRescore ставит пересчет оценок кандидатов, если веса вакансии изменились.
+// Ошибка не отменяет обновление вакансии: пересчет можно запустить повторным сохранением.
+func (s *vacancyService) scheduleRescore(ctx context.Context, existing, updated *models.Vacancy) {
+	if s.rescore == nil || existing.Weights() == updated.Weights() {
+		return
+	}
+	if _, err := s.rescore.Schedule(ctx, updated.ID, existing.Weights(), updated.Weights()); err != nil {
+		log.Printf("❌ Failed to schedule rescore for vacancy %s: %v", updated.ID, err)
+	}
 }
 
 func (s *vacancyService) validateWeights(vacancy *models.Vacancy) error {
@@ -41,19 +53,24 @@ func (s *vacancyService) validateWeights(vacancy *models.Vacancy) error {
 	if total != 100 {
 		return fmt.Errorf("weights sum must equal 100, got: %d", total)
 	}
-	return nil
-}
-
-func (s *vacancyService) validateFileType(filename string) error {
-	allowedExts := []string{".pdf", ".doc", ".docx", ".txt"}
-	ext := strings.ToLower(filepath.Ext(filename))
-
-	for _, allowed := range allowedExts {
-		if ext == allowed {
-			return nil
+	if t := vacancy.InviteThreshold; t != nil && (*t < 0 || *t > 100) {
+		return fmt.Errorf("invite threshold must be between 0 and 100, got: %v", *t)
+	}
+	limits := []struct {
+		name  string
+		value *int
+		max   int
+	}{
+		{"interview max minutes", vacancy.InterviewMaxMinutes, MaxInterviewMinutes},
+		{"interview max questions", vacancy.InterviewMaxQuestions, MaxInterviewQuestions},
+		{"interview idle minutes", vacancy.InterviewIdleMinutes, MaxInterviewMinutes},
+	}
+	for _, limit := range limits {
+		if v := limit.value; v != nil && (*v < 1 || *v > limit.max) {
+			return fmt.Errorf("%s must be between 1 and %d, got: %d", limit.name, limit.max, *v)
 		}
 	}
-	return fmt.Errorf("unsupported file type: %s", ext)
+	return nil
 }
 
 func (s *vacancyService) CreateVacancy(ctx context.Context, vacancy *models.Vacancy, file io.Reader, filename string) error {
@@ -64,7 +81,7 @@ func (s *vacancyService) CreateVacancy(ctx context.Context, vacancy *models.Vaca
 	if file == nil {
 		return fmt.Errorf("file is required to create vacancy")
 	}
-	if err := s.validateFileType(filename); err != nil {
+	if err := validateFileType(filename); err != nil {
 		return err
 	}
 
@@ -76,7 +93,7 @@ func (s *vacancyService) CreateVacancy(ctx context.Context, vacancy *models.Vaca
 	}
 
 	// 3. Извлечение структурированных данных из файла
-	extractedData, err := ExtractVacancyFromS3Key(ctx, s.storage, storageKey)
+	extractedData, err := s.parser.ParseStoredFile(ctx, storageKey)
 	if err != nil {
 		// Важно: если парсинг не удался, удаляем загруженный файл, чтобы не хранить мусор
 		s.storage.DeleteFile(ctx, storageKey)
@@ -160,7 +177,12 @@ func (s *vacancyService) UpdateVacancy(ctx context.Context, vacancy *models.Vaca
 	now := time.Now()
 	vacancy.UpdatedAt = &now
 
-	return s.repo.Update(ctx, vacancy)
+	if err := s.repo.Update(ctx, vacancy); err != nil {
+		return err
+	}
+
+	s.scheduleRescore(ctx, existing, vacancy)
+	return nil
 }
 
 func (s *vacancyService) UpdateVacancyWithFile(ctx context.Context, vacancy *models.Vacancy, file io.Reader, filename string) error {
@@ -173,7 +195,7 @@ func (s *vacancyService) UpdateVacancyWithFile(ctx context.Context, vacancy *mod
 		return err
 	}
 
-	if err := s.validateFileType(filename); err != nil {
+	if err := validateFileType(filename); err != nil {
 		return err
 	}
 
@@ -195,6 +217,8 @@ func (s *vacancyService) UpdateVacancyWithFile(ctx context.Context, vacancy *mod
 		return fmt.Errorf("failed to update vacancy: %w", err)
 	}
 
+	s.scheduleRescore(ctx, existing, vacancy)
+
 	if oldStorageKey != "" {
 		go func() {
 			ctx := context.Background()
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"interview/internal/models"
+	"interview/internal/repository"
+	"interview/internal/storage"
+)
+
+// VacancyParserService управляет профилями шаблонов вакансий и разбирает файлы вакансий по ним
+type VacancyParserService interface {
+	ListProfiles(ctx context.Context) ([]*models.VacancyParserProfile, error)
+	GetProfile(ctx context.Context, id string) (*models.VacancyParserProfile, error)
+	CreateProfile(ctx context.Context, profile *models.VacancyParserProfile) error
+	UpdateProfile(ctx context.Context, profile *models.VacancyParserProfile) error
+	DeleteProfile(ctx context.Context, id string) error
+
+	// PreviewFile разбирает файл без сохранения вакансии. Если profileID пуст, выбирается лучший профиль.
+	PreviewFile(ctx context.Context, file io.Reader, filename, profileID string) (*VacancyParseResult, error)
+	// ParseStoredFile разбирает уже загруженный в S3 файл вакансии
+	ParseStoredFile(ctx context.Context, storageKey string) (*Job, error)
+}
+
+type vacancyParserService struct {
+	repo    repository.VacancyParserProfileRepository
+	storage *storage.S3Storage
+}
+
+func NewVacancyParserService(repo repository.VacancyParserProfileRepository, storage *storage.S3Storage) VacancyParserService {
+	return &vacancyParserService{repo: repo, storage: storage}
+}
+
+func (s *vacancyParserService) ListProfiles(ctx context.Context) ([]*models.VacancyParserProfile, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *vacancyParserService) GetProfile(ctx context.Context, id string) (*models.VacancyParserProfile, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *vacancyParserService) CreateProfile(ctx context.Context, profile *models.VacancyParserProfile) error {
+	if err := s.validateProfile(profile); err != nil {
+		return err
+	}
+
+	profile.CreatedAt = time.Now()
+	if err := s.repo.Create(ctx, profile); err != nil {
+		return fmt.Errorf("failed to create parser profile: %w", err)
+	}
+	return nil
+}
+
+func (s *vacancyParserService) UpdateProfile(ctx context.Context, profile *models.VacancyParserProfile) error {
+	existing, err := s.repo.GetByID(ctx, profile.ID)
+	if err != nil {
+		return fmt.Errorf("parser profile not found: %w", err)
+	}
+
+	if err := s.validateProfile(profile); err != nil {
+		return err
+	}
+
+	profile.CreatedAt = existing.CreatedAt
+	now := time.Now()
+	profile.UpdatedAt = &now
+
+	return s.repo.Update(ctx, profile)
+}
+
+func (s *vacancyParserService) DeleteProfile(ctx context.Context, id string) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return fmt.Errorf("parser profile not found: %w", err)
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// validateProfile проверяет имя, раскладку, порог и заголовки профиля
+func (s *vacancyParserService) validateProfile(profile *models.VacancyParserProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if profile.Name == defaultVacancyTemplateName {
+		return fmt.Errorf("profile name %q is reserved for the built-in template", defaultVacancyTemplateName)
+	}
+	if profile.Layout == "" {
+		profile.Layout = models.VacancyLayoutAuto
+	}
+	if profile.FuzzyThreshold == 0 {
+		profile.FuzzyThreshold = defaultFuzzyThreshold
+	}
+
+	if _, err := compileVacancyTemplate(profile); err != nil {
+		return fmt.Errorf("invalid parser profile: %w", err)
+	}
+	return nil
+}
+
+func (s *vacancyParserService) PreviewFile(ctx context.Context, file io.Reader, filename, profileID string) (*VacancyParseResult, error) {
+	if file == nil {
+		return nil, fmt.Errorf("file is required")
+	}
+	if err := validateFileType(filename); err != nil {
+		return nil, err
+	}
+
+	fileData, err := io.ReadAll(io.LimitReader(file, MaxVacancyFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(fileData) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	var templates []*vacancyTemplate
+	if profileID != "" {
+		profile, err := s.repo.GetByID(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("parser profile not found: %w", err)
+		}
+		tmpl, err := compileVacancyTemplate(profile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parser profile: %w", err)
+		}
+		templates = []*vacancyTemplate{tmpl}
+	} else {
+		templates = s.activeTemplates(ctx)
+	}
+
+	return s.parse(fileData, filename, templates)
+}
+
+func (s *vacancyParserService) ParseStoredFile(ctx context.Context, storageKey string) (*Job, error) {
+	reader, err := s.storage.DownloadFile(ctx, storageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from S3: %w", err)
+	}
+	defer reader.Close()
+
+	fileData, err := io.ReadAll(io.LimitReader(reader, MaxVacancyFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	result, err := s.parse(fileData, storageKey, s.activeTemplates(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("📋 Vacancy file %s parsed with profile %q (%s): %d fields",
+		storageKey, result.Profile, result.Strategy, len(result.MatchedFields))
+	return result.Job, nil
+}
+
+func (s *vacancyParserService) parse(fileData []byte, filename string, templates []*vacancyTemplate) (*VacancyParseResult, error) {
+	text, err := extractVacancyText(fileData, filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseVacancyText(text, templates), nil
+}
+
+// activeTemplates возвращает включенные профили из БД и встроенный шаблон последним.
+// Ошибки БД и некорректные профили не мешают разбору встроенным шаблоном.
+func (s *vacancyParserService) activeTemplates(ctx context.Context) []*vacancyTemplate {
+	var templates []*vacancyTemplate
+
+	profiles, err := s.repo.GetActive(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load vacancy parser profiles, using built-in template: %v", err)
+	}
+	for _, profile := range profiles {
+		tmpl, err := compileVacancyTemplate(profile)
+		if err != nil {
+			log.Printf("⚠️ Skipping invalid vacancy parser profile %s: %v", profile.Name, err)
+			continue
+		}
+		templates = append(templates, tmpl)
+	}
+
+	return append(templates, defaultVacancyTemplate)
+}
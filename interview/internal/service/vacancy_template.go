@@ -0,0 +1,556 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"interview/internal/models"
+)
+
+const (
+	// defaultVacancyTemplateName - встроенный профиль исходного DOCX шаблона вакансии
+	defaultVacancyTemplateName = "default"
+	defaultFuzzyThreshold      = 0.85
+
+	// minTemplateFields - сколько полей должен распознать шаблон, чтобы не переходить к разбору свободного текста
+	minTemplateFields = 3
+
+	VacancyStrategyTemplate = "template"
+	VacancyStrategyFreeText = "free_text"
+)
+
+// VacancyParseResult - результат разбора вакансии, возвращается в предпросмотре
+type VacancyParseResult struct {
+	Job           *Job              `json:"job"`
+	Profile       string            `json:"profile"`
+	Strategy      string            `json:"strategy"`
+	Headings      map[string]string `json:"headings"` // поле -> заголовок, найденный в документе
+	MatchedFields []string          `json:"matched_fields"`
+	MissingFields []string          `json:"missing_fields"`
+}
+
+// vacancyField - поле Job с его json-именем
+type vacancyField struct {
+	Key   string
+	Value *string
+}
+
+// vacancyJobFields возвращает поля Job в порядке объявления структуры
+func vacancyJobFields(job *Job) []vacancyField {
+	return []vacancyField{
+		{"status", &job.Статус},
+		{"title", &job.Название},
+		{"region", &job.Регион},
+		{"city", &job.Город},
+		{"address", &job.Адрес},
+		{"employment_type", &job.ТипТрудового},
+		{"work_type", &job.ТипЗанятости},
+		{"schedule", &job.График},
+		{"income", &job.Доход},
+		{"salary_max", &job.ОкладМакс},
+		{"salary_min", &job.ОкладМин},
+		{"annual_bonus", &job.ГодоваяПремия},
+		{"bonus_type", &job.ТипПремирования},
+		{"responsibilities", &job.Обязанности},
+		{"requirements", &job.Требования},
+		{"education", &job.Образование},
+		{"experience", &job.Опыт},
+		{"software_skills", &job.ЗнаниеПрограмм},
+		{"computer_skills", &job.НавыкиКомпьютера},
+		{"languages", &job.ИностранныеЯзыки},
+		{"language_level", &job.УровеньЯзыка},
+		{"business_trips", &job.Командировки},
+		{"additional_info", &job.ДопИнформация},
+	}
+}
+
+// vacancyFieldValue возвращает указатель на поле Job по json-имени
+func vacancyFieldValue(job *Job, key string) *string {
+	for _, field := range vacancyJobFields(job) {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// defaultVacancyAliases - заголовки исходного DOCX шаблона вакансии
+var defaultVacancyAliases = map[string][]string{
+	"status":           {"Статус"},
+	"title":            {"Название"},
+	"region":           {"Регион"},
+	"city":             {"Город"},
+	"address":          {"Адрес"},
+	"employment_type":  {"Тип трудового"},
+	"work_type":        {"Тип занятости"},
+	"schedule":         {"Текст график работы"},
+	"income":           {"Доход (руб/мес)"},
+	"salary_max":       {"Оклад макс. (руб/мес)"},
+	"salary_min":       {"Оклад мин. (руб/мес)"},
+	"annual_bonus":     {"Годовая премия (%)"},
+	"bonus_type":       {"Тип премирования. Описание"},
+	"responsibilities": {"Обязанности (для публикации)"},
+	"requirements":     {"Требования (для публикации)"},
+	"education":        {"Уровень образования"},
+	"experience":       {"Требуемый опыт работы"},
+	"software_skills":  {"Знание специальных программ"},
+	"computer_skills":  {"Навыки работы на компьютере"},
+	"languages":        {"Знание иностранных языков"},
+	"language_level":   {"Уровень владения языка"},
+	"business_trips":   {"Наличие командировок"},
+	"additional_info":  {"Дополнительная информация"},
+}
+
+// freeTextVacancyAliases - типичные заголовки вакансий, написанных в свободной форме
+var freeTextVacancyAliases = map[string][]string{
+	"title":            {"Должность", "Вакансия", "Позиция", "Название вакансии", "Название"},
+	"city":             {"Город", "Местоположение", "Локация"},
+	"address":          {"Адрес", "Адрес офиса"},
+	"income":           {"Зарплата", "Заработная плата", "Доход", "Оклад", "Вознаграждение"},
+	"schedule":         {"График", "График работы"},
+	"work_type":        {"Занятость", "Тип занятости"},
+	"responsibilities": {"Обязанности", "Задачи", "Что нужно делать", "Чем предстоит заниматься", "Функционал"},
+	"requirements":     {"Требования", "Мы ждем", "Ожидаем от вас", "Требования к кандидату"},
+	"education":        {"Образование"},
+	"experience":       {"Опыт", "Опыт работы", "Требуемый опыт"},
+	"languages":        {"Иностранные языки", "Знание языков"},
+	"business_trips":   {"Командировки"},
+	"additional_info":  {"Условия", "Мы предлагаем", "Что мы предлагаем", "Описание", "О компании"},
+}
+
+var (
+	defaultVacancyTemplate  = mustVacancyTemplate(defaultVacancyTemplateName, models.VacancyLayoutAuto, defaultFuzzyThreshold, defaultVacancyAliases)
+	freeTextVacancyTemplate = mustVacancyTemplate("free_text", models.VacancyLayoutAuto, defaultFuzzyThreshold, freeTextVacancyAliases)
+
+	vacancyMoneyRe      = regexp.MustCompile(`(?i)(?:^|[^\p{L}\d])(?:(от|до)\s*)?(\d{1,3}(?:[\s\x{00a0},.]\d{3})+|\d{4,})`)
+	vacancyExperienceRe = regexp.MustCompile(`(?i)(?:от|не менее|более)\s+\d+(?:\s*[-–—]\s*\d+)?\s*(?:года|год|лет)`)
+)
+
+var vacancyScheduleKeywords = []string{
+	"полный день", "гибкий график", "сменный график", "вахтовый метод",
+	"удаленная работа", "удаленка", "гибрид", "5/2", "2/2",
+}
+
+var vacancyWorkTypeKeywords = []string{
+	"полная занятость", "частичная занятость", "проектная работа", "стажировка",
+}
+
+// vacancyTemplate - скомпилированный профиль разбора
+type vacancyTemplate struct {
+	name      string
+	layout    string
+	threshold float64
+	aliases   map[string]string // нормализованный заголовок -> поле
+	maxLen    int               // длина самого длинного заголовка в символах
+}
+
+func mustVacancyTemplate(name, layout string, threshold float64, aliases map[string][]string) *vacancyTemplate {
+	tmpl, err := newVacancyTemplate(name, layout, threshold, aliases)
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
+}
+
+// compileVacancyTemplate проверяет профиль из БД и готовит его к разбору
+func compileVacancyTemplate(profile *models.VacancyParserProfile) (*vacancyTemplate, error) {
+	var aliases map[string][]string
+	if err := json.Unmarshal(profile.Aliases, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid aliases: %w", err)
+	}
+	return newVacancyTemplate(profile.Name, profile.Layout, profile.FuzzyThreshold, aliases)
+}
+
+func newVacancyTemplate(name, layout string, threshold float64, aliases map[string][]string) (*vacancyTemplate, error) {
+	switch layout {
+	case "":
+		layout = models.VacancyLayoutAuto
+	case models.VacancyLayoutAuto, models.VacancyLayoutLines, models.VacancyLayoutTable:
+	default:
+		return nil, fmt.Errorf("unknown layout %q, expected auto, lines or table", layout)
+	}
+	if threshold == 0 {
+		threshold = defaultFuzzyThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("fuzzy_threshold must be between 0 and 1, got: %v", threshold)
+	}
+	if len(aliases) == 0 {
+		return nil, fmt.Errorf("aliases must not be empty")
+	}
+
+	tmpl := &vacancyTemplate{
+		name:      name,
+		layout:    layout,
+		threshold: threshold,
+		aliases:   make(map[string]string),
+	}
+	probe := &Job{}
+	for field, headings := range aliases {
+		if vacancyFieldValue(probe, field) == nil {
+			return nil, fmt.Errorf("unknown vacancy field %q", field)
+		}
+		for _, heading := range headings {
+			norm := normalizeVacancyHeading(heading)
+			if norm == "" {
+				return nil, fmt.Errorf("empty heading for field %q", field)
+			}
+			if other, ok := tmpl.aliases[norm]; ok && other != field {
+				return nil, fmt.Errorf("heading %q is used for both %q and %q", heading, other, field)
+			}
+			tmpl.aliases[norm] = field
+			if n := utf8.RuneCountInString(norm); n > tmpl.maxLen {
+				tmpl.maxLen = n
+			}
+		}
+	}
+	return tmpl, nil
+}
+
+// normalizeVacancyHeading оставляет только буквы и цифры в нижнем регистре,
+// чтобы "Оклад макс. (руб/мес)" и "оклад макс (руб./мес.)" совпадали
+func normalizeVacancyHeading(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// matchHeading возвращает поле, заголовком которого является строка:
+// точное совпадение после нормализации или похожее не ниже порога
+func (t *vacancyTemplate) matchHeading(line string) (string, bool) {
+	norm := normalizeVacancyHeading(line)
+	if norm == "" {
+		return "", false
+	}
+	if field, ok := t.aliases[norm]; ok {
+		return field, true
+	}
+
+	n := utf8.RuneCountInString(norm)
+	if n > t.maxLen+t.maxLen/3+2 {
+		return "", false
+	}
+
+	bestField, bestAlias, bestScore := "", "", 0.0
+	for alias, field := range t.aliases {
+		if score := headingSimilarity(norm, alias); score > bestScore || (score == bestScore && alias < bestAlias) {
+			bestField, bestAlias, bestScore = field, alias, score
+		}
+	}
+	if bestScore >= t.threshold {
+		return bestField, true
+	}
+	return "", false
+}
+
+// headingSimilarity - 1 минус расстояние Левенштейна, деленное на длину большей строки
+func headingSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// vacancyTemplateMatch - результат применения одного шаблона
+type vacancyTemplateMatch struct {
+	job      *Job
+	headings map[string]string
+}
+
+// count возвращает число заполненных полей
+func (m *vacancyTemplateMatch) count() int {
+	n := 0
+	for _, field := range vacancyJobFields(m.job) {
+		if *field.Value != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// apply раскладывает строки по полям: значение поля - все строки до следующего заголовка.
+// Строка таблицы "заголовок<TAB>значение" и строка "Заголовок: значение" заполняют поле сразу.
+// Значение из строки таблицы или "Заголовок: значение" не перезаписывает уже заполненное поле.
+func (t *vacancyTemplate) apply(lines []string) *vacancyTemplateMatch {
+	match := &vacancyTemplateMatch{job: &Job{}, headings: make(map[string]string)}
+
+	var currentField string
+	var buffer []string
+
+	assign := func(field, heading, value string) {
+		if _, seen := match.headings[field]; !seen {
+			match.headings[field] = heading
+		}
+		if ptr := vacancyFieldValue(match.job, field); ptr != nil && *ptr == "" {
+			*ptr = value
+		}
+	}
+
+	// Повторный раздел с тем же полем ("Описание" и "Условия") дописывается к значению
+	flush := func() {
+		if currentField != "" && len(buffer) > 0 {
+			value := strings.Join(buffer, " ")
+			if ptr := vacancyFieldValue(match.job, currentField); *ptr != "" {
+				*ptr += " " + value
+			} else {
+				*ptr = value
+			}
+		}
+		currentField, buffer = "", nil
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "\t") {
+			cells := splitTableCells(line)
+			if t.layout != models.VacancyLayoutLines && len(cells) >= 2 {
+				if field, ok := t.matchHeading(cells[0]); ok {
+					flush()
+					assign(field, cells[0], strings.Join(cells[1:], " "))
+					continue
+				}
+			}
+			line = strings.Join(cells, " ")
+			if line == "" {
+				continue
+			}
+		} else if t.layout != models.VacancyLayoutTable {
+			if field, ok := t.matchHeading(line); ok {
+				flush()
+				currentField = field
+				if _, seen := match.headings[field]; !seen {
+					match.headings[field] = line
+				}
+				continue
+			}
+			if idx := strings.Index(line, ":"); idx > 0 {
+				if field, ok := t.matchHeading(line[:idx]); ok {
+					// "Зарплата: 100 000 руб" внутри раздела заполняет свое поле,
+					// но остается и в тексте текущего раздела
+					assign(field, strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]))
+					if currentField == "" || currentField == field {
+						continue
+					}
+				}
+			}
+		}
+
+		if currentField != "" {
+			buffer = append(buffer, line)
+		}
+	}
+	flush()
+
+	return match
+}
+
+func splitTableCells(line string) []string {
+	var cells []string
+	for _, cell := range strings.Split(line, "\t") {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// parseVacancyText выбирает шаблон, распознавший больше всего полей. Шаблоны идут
+// в порядке приоритета, при равенстве побеждает более приоритетный. Если ни один шаблон
+// не распознал minTemplateFields полей, недостающие поля ищутся в свободном тексте.
+func parseVacancyText(text string, templates []*vacancyTemplate) *VacancyParseResult {
+	lines := strings.Split(strings.ReplaceAll(text, "\u00a0", " "), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	var best *vacancyTemplateMatch
+	bestName := ""
+	for _, tmpl := range templates {
+		match := tmpl.apply(lines)
+		if best == nil || match.count() > best.count() {
+			best, bestName = match, tmpl.name
+		}
+	}
+	if best == nil {
+		best = &vacancyTemplateMatch{job: &Job{}, headings: make(map[string]string)}
+	}
+
+	result := &VacancyParseResult{
+		Job:      best.job,
+		Profile:  bestName,
+		Strategy: VacancyStrategyTemplate,
+		Headings: best.headings,
+	}
+
+	if best.count() < minTemplateFields {
+		result.Strategy = VacancyStrategyFreeText
+		fillFromFreeText(result, lines)
+	}
+
+	for _, field := range vacancyJobFields(result.Job) {
+		if *field.Value != "" {
+			result.MatchedFields = append(result.MatchedFields, field.Key)
+		} else {
+			result.MissingFields = append(result.MissingFields, field.Key)
+		}
+	}
+	return result
+}
+
+// fillFromFreeText дополняет пустые поля по типичным заголовкам и эвристикам:
+// название - первая строка, зарплата, город, график, занятость и опыт - по ключевым словам
+func fillFromFreeText(result *VacancyParseResult, lines []string) {
+	job := result.Job
+
+	freeText := freeTextVacancyTemplate.apply(lines)
+	for _, field := range vacancyJobFields(freeText.job) {
+		if target := vacancyFieldValue(job, field.Key); *target == "" && *field.Value != "" {
+			*target = *field.Value
+			if _, ok := result.Headings[field.Key]; !ok {
+				result.Headings[field.Key] = freeText.headings[field.Key]
+			}
+		}
+	}
+
+	var nonEmpty []string
+	for _, line := range lines {
+		if line != "" {
+			nonEmpty = append(nonEmpty, strings.Join(splitTableCells(line), " "))
+		}
+	}
+
+	if job.Название == "" && len(nonEmpty) > 0 {
+		if _, isHeading := freeTextVacancyTemplate.matchHeading(nonEmpty[0]); !isHeading &&
+			utf8.RuneCountInString(nonEmpty[0]) <= 120 {
+			job.Название = nonEmpty[0]
+		}
+	}
+
+	if job.Город == "" {
+		job.Город = findCity(nonEmpty)
+	}
+
+	fillSalary(job, nonEmpty)
+
+	lowerText := strings.ReplaceAll(strings.ToLower(strings.Join(nonEmpty, "\n")), "ё", "е")
+	if job.График == "" {
+		job.График = strings.Join(findKeywords(lowerText, vacancyScheduleKeywords), ", ")
+	}
+	if job.ТипЗанятости == "" {
+		job.ТипЗанятости = strings.Join(findKeywords(lowerText, vacancyWorkTypeKeywords), ", ")
+	}
+	if job.Опыт == "" {
+		if strings.Contains(lowerText, "без опыта") {
+			job.Опыт = "без опыта"
+		} else if m := vacancyExperienceRe.FindString(lowerText); m != "" {
+			job.Опыт = m
+		}
+	}
+}
+
+// fillSalary ищет вилку "от ... до ... руб" в поле дохода или в строках с упоминанием денег
+func fillSalary(job *Job, lines []string) {
+	source := job.Доход
+	if source == "" {
+		for _, line := range lines {
+			lower := strings.ToLower(line)
+			if strings.Contains(lower, "руб") || strings.Contains(lower, "₽") ||
+				strings.Contains(lower, "зарплат") || strings.Contains(lower, "оклад") {
+				if vacancyMoneyRe.MatchString(line) {
+					source = line
+					break
+				}
+			}
+		}
+	}
+	if source == "" {
+		return
+	}
+	if job.Доход == "" {
+		job.Доход = source
+	}
+
+	var unlabeled []string
+	for _, m := range vacancyMoneyRe.FindAllStringSubmatch(source, -1) {
+		amount := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, m[2])
+		if v, err := strconv.Atoi(amount); err != nil || v < 1000 {
+			continue
+		}
+
+		switch strings.ToLower(m[1]) {
+		case "от":
+			if job.ОкладМин == "" {
+				job.ОкладМин = amount
+			}
+		case "до":
+			if job.ОкладМакс == "" {
+				job.ОкладМакс = amount
+			}
+		default:
+			unlabeled = append(unlabeled, amount)
+		}
+	}
+
+	// "200 000 - 300 000 руб": два числа без подписей - вилка
+	if len(unlabeled) >= 2 {
+		if job.ОкладМин == "" {
+			job.ОкладМин = unlabeled[0]
+		}
+		if job.ОкладМакс == "" {
+			job.ОкладМакс = unlabeled[1]
+		}
+	}
+}
+
+func findKeywords(text string, keywords []string) []string {
+	var found []string
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			found = append(found, keyword)
+		}
+	}
+	return found
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"interview/internal/models"
+)
+
+func TestParseVacancyText_DefaultTemplateFuzzyHeadings(t *testing.T) {
+	// Заголовки исходного шаблона с лишними пробелами, другой пунктуацией и опечаткой
+	text := `Название
+Менеджер по продажам
+Текст  график  работы
+5/2, с 9 до 18
+Оклад макс (руб./мес.)
+90000
+Обязаности (для публикации)
+Работа с клиентами
+Ведение CRM
+Неизвестный заголовок`
+
+	result := parseVacancyText(text, []*vacancyTemplate{defaultVacancyTemplate})
+
+	assert.Equal(t, defaultVacancyTemplateName, result.Profile)
+	assert.Equal(t, VacancyStrategyTemplate, result.Strategy)
+	assert.Equal(t, "Менеджер по продажам", result.Job.Название)
+	assert.Equal(t, "5/2, с 9 до 18", result.Job.График)
+	assert.Equal(t, "90000", result.Job.ОкладМакс)
+	assert.Equal(t, "Работа с клиентами Ведение CRM Неизвестный заголовок", result.Job.Обязанности)
+	assert.Equal(t, "Обязаности (для публикации)", result.Headings["responsibilities"])
+	assert.Equal(t, []string{"title", "schedule", "salary_max", "responsibilities"}, result.MatchedFields)
+	assert.Contains(t, result.MissingFields, "city")
+}
+
+func TestParseVacancyText_TableLayoutFromDOCX(t *testing.T) {
+	text, err := extractVacancyText(readFixture(t, "vacancy_table.docx"), "vacancy_table.docx")
+	require.NoError(t, err)
+
+	result := parseVacancyText(text, []*vacancyTemplate{defaultVacancyTemplate})
+
+	assert.Equal(t, VacancyStrategyTemplate, result.Strategy)
+	assert.Equal(t, "Инженер-программист", result.Job.Название)
+	assert.Equal(t, "Казань", result.Job.Город)
+	assert.Equal(t, "150000", result.Job.ОкладМакс)
+	assert.Equal(t, "120000", result.Job.ОкладМин)
+	assert.Equal(t, "Разработка сервисов Поддержка legacy", result.Job.Обязанности)
+}
+
+func TestParseVacancyText_CustomProfileWins(t *testing.T) {
+	custom, err := compileVacancyTemplate(&models.VacancyParserProfile{
+		Name:    "hh-export",
+		Layout:  models.VacancyLayoutLines,
+		Aliases: []byte(`{"title": ["Должность"], "requirements": ["Что мы ждем"], "additional_info": ["Что предлагаем"], "city": ["Офис"]}`),
+	})
+	require.NoError(t, err)
+
+	text := `Должность
+Аналитик данных
+Офис
+Москва
+Что мы ждем
+SQL, Python
+Что предлагаем
+ДМС`
+
+	result := parseVacancyText(text, []*vacancyTemplate{custom, defaultVacancyTemplate})
+
+	assert.Equal(t, "hh-export", result.Profile)
+	assert.Equal(t, VacancyStrategyTemplate, result.Strategy)
+	assert.Equal(t, "Аналитик данных", result.Job.Название)
+	assert.Equal(t, "Москва", result.Job.Город)
+	assert.Equal(t, "SQL, Python", result.Job.Требования)
+	assert.Equal(t, "ДМС", result.Job.ДопИнформация)
+}
+
+func TestParseVacancyText_FreeTextFallback(t *testing.T) {
+	text := `Позиция: Senior Backend Developer
+
+Описание:
+Мы ищем опытного Backend разработчика. Офис: г. Казань
+
+Требования:
+- Опыт работы с Go от 3 лет
+- Знание PostgreSQL, Redis
+
+Условия:
+- Зарплата: 200,000 - 300,000 руб
+- Полная удалёнка
+- Гибкий график, полная занятость
+
+Обязанности:
+- Разработка и поддержка микросервисов`
+
+	result := parseVacancyText(text, []*vacancyTemplate{defaultVacancyTemplate})
+
+	assert.Equal(t, VacancyStrategyFreeText, result.Strategy)
+	assert.Equal(t, "Senior Backend Developer", result.Job.Название)
+	assert.Equal(t, "- Опыт работы с Go от 3 лет - Знание PostgreSQL, Redis", result.Job.Требования)
+	assert.Equal(t, "- Разработка и поддержка микросервисов", result.Job.Обязанности)
+	assert.Contains(t, result.Job.ДопИнформация, "- Зарплата: 200,000 - 300,000 руб")
+	assert.Equal(t, "200,000 - 300,000 руб", result.Job.Доход)
+	assert.Equal(t, "200000", result.Job.ОкладМин)
+	assert.Equal(t, "300000", result.Job.ОкладМакс)
+	assert.Equal(t, "гибкий график, удаленка", result.Job.График)
+	assert.Equal(t, "полная занятость", result.Job.ТипЗанятости)
+	assert.Equal(t, "от 3 лет", result.Job.Опыт)
+}
+
+func TestFillSalary(t *testing.T) {
+	tests := []struct {
+		line     string
+		min, max string
+	}{
+		{"Зарплата от 100 000 до 150 000 руб.", "100000", "150000"},
+		{"Оклад: до 80000 ₽ на руки", "", "80000"},
+		{"Доход 120 000 руб после испытательного срока", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			job := &Job{}
+			fillSalary(job, []string{"Работаем с 2015 года", tt.line})
+			assert.Equal(t, tt.min, job.ОкладМин)
+			assert.Equal(t, tt.max, job.ОкладМакс)
+		})
+	}
+}
+
+func TestCompileVacancyTemplate_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile models.VacancyParserProfile
+		errText string
+	}{
+		{
+			name:    "неизвестное поле",
+			profile: models.VacancyParserProfile{Aliases: []byte(`{"salary": ["Оклад"]}`)},
+			errText: `unknown vacancy field "salary"`,
+		},
+		{
+			name:    "один заголовок для двух полей",
+			profile: models.VacancyParserProfile{Aliases: []byte(`{"salary_min": ["Оклад"], "salary_max": ["оклад."]}`)},
+			errText: "is used for both",
+		},
+		{
+			name:    "неизвестная раскладка",
+			profile: models.VacancyParserProfile{Layout: "columns", Aliases: []byte(`{"title": ["Название"]}`)},
+			errText: `unknown layout "columns"`,
+		},
+		{
+			name:    "порог вне диапазона",
+			profile: models.VacancyParserProfile{FuzzyThreshold: 1.5, Aliases: []byte(`{"title": ["Название"]}`)},
+			errText: "fuzzy_threshold must be between 0 and 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileVacancyTemplate(&tt.profile)
+			assert.ErrorContains(t, err, tt.errText)
+		})
+	}
+}
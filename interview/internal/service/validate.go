@@ -6,14 +6,66 @@ import (
 	"strings"
 )
 
+// fileFormat описывает поддерживаемый формат документа:
+// допустимые расширения и функцию извлечения текста
+type fileFormat struct {
+	Type       FileType
+	Name       string
+	Extensions []string
+	Extract    func(fileData []byte) (string, error)
+}
+
+// supportedFormats - единый реестр форматов для резюме и вакансий
+var supportedFormats = []fileFormat{
+	{Type: FileTypeDOCX, Name: "DOCX", Extensions: []string{".docx"}, Extract: extractFromDOCXFree},
+	{Type: FileTypeDOC, Name: "DOC", Extensions: []string{".doc"}, Extract: extractFromDOC},
+	{Type: FileTypePDF, Name: "PDF", Extensions: []string{".pdf"}, Extract: extractFromPDFFree},
+	{Type: FileTypeRTF, Name: "RTF", Extensions: []string{".rtf"}, Extract: extractFromRTF},
+	{Type: FileTypeODT, Name: "ODT", Extensions: []string{".odt"}, Extract: extractFromODT},
+	{Type: FileTypeTXT, Name: "TXT", Extensions: []string{".txt"}, Extract: extractFromTXT},
+}
+
+// lookupFormat возвращает описание формата по типу файла
+func lookupFormat(fileType FileType) (fileFormat, bool) {
+	for _, format := range supportedFormats {
+		if format.Type == fileType {
+			return format, true
+		}
+	}
+	return fileFormat{}, false
+}
+
+// supportedExtensions возвращает все допустимые расширения файлов
+func supportedExtensions() []string {
+	var exts []string
+	for _, format := range supportedFormats {
+		exts = append(exts, format.Extensions...)
+	}
+	return exts
+}
+
+// supportedFormatNames возвращает названия форматов для сообщений об ошибках
+func supportedFormatNames() string {
+	names := make([]string, 0, len(supportedFormats))
+	for _, format := range supportedFormats {
+		names = append(names, format.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 func validateFileType(filename string) error {
-	allowedExts := []string{".pdf", ".doc", ".docx", ".txt"}
+	if filename == "" {
+		return fmt.Errorf("filename cannot be empty")
+	}
+
 	ext := strings.ToLower(filepath.Ext(filename))
+	allowedExts := supportedExtensions()
 
 	for _, allowed := range allowedExts {
 		if ext == allowed {
 			return nil
 		}
 	}
-	return fmt.Errorf("unsupported file type: %s", ext)
+	return fmt.Errorf("unsupported file type: %s. Allowed types: %s",
+		ext, strings.Join(allowedExts, ", "))
 }
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFileType(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected FileType
+	}{
+		{"resume.docx", FileTypeDOCX},
+		{"resume.doc", FileTypeDOC},
+		{"resume.rtf", FileTypeRTF},
+		{"resume.odt", FileTypeODT},
+		{"two_columns.pdf", FileTypePDF},
+		{"workbook.xls", FileTypeUnknown},
+		{"archive.zip", FileTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data := readFixture(t, tt.fixture)
+			assert.Equal(t, tt.expected, detectFileType(data))
+		})
+	}
+
+	assert.Equal(t, FileTypeTXT, detectFileType([]byte("Обычный текст резюме")))
+}
+
+func TestExtractTextFromFile_Formats(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		expected string
+	}{
+		{
+			name:     "DOC с несжатым и сжатым фрагментами и гиперссылкой",
+			fixture:  "resume.doc",
+			expected: "Ivan Petrov\nEmail: ivan@example.com\nОпыт работы: 5 лет\nGo\tPostgreSQL",
+		},
+		{
+			name:     "RTF в cp1251 с \\u-символами и полем",
+			fixture:  "resume.rtf",
+			expected: "Иван Петров\nОпыт Go\t5 лет\nivan@example.com",
+		},
+		{
+			name:     "ODT с заголовком, таблицей и переносом строки",
+			fixture:  "resume.odt",
+			expected: "Мария Сидорова\nНавыки:  Python\tSQL\n2020\nЯндекс\nСтрока\nперенос",
+		},
+		{
+			name:     "DOCX",
+			fixture:  "resume.docx",
+			expected: "Пётр Смирнов",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readFixture(t, tt.fixture)
+
+			text, err := ExtractTextFromFile(data, tt.fixture)
+			require.NoError(t, err)
+			assert.Contains(t, text, tt.expected)
+		})
+	}
+}
+
+func TestExtractTextFromFile_Unsupported(t *testing.T) {
+	data := readFixture(t, "workbook.xls")
+
+	_, err := ExtractTextFromFile(data, "workbook.xls")
+	assert.EqualError(t, err, "неподдерживаемый формат файла: Unknown")
+}
+
+func TestFindPlcPcd_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		clx  []byte
+	}{
+		{"отрицательная длина Prc не зацикливает разбор", []byte{0x01, 0xFD, 0xFF, 0x02}},
+		{"отрицательная длина Prc не уводит индекс назад", []byte{0x01, 0xF0, 0xFF, 0x02}},
+		{"длина Prc за пределами таблицы", []byte{0x01, 0x10, 0x00, 0x02}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := findPlcPcd(tt.clx)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseODTContent_LimitsSpaces(t *testing.T) {
+	content := `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+		`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>` +
+		`<text:p>Иван<text:s text:c="2000000000"/>Петров</text:p></office:text></office:body></office:document-content>`
+
+	text, err := parseODTContent(strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, "Иван"+strings.Repeat(" ", odtMaxSpaces)+"Петров", strings.TrimSpace(text))
+}
+
+func TestExtractFromODT_RejectsZipBomb(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("content.xml")
+	require.NoError(t, err)
+	chunk := bytes.Repeat([]byte(" "), 1<<20)
+	for written := 0; written <= odtMaxContentSize; written += len(chunk) {
+		_, err := w.Write(chunk)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.Less(t, buf.Len(), MaxResumeFileSize, "архив проходит ограничение размера файла")
+
+	_, err = extractFromODT(buf.Bytes())
+	assert.ErrorContains(t, err, "content.xml слишком большой")
+}
+
+func TestValidateFileType(t *testing.T) {
+	for _, filename := range []string{"cv.pdf", "cv.DOC", "cv.docx", "cv.rtf", "cv.odt", "cv.txt"} {
+		assert.NoError(t, validateFileType(filename), filename)
+	}
+
+	assert.EqualError(t, validateFileType(""), "filename cannot be empty")
+	assert.ErrorContains(t, validateFileType("cv.xls"), "unsupported file type: .xls")
+}
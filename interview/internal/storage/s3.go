@@ -66,6 +66,7 @@ func (s *S3Storage) getContentTypeByExtension(filename string) string {
 		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
 		".txt":  "text/plain",
 		".rtf":  "application/rtf",
+		".odt":  "application/vnd.oasis.opendocument.text",
 		".jpg":  "image/jpeg",
 		".jpeg": "image/jpeg",
 		".png":  "image/png",
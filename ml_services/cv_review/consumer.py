@@ -3,11 +3,9 @@ import json
 import logging
 import os
 import time
-from datetime import datetime
+from datetime import datetime, timezone
 
 import pika
-import psycopg2
-import psycopg2.extras
 import requests
 from rich.console import Console
 from rich.json import JSON
@@ -19,37 +17,18 @@ RABBITMQ_HOST = os.getenv("RABBITMQ_HOST", "localhost")
 RABBITMQ_URL = f"amqp://guest:guest@{RABBITMQ_HOST}:5672/"
 CV_REVIEW_SERVICE = os.getenv("CV_REVIEW_SERVICE", "http://localhost:5030")
 
-# Настройки БД
-DB_HOST = os.getenv("DB_HOST", "localhost")
-DB_USER = os.getenv("DB_USER", "postgres")
-DB_PASSWORD = os.getenv("DB_PASSWORD", "postgres")
-DB_NAME = os.getenv("DB_NAME", "aihrservicedb")
-DB_PORT = os.getenv("DB_PORT", "5432")
-
 EXCHANGE_NAME = "resume_exchange"
 QUEUE_NAME = "resume_analysis_queue"
 ROUTING_KEY = "resume_analysis_queue"
 
+# Результаты анализа читает interview сервис (ResumeResultConsumer) и сам сохраняет их в БД
+RESULTS_QUEUE = os.getenv("RABBITMQ_RESULTS_QUEUE", "resume_results_queue")
+RESULT_SCHEMA_VERSION = 1
+
 console = Console()
 logger = logging.getLogger(__name__)
 
 
-def get_db_connection():
-    """Получение подключения к PostgreSQL"""
-    try:
-        connection = psycopg2.connect(
-            host=DB_HOST,
-            port=DB_PORT,
-            database=DB_NAME,
-            user=DB_USER,
-            password=DB_PASSWORD
-        )
-        return connection
-    except Exception as e:
-        console.print(f"💥 [bold red]Ошибка подключения к БД:[/bold red] {e}")
-        return None
-
-
 def extract_email_from_ai_response(ai_response):
     """
     Извлечение email из ответа ИИ
@@ -98,73 +77,43 @@ def extract_email_from_ai_response(ai_response):
         console.print(f"💥 [bold red]Ошибка извлечения email:[/bold red] {e}")
         return 'user_has_no_mail'
 
-def save_analysis_to_database(resume_id, ai_response, extracted_email):
+def build_result_message(resume_id, vacancy_id, analysis=None, email=None, error=None):
     """
-    Сохранение результатов анализа в БД
-    :param resume_id: ID резюме (UUID)
-    :param ai_response: Dict с полным ответом от ИИ
-    :param extracted_email: Найденный email или 'user_has_no_mail'
-    :return: True если успешно, False если ошибка
+    Сообщение с результатом анализа по схеме ResumeResultMessage (schema_version 1)
+    :param analysis: Dict с ответом ИИ, если анализ успешен
+    :param error: Текст ошибки, если анализ не удался
     """
-    connection = get_db_connection()
-    if not connection:
-        console.print(f"❌ [bold red]Не удалось подключиться к БД[/bold red]")
-        return False
-
-    try:
-        cursor = connection.cursor()
-
-        # Преобразуем весь ответ ИИ в JSON для сохранения
-        analysis_json = json.dumps(ai_response, ensure_ascii=False)
-
-        # Определяем значение email для БД
-        email_for_db = None if extracted_email == 'user_has_no_mail' else extracted_email
-
-        console.print(f"💾 [bold cyan]Сохранение в БД:[/bold cyan]")
-        console.print(f"📋 Resume ID: {resume_id}")
-        console.print(f"📧 Email для БД: {email_for_db}")
-        console.print(f"📊 Размер анализа: {len(analysis_json)} символов")
-
-        # SQL запрос для обновления резюме
-        update_query = """
-            UPDATE resumes 
-            SET 
-                resume_analysis_jsonb = %s::jsonb,
-                mail = %s,
-                status = 'analyzed'
-            WHERE id = %s
-        """
-
-        cursor.execute(update_query, (analysis_json, email_for_db, resume_id))
-        connection.commit()
-
-        if cursor.rowcount > 0:
-            console.print(f"✅ [bold green]Резюме {resume_id} успешно обновлено в БД[/bold green]")
-
-            # Проверяем что реально сохранилось
-            cursor.execute(
-                "SELECT mail, status FROM resumes WHERE id = %s",
-                (resume_id,)
-            )
-            result = cursor.fetchone()
-            if result:
-                saved_mail, saved_status = result
-                console.print(f"🔍 [bold cyan]Проверка: mail='{saved_mail}', status='{saved_status}'[/bold cyan]")
-
-            return True
-        else:
-            console.print(f"⚠️ [bold yellow]Резюме {resume_id} не найдено в БД для обновления[/bold yellow]")
-            return False
-
-    except Exception as e:
-        console.print(f"💥 [bold red]Ошибка сохранения в БД:[/bold red] {e}")
-        import traceback
-        console.print(traceback.format_exc())
-        connection.rollback()
-        return False
-    finally:
-        cursor.close()
-        connection.close()
+    message = {
+        "schema_version": RESULT_SCHEMA_VERSION,
+        "resume_id": resume_id,
+        "status": "failed" if error else "analyzed",
+        "analyzed_at": datetime.now(timezone.utc).isoformat(),
+    }
+    if vacancy_id:
+        message["vacancy_id"] = vacancy_id
+    if error:
+        message["error"] = error
+    else:
+        message["analysis"] = analysis
+    if email and email != 'user_has_no_mail':
+        message["email"] = email
+    return message
+
+
+def publish_result(channel, message):
+    """Публикация результата в очередь результатов"""
+    channel.basic_publish(
+        exchange=EXCHANGE_NAME,
+        routing_key=RESULTS_QUEUE,
+        body=json.dumps(message, ensure_ascii=False),
+        properties=pika.BasicProperties(
+            content_type="application/json",
+            delivery_mode=2,  # persistent
+            message_id=message["resume_id"],
+        ),
+    )
+    console.print(f"📤 [bold green]Результат для резюме {message['resume_id']} отправлен "
+                  f"({message['status']})[/bold green]")
 
 
 def log_message_received(message_data):
@@ -339,10 +288,13 @@ def log_ai_response_details(resume_id, response, processing_time):
 
         return None
 
-def process_resume_message(body):
-    """Обработка сообщения из RabbitMQ с подробным логированием и сохранением в БД"""
+def process_resume_message(channel, body):
+    """Обработка сообщения из RabbitMQ с подробным логированием и публикацией результата"""
+    resume_id = None
+    vacancy_id = None
     try:
         message_data = json.loads(body)
+        vacancy_id = message_data.get('vacancy_id')
 
         # 📥 Логируем получение сообщения
         resume_id, cv_text, vacancy_data, weight_soft, weight_hard, weight_case = log_message_received(message_data)
@@ -395,40 +347,43 @@ def process_resume_message(body):
             extracted_email = extract_email_from_ai_response(analysis_result)
             console.print(f"🎯 [bold blue]Финальный извлеченный email: '{extracted_email}'[/bold blue]")
 
-            # 💾 Сохраняем результаты в БД
-            db_success = save_analysis_to_database(resume_id, analysis_result, extracted_email)
-
-            if db_success:
-                # 🎉 Полный успех
-                console.print("\n" + "🎉 " * 30)
-                console.print(f"[bold green]✅ РЕЗЮМЕ {resume_id} ПОЛНОСТЬЮ ОБРАБОТАНО И СОХРАНЕНО![/bold green]",
-                              justify="center")
-                console.print(f"[bold green]📧 EMAIL: {extracted_email}[/bold green]", justify="center")
-                console.print(f"[bold green]💾 АНАЛИЗ СОХРАНЕН В БД[/bold green]", justify="center")
-                console.print("🎉 " * 30)
-            else:
-                # ⚠️ Анализ прошел, но БД не обновилась
-                console.print("\n" + "⚠️ " * 30)
-                console.print(
-                    f"[bold yellow]⚠️ РЕЗЮМЕ {resume_id} ПРОАНАЛИЗИРОВАНО, НО НЕ СОХРАНЕНО В БД[/bold yellow]",
-                    justify="center")
-                console.print("⚠️ " * 30)
+            if not isinstance(analysis_result, dict) or not analysis_result:
+                analysis_result = {"raw_response": str(analysis_result)[:500]}
+
+            publish_result(channel, build_result_message(resume_id, vacancy_id, analysis_result, extracted_email))
+
+            console.print("\n" + "🎉 " * 30)
+            console.print(f"[bold green]✅ РЕЗЮМЕ {resume_id} ПРОАНАЛИЗИРОВАНО![/bold green]", justify="center")
+            console.print(f"[bold green]📧 EMAIL: {extracted_email}[/bold green]", justify="center")
+            console.print("🎉 " * 30)
 
         else:
             console.print("\n" + "💥 " * 30)
             console.print(f"[bold red]❌ ОШИБКА ОБРАБОТКИ РЕЗЮМЕ {resume_id}![/bold red]", justify="center")
             console.print("💥 " * 30)
+            publish_result(channel, build_result_message(
+                resume_id, vacancy_id, error=f"cv review service returned HTTP {response.status_code}"))
 
     except json.JSONDecodeError as e:
         console.print(f"💥 [bold red]Ошибка парсинга JSON из RabbitMQ:[/bold red] {e}")
     except requests.exceptions.Timeout:
         console.print(f"⏰ [bold red]Таймаут запроса к ИИ сервису![/bold red]")
+        if resume_id:
+            publish_result(channel, build_result_message(resume_id, vacancy_id, error="cv review service timeout"))
     except requests.exceptions.ConnectionError:
         console.print(f"🔌 [bold red]Ошибка подключения к ИИ сервису![/bold red]")
+        if resume_id:
+            publish_result(channel, build_result_message(resume_id, vacancy_id, error="cv review service unavailable"))
     except Exception as e:
         console.print(f"💥 [bold red]Неожиданная ошибка в process_resume_message:[/bold red] {e}")
         import traceback
         console.print(traceback.format_exc())
+        # Без результата резюме навсегда осталось бы в статусе обработки
+        if resume_id:
+            try:
+                publish_result(channel, build_result_message(resume_id, vacancy_id, error=f"cv review failed: {e}"[:500]))
+            except Exception as publish_error:
+                console.print(f"❌ [bold red]Не удалось отправить результат с ошибкой:[/bold red] {publish_error}")
 
 
 def print_startup_banner():
@@ -437,41 +392,16 @@ def print_startup_banner():
 ╔══════════════════════════════════════════════════════════╗
 ║                 🤖 AI RESUME ANALYZER                    ║
 ║                    Consumer v3.0                         ║
-║          💾 WITH DATABASE INTEGRATION 📧                 ║
+║          📤 RESULTS VIA RABBITMQ 📧                      ║
 ╚══════════════════════════════════════════════════════════╝
     """
     console.print(banner, style="bold blue")
 
 
-def test_db_connection():
-    """Тестирование подключения к БД при запуске"""
-    console.print("🔌 [bold cyan]Тестирование подключения к PostgreSQL...[/bold cyan]")
-    connection = get_db_connection()
-    if connection:
-        try:
-            cursor = connection.cursor()
-            cursor.execute("SELECT COUNT(*) FROM resumes;")
-            count = cursor.fetchone()[0]
-            console.print(f"✅ [bold green]Подключение к БД успешно! Найдено {count} резюме[/bold green]")
-            cursor.close()
-            connection.close()
-            return True
-        except Exception as e:
-            console.print(f"❌ [bold red]Ошибка проверки БД:[/bold red] {e}")
-            connection.close()
-            return False
-    return False
-
-
 def main():
     """Основная функция consumer"""
     print_startup_banner()
-    console.print("🚀 [bold blue]Запуск AI Consumer для анализа резюме с сохранением в БД...[/bold blue]")
-
-    # Тестируем подключение к БД
-    if not test_db_connection():
-        console.print("💥 [bold red]Не удалось подключиться к БД. Завершение работы.[/bold red]")
-        return
+    console.print("🚀 [bold blue]Запуск AI Consumer для анализа резюме...[/bold blue]")
 
     processed_count = 0
 
@@ -487,6 +417,8 @@ def main():
             channel.queue_declare(queue='resume_analysis_queue', durable=True)
             channel.queue_bind(exchange='resume_exchange', queue='resume_analysis_queue',
                                routing_key='resume_analysis_queue')
+            channel.queue_declare(queue=RESULTS_QUEUE, durable=True)
+            channel.queue_bind(exchange=EXCHANGE_NAME, queue=RESULTS_QUEUE, routing_key=RESULTS_QUEUE)
 
             console.print("✅ [bold green]Подключение к RabbitMQ установлено[/bold green]")
             console.print(f"👀 [bold cyan]Ожидание сообщений... (Обработано: {processed_count})[/bold cyan]")
@@ -495,7 +427,7 @@ def main():
             while True:
                 method_frame, _, body = channel.basic_get(queue='resume_analysis_queue', auto_ack=False)
                 if method_frame:
-                    process_resume_message(body)
+                    process_resume_message(channel, body)
                     channel.basic_ack(delivery_tag=method_frame.delivery_tag)
                     processed_count += 1
 
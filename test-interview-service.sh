@@ -8,6 +8,8 @@ set -e
 # Настройки
 BASE_URL="http://localhost:8081"
 API_URL="$BASE_URL/api"
+# Токен HR-специалиста для /api/interviews
+HR_TOKEN="${HR_TOKEN:-}"
 
 # Цвета для вывода
 RED='\033[0;31m'
@@ -187,7 +189,8 @@ if [ "$RESUME_ID" != "null" ] && [ -n "$RESUME_ID" ]; then
 fi
 INTERVIEW_JSON="$INTERVIEW_JSON}"
 
-INTERVIEW_RESPONSE=$(curl -s -X POST "$API_URL/admin/interviews" \
+INTERVIEW_RESPONSE=$(curl -s -X POST "$API_URL/interviews" \
+  -H "Authorization: Bearer $HR_TOKEN" \
   -H "Content-Type: application/json" \
   -d "$INTERVIEW_JSON")
 
@@ -211,12 +214,12 @@ if [ "$INTERVIEW_URL" != "null" ] && [ -n "$INTERVIEW_URL" ]; then
 
     # Получение статуса интервью (GET с токеном в URL)
     echo -e "${YELLOW}Получение статуса интервью:${NC}"
-    INTERVIEW_STATUS=$(curl -s -X GET "$BASE_URL/interview/$INTERVIEW_TOKEN")
+    INTERVIEW_STATUS=$(curl -s -X GET "$API_URL/interview/$INTERVIEW_TOKEN")
     echo "$INTERVIEW_STATUS" | jq '.' 2>/dev/null || echo "$INTERVIEW_STATUS"
 
     # Запуск интервью
     echo -e "\n${YELLOW}Запуск интервью:${NC}"
-    START_RESPONSE=$(curl -s -w "%{http_code}" -X POST "$BASE_URL/interview/$INTERVIEW_TOKEN/start")
+    START_RESPONSE=$(curl -s -w "%{http_code}" -X POST "$API_URL/interview/$INTERVIEW_TOKEN/start")
     if [[ "$START_RESPONSE" == *"200"* ]]; then
         echo -e "${GREEN}✅ Интервью запущено${NC}"
     else
@@ -225,12 +228,12 @@ if [ "$INTERVIEW_URL" != "null" ] && [ -n "$INTERVIEW_URL" ]; then
 
     # Статус после запуска
     echo -e "\n${YELLOW}Статус после запуска:${NC}"
-    UPDATED_STATUS=$(curl -s -X GET "$BASE_URL/interview/$INTERVIEW_TOKEN")
+    UPDATED_STATUS=$(curl -s -X GET "$API_URL/interview/$INTERVIEW_TOKEN")
     echo "$UPDATED_STATUS" | jq '.' 2>/dev/null || echo "$UPDATED_STATUS"
 
     # Завершение интервью
     echo -e "\n${YELLOW}Завершение интервью:${NC}"
-    FINISH_RESPONSE=$(curl -s -w "%{http_code}" -X POST "$BASE_URL/interview/$INTERVIEW_TOKEN/finish")
+    FINISH_RESPONSE=$(curl -s -w "%{http_code}" -X POST "$API_URL/interview/$INTERVIEW_TOKEN/finish")
     if [[ "$FINISH_RESPONSE" == *"200"* ]]; then
         echo -e "${GREEN}✅ Интервью завершено${NC}"
     else